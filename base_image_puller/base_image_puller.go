@@ -27,10 +27,22 @@ type UnpackSpec struct {
 	Stream        io.ReadCloser `json:"-"`
 	TargetPath    string
 	BaseDirectory string
+	ExcludePaths  []string
 }
 
 type VolumeMeta struct {
 	Size int64
+	// CreatedAt and LastUsed are set to the volume modification time by the
+	// store migration for volumes created before they were recorded
+	CreatedAt time.Time
+	LastUsed  time.Time
+	// UsedBy lists the ids of the last images created on the volume, most
+	// recent first
+	UsedBy []string `json:",omitempty"`
+	// SourceImage and ManifestDigest identify the image the volume was first
+	// pulled for. They are empty for volumes not pulled from an image.
+	SourceImage    string `json:",omitempty"`
+	ManifestDigest string `json:",omitempty"`
 }
 
 type Fetcher interface {
@@ -45,6 +57,7 @@ type DependencyRegisterer interface {
 
 type UnpackOutput struct {
 	BytesWritten    int64
+	BytesExcluded   int64
 	OpaqueWhiteouts []string
 }
 
@@ -59,6 +72,7 @@ type VolumeDriver interface {
 	Volumes(logger lager.Logger) ([]string, error)
 	MoveVolume(logger lager.Logger, from, to string) error
 	WriteVolumeMeta(logger lager.Logger, id string, data VolumeMeta) error
+	TouchVolume(logger lager.Logger, id, imageID string) error
 	HandleOpaqueWhiteouts(logger lager.Logger, id string, opaqueWhiteouts []string) error
 }
 
@@ -103,7 +117,22 @@ func (p *BaseImagePuller) Pull(logger lager.Logger, baseImageInfo groot.BaseImag
 		return err
 	}
 
-	return p.buildLayer(logger, len(baseImageInfo.LayerInfos)-1, baseImageInfo.LayerInfos, spec)
+	if err := p.buildLayer(logger, len(baseImageInfo.LayerInfos)-1, baseImageInfo.LayerInfos, spec); err != nil {
+		return err
+	}
+
+	p.touchVolumes(logger, baseImageInfo.LayerInfos, spec.ImageID)
+	return nil
+}
+
+// touchVolumes records that the volumes are used by a new image. Failing to
+// do so only affects which volumes clean picks first.
+func (p *BaseImagePuller) touchVolumes(logger lager.Logger, layerInfos []groot.LayerInfo, imageID string) {
+	for _, layerInfo := range layerInfos {
+		if err := p.volumeDriver.TouchVolume(logger, layerInfo.ChainID, imageID); err != nil {
+			logger.Error("touching-volume-failed", err, lager.Data{"chainID": layerInfo.ChainID})
+		}
+	}
 }
 
 func (p *BaseImagePuller) quotaExceeded(logger lager.Logger, layerInfos []groot.LayerInfo, spec groot.BaseImageSpec) error {
@@ -207,6 +236,7 @@ func (p *BaseImagePuller) unpackLayer(logger lager.Logger, layerInfo, parentLaye
 		TargetPath:    volumePath,
 		Stream:        stream,
 		BaseDirectory: layerInfo.BaseDirectory,
+		ExcludePaths:  spec.ExcludePaths,
 	}
 
 	volSize, err := p.unpackLayerToTemporaryDirectory(logger, unpackSpec, layerInfo, parentLayerInfo)
@@ -214,7 +244,15 @@ func (p *BaseImagePuller) unpackLayer(logger lager.Logger, layerInfo, parentLaye
 		return err
 	}
 
-	return p.finalizeVolume(logger, tempVolumeName, volumePath, layerInfo.ChainID, volSize)
+	now := time.Now()
+	volumeMeta := VolumeMeta{
+		Size:           volSize,
+		CreatedAt:      now,
+		LastUsed:       now,
+		SourceImage:    spec.BaseImageReference,
+		ManifestDigest: spec.ManifestDigest,
+	}
+	return p.finalizeVolume(logger, tempVolumeName, volumePath, layerInfo.ChainID, volumeMeta)
 }
 
 func (p *BaseImagePuller) createTemporaryVolumeDirectory(logger lager.Logger, layerInfo groot.LayerInfo, spec groot.BaseImageSpec) (string, string, error) {
@@ -238,8 +276,8 @@ func (p *BaseImagePuller) createTemporaryVolumeDirectory(logger lager.Logger, la
 	return tempVolumeName, volumePath, nil
 }
 
-func (p *BaseImagePuller) finalizeVolume(logger lager.Logger, tempVolumeName, volumePath, chainID string, volSize int64) error {
-	if err := p.volumeDriver.WriteVolumeMeta(logger, chainID, VolumeMeta{Size: volSize}); err != nil {
+func (p *BaseImagePuller) finalizeVolume(logger lager.Logger, tempVolumeName, volumePath, chainID string, volumeMeta VolumeMeta) error {
+	if err := p.volumeDriver.WriteVolumeMeta(logger, chainID, volumeMeta); err != nil {
 		return errorspkg.Wrapf(err, "writing volume `%s` metadata", chainID)
 	}
 
@@ -286,6 +324,6 @@ func (p *BaseImagePuller) unpackLayerToTemporaryDirectory(logger lager.Logger, u
 		return 0, errorspkg.Wrap(err, "handling opaque whiteouts")
 	}
 
-	logger.Debug("layer-unpacked")
+	logger.Debug("layer-unpacked", lager.Data{"bytesWritten": unpackOutput.BytesWritten, "bytesExcluded": unpackOutput.BytesExcluded})
 	return unpackOutput.BytesWritten, nil
 }
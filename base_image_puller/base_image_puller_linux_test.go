@@ -152,6 +152,19 @@ var _ = Describe("Base Image Puller", func() {
 			Expect(unpackSpec.TargetPath).To(MatchRegexp(filepath.Join(tmpVolumesDir, "chain-333-incomplete-\\d*-\\d*")))
 		})
 
+		It("passes the exclude paths to the unpacker", func() {
+			err := baseImagePuller.Pull(logger, baseImageInfo, groot.BaseImageSpec{
+				ExcludePaths: []string{"/usr/share/doc"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeUnpacker.UnpackCallCount()).To(Equal(3))
+			for i := 0; i < 3; i++ {
+				_, unpackSpec := fakeUnpacker.UnpackArgsForCall(i)
+				Expect(unpackSpec.ExcludePaths).To(Equal([]string{"/usr/share/doc"}))
+			}
+		})
+
 		Context("when there is a base directory provided on a layer", func() {
 			BeforeEach(func() {
 				layerInfos[1].BaseDirectory = "/home/base_directory"
@@ -251,21 +264,47 @@ var _ = Describe("Base Image Puller", func() {
 				return base_image_puller.UnpackOutput{BytesWritten: int64(unpackCall * 100)}, nil
 			}
 
-			err := baseImagePuller.Pull(logger, baseImageInfo, groot.BaseImageSpec{})
+			err := baseImagePuller.Pull(logger, baseImageInfo, groot.BaseImageSpec{
+				BaseImageReference: "docker:///cfgarden/empty",
+				ManifestDigest:     "sha256:manifest",
+			})
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(fakeVolumeDriver.WriteVolumeMetaCallCount()).To(Equal(3))
-			_, id, metadata := fakeVolumeDriver.WriteVolumeMetaArgsForCall(0)
-			Expect(id).To(Equal("layer-111"))
-			Expect(metadata).To(Equal(base_image_puller.VolumeMeta{Size: 100}))
+			for i, expected := range []struct {
+				id   string
+				size int64
+			}{{"layer-111", 100}, {"chain-222", 200}, {"chain-333", 300}} {
+				_, id, metadata := fakeVolumeDriver.WriteVolumeMetaArgsForCall(i)
+				Expect(id).To(Equal(expected.id))
+				Expect(metadata.Size).To(Equal(expected.size))
+				Expect(metadata.SourceImage).To(Equal("docker:///cfgarden/empty"))
+				Expect(metadata.ManifestDigest).To(Equal("sha256:manifest"))
+				Expect(metadata.CreatedAt).To(BeTemporally("~", time.Now(), time.Minute))
+				Expect(metadata.LastUsed).To(Equal(metadata.CreatedAt))
+			}
+		})
+
+		It("touches each volume", func() {
+			err := baseImagePuller.Pull(logger, baseImageInfo, groot.BaseImageSpec{ImageID: "my-image"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeVolumeDriver.TouchVolumeCallCount()).To(Equal(3))
+			for i, layer := range layerInfos {
+				_, id, imageID := fakeVolumeDriver.TouchVolumeArgsForCall(i)
+				Expect(id).To(Equal(layer.ChainID))
+				Expect(imageID).To(Equal("my-image"))
+			}
+		})
 
-			_, id, metadata = fakeVolumeDriver.WriteVolumeMetaArgsForCall(1)
-			Expect(id).To(Equal("chain-222"))
-			Expect(metadata).To(Equal(base_image_puller.VolumeMeta{Size: 200}))
+		Context("when touching a volume fails", func() {
+			BeforeEach(func() {
+				fakeVolumeDriver.TouchVolumeReturns(errors.New("touch failed"))
+			})
 
-			_, id, metadata = fakeVolumeDriver.WriteVolumeMetaArgsForCall(2)
-			Expect(id).To(Equal("chain-333"))
-			Expect(metadata).To(Equal(base_image_puller.VolumeMeta{Size: 300}))
+			It("does not fail", func() {
+				Expect(baseImagePuller.Pull(logger, baseImageInfo, groot.BaseImageSpec{})).To(Succeed())
+			})
 		})
 
 		It("emits a metric with the unpack and download time for each layer", func() {
@@ -5,7 +5,7 @@ import (
 	"sync"
 
 	"code.cloudfoundry.org/grootfs/base_image_puller"
-	"code.cloudfoundry.org/lager/v3"
+	lager "code.cloudfoundry.org/lager/v3"
 )
 
 type FakeVolumeDriver struct {
@@ -62,6 +62,19 @@ type FakeVolumeDriver struct {
 	moveVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	TouchVolumeStub        func(lager.Logger, string, string) error
+	touchVolumeMutex       sync.RWMutex
+	touchVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}
+	touchVolumeReturns struct {
+		result1 error
+	}
+	touchVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	VolumePathStub        func(lager.Logger, string) (string, error)
 	volumePathMutex       sync.RWMutex
 	volumePathArgsForCall []struct {
@@ -365,6 +378,69 @@ func (fake *FakeVolumeDriver) MoveVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeVolumeDriver) TouchVolume(arg1 lager.Logger, arg2 string, arg3 string) error {
+	fake.touchVolumeMutex.Lock()
+	ret, specificReturn := fake.touchVolumeReturnsOnCall[len(fake.touchVolumeArgsForCall)]
+	fake.touchVolumeArgsForCall = append(fake.touchVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.TouchVolumeStub
+	fakeReturns := fake.touchVolumeReturns
+	fake.recordInvocation("TouchVolume", []interface{}{arg1, arg2, arg3})
+	fake.touchVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeVolumeDriver) TouchVolumeCallCount() int {
+	fake.touchVolumeMutex.RLock()
+	defer fake.touchVolumeMutex.RUnlock()
+	return len(fake.touchVolumeArgsForCall)
+}
+
+func (fake *FakeVolumeDriver) TouchVolumeCalls(stub func(lager.Logger, string, string) error) {
+	fake.touchVolumeMutex.Lock()
+	defer fake.touchVolumeMutex.Unlock()
+	fake.TouchVolumeStub = stub
+}
+
+func (fake *FakeVolumeDriver) TouchVolumeArgsForCall(i int) (lager.Logger, string, string) {
+	fake.touchVolumeMutex.RLock()
+	defer fake.touchVolumeMutex.RUnlock()
+	argsForCall := fake.touchVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeVolumeDriver) TouchVolumeReturns(result1 error) {
+	fake.touchVolumeMutex.Lock()
+	defer fake.touchVolumeMutex.Unlock()
+	fake.TouchVolumeStub = nil
+	fake.touchVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVolumeDriver) TouchVolumeReturnsOnCall(i int, result1 error) {
+	fake.touchVolumeMutex.Lock()
+	defer fake.touchVolumeMutex.Unlock()
+	fake.TouchVolumeStub = nil
+	if fake.touchVolumeReturnsOnCall == nil {
+		fake.touchVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.touchVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeVolumeDriver) VolumePath(arg1 lager.Logger, arg2 string) (string, error) {
 	fake.volumePathMutex.Lock()
 	ret, specificReturn := fake.volumePathReturnsOnCall[len(fake.volumePathArgsForCall)]
@@ -568,6 +644,8 @@ func (fake *FakeVolumeDriver) Invocations() map[string][][]interface{} {
 	defer fake.handleOpaqueWhiteoutsMutex.RUnlock()
 	fake.moveVolumeMutex.RLock()
 	defer fake.moveVolumeMutex.RUnlock()
+	fake.touchVolumeMutex.RLock()
+	defer fake.touchVolumeMutex.RUnlock()
 	fake.volumePathMutex.RLock()
 	defer fake.volumePathMutex.RUnlock()
 	fake.volumesMutex.RLock()
@@ -0,0 +1,201 @@
+package unpacker
+
+import (
+	"archive/tar"
+	"bytes"
+	"hash/fnv"
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// files up to this size are buffered in memory and written in the
+	// background, bigger files are streamed straight from the tar reader
+	maxBufferedFileSize = 256 * 1024
+	workerQueueLength   = 16
+)
+
+type fileWriteJob struct {
+	path     string
+	header   *tar.Header
+	contents io.Reader
+	done     chan struct{}
+}
+
+// fileWriterPool materializes regular files on a fixed set of workers. Jobs
+// for the same path always land on the same worker, so that files overwriting
+// each other are still applied in archive order. Everything else is created
+// by the caller, which has to wait for the jobs writing the paths it goes
+// through.
+type fileWriterPool struct {
+	unpacker *TarUnpacker
+	queues   []chan fileWriteJob
+	pending  sync.WaitGroup
+	workers  sync.WaitGroup
+
+	pendingPathsLock sync.Mutex
+	pendingPaths     map[string]int
+
+	bytesWritten int64
+	errOnce      sync.Once
+	err          error
+	failed       atomic.Bool
+}
+
+func newFileWriterPool(unpacker *TarUnpacker, workers int) *fileWriterPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	pool := &fileWriterPool{
+		unpacker:     unpacker,
+		queues:       make([]chan fileWriteJob, workers),
+		pendingPaths: map[string]int{},
+	}
+
+	for i := range pool.queues {
+		pool.queues[i] = make(chan fileWriteJob, workerQueueLength)
+		pool.workers.Add(1)
+		go pool.work(pool.queues[i])
+	}
+
+	return pool
+}
+
+// WriteFile schedules the creation of a regular file. Small files are read
+// into memory so that the caller can move on to the next tar entry straight
+// away, while bigger ones block until the worker has consumed the reader.
+func (p *fileWriterPool) WriteFile(path string, header *tar.Header, reader io.Reader) error {
+	if header.Size > maxBufferedFileSize {
+		done := make(chan struct{})
+		p.schedule(fileWriteJob{path: path, header: header, contents: reader, done: done})
+		<-done
+		return p.Err()
+	}
+
+	contents := bytes.NewBuffer(make([]byte, 0, header.Size))
+	if _, err := io.Copy(contents, reader); err != nil {
+		return err
+	}
+
+	p.schedule(fileWriteJob{path: path, header: header, contents: contents})
+	return nil
+}
+
+// WaitForPath waits for the scheduled jobs when one of them writes the given
+// path or one of its parents, so that the caller can replace or go through
+// it without racing with the workers.
+func (p *fileWriterPool) WaitForPath(path string) error {
+	if p.isPending(path) {
+		return p.Wait()
+	}
+
+	return p.Err()
+}
+
+// Wait blocks until all the scheduled jobs have been applied and returns the
+// first error that any of them hit.
+func (p *fileWriterPool) Wait() error {
+	p.pending.Wait()
+	return p.Err()
+}
+
+// Close waits for the scheduled jobs and stops the workers.
+func (p *fileWriterPool) Close() error {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.workers.Wait()
+
+	return p.Err()
+}
+
+func (p *fileWriterPool) Err() error {
+	if !p.failed.Load() {
+		return nil
+	}
+
+	return p.err
+}
+
+func (p *fileWriterPool) BytesWritten() int64 {
+	return atomic.LoadInt64(&p.bytesWritten)
+}
+
+func (p *fileWriterPool) schedule(job fileWriteJob) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(job.path))
+
+	p.pendingPathsLock.Lock()
+	p.pendingPaths[job.path]++
+	p.pendingPathsLock.Unlock()
+
+	p.pending.Add(1)
+	p.queues[hash.Sum32()%uint32(len(p.queues))] <- job
+}
+
+func (p *fileWriterPool) isPending(path string) bool {
+	p.pendingPathsLock.Lock()
+	defer p.pendingPathsLock.Unlock()
+
+	for {
+		if p.pendingPaths[path] > 0 {
+			return true
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+func (p *fileWriterPool) done(job fileWriteJob) {
+	p.pendingPathsLock.Lock()
+	p.pendingPaths[job.path]--
+	if p.pendingPaths[job.path] == 0 {
+		delete(p.pendingPaths, job.path)
+	}
+	p.pendingPathsLock.Unlock()
+
+	if job.done != nil {
+		close(job.done)
+	}
+	p.pending.Done()
+}
+
+func (p *fileWriterPool) work(queue chan fileWriteJob) {
+	defer p.workers.Done()
+
+	for job := range queue {
+		p.run(job)
+		p.done(job)
+	}
+}
+
+func (p *fileWriterPool) run(job fileWriteJob) {
+	if p.failed.Load() {
+		return
+	}
+
+	size, err := p.unpacker.createRegularFile(job.path, job.header, job.contents)
+	if err != nil {
+		p.fail(err)
+		return
+	}
+	atomic.AddInt64(&p.bytesWritten, size)
+}
+
+func (p *fileWriterPool) fail(err error) {
+	if err == nil {
+		return
+	}
+
+	p.errOnce.Do(func() {
+		p.err = err
+		p.failed.Store(true)
+	})
+}
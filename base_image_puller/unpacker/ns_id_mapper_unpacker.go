@@ -23,7 +23,7 @@ type NSIdMapperUnpacker struct {
 
 func init() {
 	sandbox.Register("unpack", func(logger lager.Logger, extraFiles []*os.File, args ...string) error {
-		if len(os.Args) != 6 {
+		if len(os.Args) != 7 {
 			return errorspkg.New("wrong number of arguments")
 		}
 
@@ -35,6 +35,7 @@ func init() {
 		if err != nil {
 			return errorspkg.Wrap(err, "parsing 'shouldMapUidGid' to bool")
 		}
+		excludePathsJSON := os.Args[6]
 
 		if len(extraFiles) != 1 {
 			return errorspkg.New("wrong number of extra files")
@@ -48,6 +49,11 @@ func init() {
 			return errorspkg.Wrap(err, "unmarshaling gid mappings")
 		}
 
+		var excludePaths []string
+		if err := json.Unmarshal([]byte(excludePathsJSON), &excludePaths); err != nil {
+			return errorspkg.Wrap(err, "unmarshaling exclude paths")
+		}
+
 		storeDir := extraFiles[0]
 		whiteoutHandler := NewOverlayWhiteoutHandler(storeDir)
 
@@ -62,6 +68,7 @@ func init() {
 			Stream:        os.Stdin,
 			TargetPath:    targetDir,
 			BaseDirectory: baseDirectory,
+			ExcludePaths:  excludePaths,
 		})
 		if err != nil {
 			return errorspkg.Wrap(err, "unpacking-failed")
@@ -106,12 +113,17 @@ func (u *NSIdMapperUnpacker) Unpack(logger lager.Logger, spec base_image_puller.
 		return base_image_puller.UnpackOutput{}, errorspkg.Wrap(err, "marshaling gid mappings")
 	}
 
+	excludePathsJSON, err := json.Marshal(spec.ExcludePaths)
+	if err != nil {
+		return base_image_puller.UnpackOutput{}, errorspkg.Wrap(err, "marshaling exclude paths")
+	}
+
 	shouldMapUidGid := strconv.FormatBool(!u.shouldCloneUserNsOnUnpack)
 	out, err := u.reexecer.Reexec("unpack", groot.ReexecSpec{
 		Stdin:       spec.Stream,
 		ChrootDir:   spec.TargetPath,
 		CloneUserns: u.shouldCloneUserNsOnUnpack,
-		Args:        []string{".", spec.BaseDirectory, string(uidMappingsJSON), string(gidMappingsJSON), shouldMapUidGid},
+		Args:        []string{".", spec.BaseDirectory, string(uidMappingsJSON), string(gidMappingsJSON), shouldMapUidGid, string(excludePathsJSON)},
 		ExtraFiles:  []string{u.storePath},
 	})
 	if err != nil {
@@ -62,10 +62,22 @@ var _ = Describe("NSIdMapperUnpacker", func() {
 		_, reexecSpec := reexecer.ReexecArgsForCall(0)
 
 		Expect(reexecSpec.Args).To(Equal(
-			[]string{".", "/base-folder/", "null", "null", strconv.FormatBool(!shouldCloneUserNsOnUnpack)},
+			[]string{".", "/base-folder/", "null", "null", strconv.FormatBool(!shouldCloneUserNsOnUnpack), "null"},
 		))
 	})
 
+	It("passes the exclude paths to the unpack command", func() {
+		_, err := unpacker.Unpack(logger, base_image_puller.UnpackSpec{
+			TargetPath:   targetPath,
+			ExcludePaths: []string{"/usr/share/doc", "/var/cache/*"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reexecer.ReexecCallCount()).To(Equal(1))
+		_, reexecSpec := reexecer.ReexecArgsForCall(0)
+		Expect(reexecSpec.Args[5]).To(Equal(`["/usr/share/doc","/var/cache/*"]`))
+	})
+
 	It("returns the unpack result", func() {
 		unpackOutput, err := unpacker.Unpack(logger, base_image_puller.UnpackSpec{
 			TargetPath: targetPath,
@@ -0,0 +1,36 @@
+package unpacker
+
+import (
+	"path/filepath"
+)
+
+// pathExcluder matches image paths against glob patterns. A path is excluded
+// when either the path itself or any of its parent directories matches.
+type pathExcluder struct {
+	patterns []string
+}
+
+func newPathExcluder(patterns []string) *pathExcluder {
+	cleanPatterns := []string{}
+	for _, pattern := range patterns {
+		cleanPatterns = append(cleanPatterns, filepath.Join("/", pattern))
+	}
+
+	return &pathExcluder{patterns: cleanPatterns}
+}
+
+func (e *pathExcluder) Excluded(entryPath string) bool {
+	if len(e.patterns) == 0 {
+		return false
+	}
+
+	for p := filepath.Join("/", entryPath); p != "/"; p = filepath.Dir(p) {
+		for _, pattern := range e.patterns {
+			if matched, _ := filepath.Match(pattern, p); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
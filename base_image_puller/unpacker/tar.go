@@ -5,7 +5,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/pkg/system"
 	"github.com/pkg/errors"
@@ -18,6 +20,7 @@ const (
 	defaultDirectoryFileMode = 0755
 	defaultDirectoryUid      = 0
 	defaultDirectoryGid      = 0
+	sparseBlockSize          = 4096
 )
 
 //go:generate counterfeiter . WhiteoutHandler
@@ -28,17 +31,31 @@ type WhiteoutHandler interface {
 type TarUnpacker struct {
 	whiteoutHandler WhiteoutHandler
 	idTranslator    IDTranslator
+	workers         int
+}
+
+type directoryMetadata struct {
+	path    string
+	mode    os.FileMode
+	modTime time.Time
 }
 
 func NewTarUnpacker(whiteoutHandler WhiteoutHandler, idTranslator IDTranslator) *TarUnpacker {
 	return &TarUnpacker{
 		whiteoutHandler: whiteoutHandler,
 		idTranslator:    idTranslator,
+		workers:         runtime.NumCPU(),
 	}
 }
 
+// WithWorkers sets how many files are materialized concurrently.
+func (u *TarUnpacker) WithWorkers(workers int) *TarUnpacker {
+	u.workers = workers
+	return u
+}
+
 func (u *TarUnpacker) Unpack(logger lager.Logger, spec base_image_puller.UnpackSpec) (base_image_puller.UnpackOutput, error) {
-	logger = logger.Session("unpacking-with-tar", lager.Data{"spec": spec})
+	logger = logger.Session("unpacking-with-tar", lager.Data{"spec": spec, "workers": u.workers})
 	logger.Info("starting")
 	defer logger.Info("ending")
 
@@ -46,82 +63,136 @@ func (u *TarUnpacker) Unpack(logger lager.Logger, spec base_image_puller.UnpackS
 		return base_image_puller.UnpackOutput{}, err
 	}
 
+	writers := newFileWriterPool(u, u.workers)
+	directories, output, err := u.unpackEntries(writers, spec)
+	if closeErr := writers.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return base_image_puller.UnpackOutput{}, err
+	}
+
+	// directory metadata is applied once all the files have been written, as
+	// creating entries inside a directory would otherwise change its modtime
+	for i := len(directories) - 1; i >= 0; i-- {
+		if err := u.applyDirectoryMetadata(directories[i]); err != nil {
+			return base_image_puller.UnpackOutput{}, err
+		}
+	}
+
+	output.BytesWritten = writers.BytesWritten()
+	return output, nil
+}
+
+func (u *TarUnpacker) unpackEntries(writers *fileWriterPool, spec base_image_puller.UnpackSpec) ([]directoryMetadata, base_image_puller.UnpackOutput, error) {
 	tarReader := tar.NewReader(spec.Stream)
-	opaqueWhiteouts := []string{}
-	var totalBytesUnpacked int64
+	excluder := newPathExcluder(spec.ExcludePaths)
+	output := base_image_puller.UnpackOutput{OpaqueWhiteouts: []string{}}
+	directories := []directoryMetadata{}
+
 	for {
+		if err := writers.Err(); err != nil {
+			return nil, base_image_puller.UnpackOutput{}, err
+		}
+
 		tarHeader, err := tarReader.Next()
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return base_image_puller.UnpackOutput{}, err
+			return nil, base_image_puller.UnpackOutput{}, err
 		}
 
 		entryPath := filepath.Join(spec.BaseDirectory, tarHeader.Name)
 		entryTargetPath := filepath.Join(spec.TargetPath, entryPath)
 
 		if strings.Contains(tarHeader.Name, ".wh..wh..opq") {
-			opaqueWhiteouts = append(opaqueWhiteouts, entryPath)
+			output.OpaqueWhiteouts = append(output.OpaqueWhiteouts, entryPath)
 			continue
 		}
 
 		if strings.Contains(tarHeader.Name, ".wh.") {
+			// whiteouts may remove whole trees that still have pending writes
+			if err := writers.Wait(); err != nil {
+				return nil, base_image_puller.UnpackOutput{}, err
+			}
+
 			if err := u.whiteoutHandler.RemoveWhiteout(entryTargetPath); err != nil {
-				return base_image_puller.UnpackOutput{}, err
+				return nil, base_image_puller.UnpackOutput{}, err
 			}
 			continue
 		}
 
-		entrySize, err := u.handleEntry(entryTargetPath, tarReader, tarHeader, spec)
-		if err != nil {
-			return base_image_puller.UnpackOutput{}, err
+		if excluder.Excluded(entryPath) {
+			if tarHeader.Typeflag == tar.TypeReg || tarHeader.Typeflag == tar.TypeGNUSparse {
+				output.BytesExcluded += tarHeader.Size
+			}
+			continue
 		}
 
-		totalBytesUnpacked += entrySize
-	}
+		// the entry may replace or go through a file that is still being written
+		if err := writers.WaitForPath(entryTargetPath); err != nil {
+			return nil, base_image_puller.UnpackOutput{}, err
+		}
 
-	return base_image_puller.UnpackOutput{
-		BytesWritten:    totalBytesUnpacked,
-		OpaqueWhiteouts: opaqueWhiteouts,
-	}, nil
-}
+		if err := u.ensureParentDir(entryTargetPath); err != nil {
+			return nil, base_image_puller.UnpackOutput{}, err
+		}
 
-func (u *TarUnpacker) handleEntry(entryPath string, tarReader *tar.Reader, tarHeader *tar.Header, spec base_image_puller.UnpackSpec) (entrySize int64, err error) {
-	if err := u.ensureParentDir(entryPath); err != nil {
-		return 0, err
-	}
+		switch tarHeader.Typeflag {
+		case tar.TypeBlock, tar.TypeChar:
+			// ignore devices
+			continue
 
-	switch tarHeader.Typeflag {
-	case tar.TypeBlock, tar.TypeChar:
-		// ignore devices
-		return 0, nil
+		case tar.TypeLink:
+			if excluder.Excluded(filepath.Join(spec.BaseDirectory, tarHeader.Linkname)) {
+				// there is nothing to link to
+				continue
+			}
 
-	case tar.TypeLink:
-		if err = u.createLink(entryPath, tarHeader, spec); err != nil {
-			return 0, err
-		}
+			// the link target has to be fully written before linking to it
+			if err := writers.Wait(); err != nil {
+				return nil, base_image_puller.UnpackOutput{}, err
+			}
 
-	case tar.TypeSymlink:
-		if err = u.createSymlink(entryPath, tarHeader, spec); err != nil {
-			return 0, err
-		}
+			if err := u.createLink(entryTargetPath, tarHeader, spec); err != nil {
+				return nil, base_image_puller.UnpackOutput{}, err
+			}
 
-	case tar.TypeDir:
-		if err = u.createDirectory(entryPath, tarHeader, spec); err != nil {
-			return 0, err
-		}
+		case tar.TypeSymlink:
+			if err := u.createSymlink(entryTargetPath, tarHeader); err != nil {
+				return nil, base_image_puller.UnpackOutput{}, err
+			}
 
-	case tar.TypeReg:
-		if entrySize, err = u.createRegularFile(entryPath, tarHeader, tarReader, spec); err != nil {
-			return 0, err
+		case tar.TypeDir:
+			if err := u.createDirectory(entryTargetPath, tarHeader); err != nil {
+				return nil, base_image_puller.UnpackOutput{}, err
+			}
+
+			directories = append(directories, directoryMetadata{
+				path:    entryTargetPath,
+				mode:    tarHeader.FileInfo().Mode(),
+				modTime: tarHeader.ModTime,
+			})
+
+		case tar.TypeReg, tar.TypeGNUSparse:
+			if err := writers.WriteFile(entryTargetPath, tarHeader, tarReader); err != nil {
+				return nil, base_image_puller.UnpackOutput{}, errors.Wrapf(err, "reading file `%s`", entryTargetPath)
+			}
 		}
 	}
 
-	return entrySize, nil
+	return directories, output, nil
 }
 
-func (u *TarUnpacker) createDirectory(path string, tarHeader *tar.Header, spec base_image_puller.UnpackSpec) error {
-	if _, err := os.Stat(path); err != nil {
+func (u *TarUnpacker) createDirectory(path string, tarHeader *tar.Header) error {
+	// the directory replaces whatever else had the same path
+	if fi, err := os.Lstat(path); err == nil && !fi.IsDir() {
+		if err := os.Remove(path); err != nil {
+			return errors.Wrapf(err, "removing file `%s`", path)
+		}
+	}
+
+	if _, err := os.Lstat(path); err != nil {
 		if err = os.Mkdir(path, tarHeader.FileInfo().Mode()); err != nil {
 			newErr := errors.Wrapf(err, "creating directory `%s`", path)
 
@@ -140,19 +211,28 @@ func (u *TarUnpacker) createDirectory(path string, tarHeader *tar.Header, spec b
 		return errors.Wrapf(err, "chowning directory %d:%d `%s`", uid, gid, path)
 	}
 
+	return nil
+}
+
+func (u *TarUnpacker) applyDirectoryMetadata(directory directoryMetadata) error {
+	// a later whiteout in the same layer may have replaced the directory
+	if fi, err := os.Lstat(directory.path); err != nil || !fi.IsDir() {
+		return nil
+	}
+
 	// we need to explicitly apply perms because mkdir is subject to umask
-	if err := os.Chmod(path, tarHeader.FileInfo().Mode()); err != nil {
-		return errors.Wrapf(err, "chmoding directory `%s`", path)
+	if err := os.Chmod(directory.path, directory.mode); err != nil {
+		return errors.Wrapf(err, "chmoding directory `%s`", directory.path)
 	}
 
-	if err := changeModTime(path, tarHeader.ModTime); err != nil {
-		return errors.Wrapf(err, "setting the modtime for directory %s", path)
+	if err := changeModTime(directory.path, directory.modTime); err != nil {
+		return errors.Wrapf(err, "setting the modtime for directory %s", directory.path)
 	}
 
 	return nil
 }
 
-func (u *TarUnpacker) createSymlink(path string, tarHeader *tar.Header, spec base_image_puller.UnpackSpec) error {
+func (u *TarUnpacker) createSymlink(path string, tarHeader *tar.Header) error {
 	if _, err := os.Lstat(path); err == nil {
 		if err := os.Remove(path); err != nil {
 			return errors.Wrapf(err, "removing file `%s`", path)
@@ -204,7 +284,7 @@ func (u *TarUnpacker) ensureParentDir(childPath string) error {
 	return nil
 }
 
-func (u *TarUnpacker) createRegularFile(path string, tarHeader *tar.Header, tarReader *tar.Reader, spec base_image_puller.UnpackSpec) (int64, error) {
+func (u *TarUnpacker) createRegularFile(path string, tarHeader *tar.Header, contents io.Reader) (int64, error) {
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, tarHeader.FileInfo().Mode())
 	if err != nil {
 		newErr := errors.Wrapf(err, "creating file `%s`", path)
@@ -217,7 +297,7 @@ func (u *TarUnpacker) createRegularFile(path string, tarHeader *tar.Header, tarR
 		return 0, newErr
 	}
 
-	fileSize, err := io.Copy(file, tarReader)
+	fileSize, err := copySparse(file, contents)
 	if err != nil {
 		_ = file.Close()
 		return 0, errors.Wrapf(err, "writing to file `%s`", path)
@@ -255,6 +335,61 @@ func (u *TarUnpacker) createRegularFile(path string, tarHeader *tar.Header, tarR
 	return fileSize, nil
 }
 
+// copySparse copies the reader contents to the file, seeking over blocks that
+// only contain zeros so that they end up as holes instead of being allocated.
+// The tar reader expands GNU and PAX sparse entries into zeros, so this also
+// restores the holes described by sparse headers. It returns the number of
+// bytes that were actually written, i.e. excluding holes.
+func copySparse(file *os.File, reader io.Reader) (int64, error) {
+	buffer := make([]byte, sparseBlockSize)
+	var fileSize, bytesWritten int64
+
+	for {
+		n, readErr := io.ReadFull(reader, buffer)
+		if n > 0 {
+			block := buffer[:n]
+			if isZeroBlock(block) {
+				if _, err := file.Seek(int64(n), io.SeekCurrent); err != nil {
+					return 0, errors.Wrap(err, "seeking over hole")
+				}
+			} else {
+				written, err := file.Write(block)
+				bytesWritten += int64(written)
+				if err != nil {
+					return 0, err
+				}
+			}
+			fileSize += int64(n)
+		}
+
+		// a short read is the last block of the file
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return 0, readErr
+		}
+	}
+
+	// seeking past the end does not change the file size, so a trailing hole
+	// has to be accounted for explicitly
+	if err := file.Truncate(fileSize); err != nil {
+		return 0, errors.Wrap(err, "truncating to final size")
+	}
+
+	return bytesWritten, nil
+}
+
+func isZeroBlock(block []byte) bool {
+	for _, b := range block {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 func safeMkdir(path string, perm os.FileMode) error {
 	if _, err := os.Stat(path); err != nil {
 		if err := os.Mkdir(path, perm); err != nil {
@@ -0,0 +1,104 @@
+package unpacker_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"code.cloudfoundry.org/grootfs/base_image_puller"
+	"code.cloudfoundry.org/grootfs/base_image_puller/unpacker"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+func BenchmarkUnpackSmallFilesSequential(b *testing.B) {
+	benchmarkUnpack(b, 1, 10000, 1024)
+}
+
+func BenchmarkUnpackSmallFilesParallel(b *testing.B) {
+	benchmarkUnpack(b, 8, 10000, 1024)
+}
+
+func BenchmarkUnpackLargeFilesSequential(b *testing.B) {
+	benchmarkUnpack(b, 1, 20, 4*1024*1024)
+}
+
+func BenchmarkUnpackLargeFilesParallel(b *testing.B) {
+	benchmarkUnpack(b, 8, 20, 4*1024*1024)
+}
+
+func benchmarkUnpack(b *testing.B, workers, files, fileSize int) {
+	layer := buildBenchmarkLayer(b, files, fileSize)
+	logger := lager.NewLogger("benchmark")
+	b.SetBytes(int64(files * fileSize))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		targetPath, err := os.MkdirTemp("", "unpack-benchmark-")
+		if err != nil {
+			b.Fatal(err)
+		}
+		tarUnpacker := unpacker.NewTarUnpacker(
+			unpacker.NewOverlayWhiteoutHandler(nil),
+			unpacker.NewNoopIDTranslator(),
+		).WithWorkers(workers)
+		b.StartTimer()
+
+		if _, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+			Stream:     io.NopCloser(bytes.NewReader(layer)),
+			TargetPath: targetPath,
+		}); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		if err := os.RemoveAll(targetPath); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+	}
+}
+
+func buildBenchmarkLayer(b *testing.B, files, fileSize int) []byte {
+	buffer := new(bytes.Buffer)
+	tarWriter := tar.NewWriter(buffer)
+	contents := bytes.Repeat([]byte("groot"), fileSize/5+1)[:fileSize]
+
+	for i := 0; i < files; i++ {
+		if i%100 == 0 {
+			if err := tarWriter.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     fmt.Sprintf("dir-%d/", i/100),
+				Mode:     0755,
+				Uid:      os.Getuid(),
+				Gid:      os.Getgid(),
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     fmt.Sprintf("dir-%d/file-%d", i/100, i),
+			Mode:     0644,
+			Size:     int64(fileSize),
+			Uid:      os.Getuid(),
+			Gid:      os.Getgid(),
+		}); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := tarWriter.Write(contents); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
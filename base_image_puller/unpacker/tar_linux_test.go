@@ -161,6 +161,30 @@ var _ = Describe("Tar unpacker - Linux tests", func() {
 		})
 	})
 
+	Describe("GNU sparse files", func() {
+		BeforeEach(func() {
+			sparseFilePath := path.Join(baseImagePath, "sparse_file")
+			Expect(exec.Command("truncate", "-s", "8M", sparseFilePath).Run()).To(Succeed())
+			Expect(exec.Command("dd", "if=/dev/urandom", "of="+sparseFilePath, "bs=4096", "count=1", "seek=1024", "conv=notrunc").Run()).To(Succeed())
+
+			tarCommand = exec.Command("tar", "-C", baseImagePath, "--sparse", "--format=gnu", "-cf", tarFilePath, ".")
+		})
+
+		It("restores the holes", func() {
+			output, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+				Stream:     stream,
+				TargetPath: targetPath,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.BytesWritten).To(Equal(int64(4096)))
+
+			fi, err := os.Stat(filepath.Join(targetPath, "sparse_file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fi.Size()).To(Equal(int64(8 * 1024 * 1024)))
+			Expect(fi.Sys().(*syscall.Stat_t).Blocks * 512).To(BeNumerically("<", 8*1024*1024))
+		})
+	})
+
 	Describe("security xattrs", func() {
 		var capabilities = "0100000200200000000000000000000000000000" // output from `getfattr -e hex -d -m '' /bin/ping`
 		BeforeEach(func() {
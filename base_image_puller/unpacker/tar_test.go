@@ -1,6 +1,7 @@
 package unpacker_test
 
 import (
+	"archive/tar"
 	"fmt"
 	"io"
 	"os"
@@ -129,14 +130,62 @@ var _ = Describe("Tar unpacker", func() {
 				Eventually(sess).Should(gexec.Exit(0))
 			})
 
-			It("returns the total size that was unpacked", func() {
+			It("returns the total size that was unpacked, excluding holes", func() {
 				totalUnpacked, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
 					Stream:     stream,
 					TargetPath: targetPath,
 				})
 
 				Expect(err).NotTo(HaveOccurred())
-				Expect(totalUnpacked).To(Equal(base_image_puller.UnpackOutput{BytesWritten: 1024*1024 + 1024*1024*3 + 1024 + 11, OpaqueWhiteouts: []string{}}))
+				Expect(totalUnpacked).To(Equal(base_image_puller.UnpackOutput{BytesWritten: 11, OpaqueWhiteouts: []string{}}))
+			})
+
+			It("preserves the size of files made of zeros", func() {
+				_, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+					Stream:     stream,
+					TargetPath: targetPath,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(targetPath, "1mb")).To(BeARegularFile())
+				fi, err := os.Stat(filepath.Join(targetPath, "3mb"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.Size()).To(Equal(int64(1024 * 1024 * 3)))
+
+				stat := fi.Sys().(*syscall.Stat_t)
+				Expect(stat.Blocks * 512).To(BeNumerically("<", 1024*1024*3))
+			})
+		})
+
+		Describe("files with runs of zeros", func() {
+			var contents []byte
+
+			BeforeEach(func() {
+				contents = make([]byte, 4096*4)
+				copy(contents, []byte("head"))
+				copy(contents[4096*2:], []byte("middle"))
+				Expect(os.WriteFile(path.Join(baseImagePath, "sparse_file"), contents, 0o600)).To(Succeed())
+			})
+
+			It("preserves the contents", func() {
+				_, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+					Stream:     stream,
+					TargetPath: targetPath,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				unpackedContents, err := os.ReadFile(path.Join(targetPath, "sparse_file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unpackedContents).To(Equal(contents))
+			})
+
+			It("only counts the blocks holding data", func() {
+				output, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+					Stream:     stream,
+					TargetPath: targetPath,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output.BytesWritten).To(Equal(int64(4096*2 + 11)))
 			})
 		})
 
@@ -462,6 +511,58 @@ var _ = Describe("Tar unpacker", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fi.ModTime().Unix()).To(Equal(dirModTime.Unix()))
 		})
+
+		Context("when the directory has contents", func() {
+			BeforeEach(func() {
+				dirPath := path.Join(baseImagePath, "old-dir")
+				Expect(os.WriteFile(path.Join(dirPath, "a_file"), []byte("hello-world"), 0o600)).To(Succeed())
+				Expect(os.Chtimes(dirPath, time.Now(), dirModTime)).To(Succeed())
+			})
+
+			It("preserves the modtime for the directory", func() {
+				_, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+					Stream:     stream,
+					TargetPath: targetPath,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				fi, err := os.Stat(path.Join(targetPath, "old-dir"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.ModTime().Unix()).To(Equal(dirModTime.Unix()))
+			})
+		})
+	})
+
+	Describe("many files", func() {
+		BeforeEach(func() {
+			for i := 0; i < 50; i++ {
+				dirPath := path.Join(baseImagePath, fmt.Sprintf("dir-%d", i))
+				Expect(os.Mkdir(dirPath, 0o755)).To(Succeed())
+				for j := 0; j < 20; j++ {
+					contents := []byte(fmt.Sprintf("file-%d-%d", i, j))
+					Expect(os.WriteFile(path.Join(dirPath, fmt.Sprintf("file-%d", j)), contents, 0o644)).To(Succeed())
+				}
+			}
+		})
+
+		It("unpacks all of them", func() {
+			output, err := tarUnpacker.WithWorkers(8).Unpack(logger, base_image_puller.UnpackSpec{
+				Stream:     stream,
+				TargetPath: targetPath,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var expectedBytes int64
+			for i := 0; i < 50; i++ {
+				for j := 0; j < 20; j++ {
+					contents, err := os.ReadFile(path.Join(targetPath, fmt.Sprintf("dir-%d", i), fmt.Sprintf("file-%d", j)))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal(fmt.Sprintf("file-%d-%d", i, j)))
+					expectedBytes += int64(len(contents))
+				}
+			}
+			Expect(output.BytesWritten).To(Equal(expectedBytes))
+		})
 	})
 
 	Describe("permissions", func() {
@@ -616,6 +717,43 @@ var _ = Describe("Tar unpacker", func() {
 				})
 			})
 
+			Context("when a later entry replaces the symlink with a directory", func() {
+				JustBeforeEach(func() {
+					buffer := gbytes.NewBuffer()
+					tarWriter := tar.NewWriter(buffer)
+					Expect(tarWriter.WriteHeader(&tar.Header{Name: "target-dir/", Typeflag: tar.TypeDir, Mode: 0o755})).To(Succeed())
+					for i := 0; i < 20; i++ {
+						contents := []byte(fmt.Sprintf("file-%d", i))
+						Expect(tarWriter.WriteHeader(&tar.Header{Name: fmt.Sprintf("target-dir/file-%d", i), Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(contents))})).To(Succeed())
+						_, err := tarWriter.Write(contents)
+						Expect(err).NotTo(HaveOccurred())
+					}
+					Expect(tarWriter.WriteHeader(&tar.Header{Name: "replaced", Typeflag: tar.TypeSymlink, Linkname: "target-dir"})).To(Succeed())
+					Expect(tarWriter.WriteHeader(&tar.Header{Name: "replaced/", Typeflag: tar.TypeDir, Mode: 0o755})).To(Succeed())
+					Expect(tarWriter.WriteHeader(&tar.Header{Name: "replaced/file", Typeflag: tar.TypeReg, Mode: 0o644, Size: 5})).To(Succeed())
+					_, err := tarWriter.Write([]byte("hello"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(tarWriter.Close()).To(Succeed())
+					stream = buffer
+				})
+
+				It("unpacks a directory in place of the symlink", func() {
+					_, err := tarUnpacker.WithWorkers(8).Unpack(logger, base_image_puller.UnpackSpec{
+						Stream:     stream,
+						TargetPath: targetPath,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					stat, err := os.Lstat(filepath.Join(targetPath, "replaced"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stat.IsDir()).To(BeTrue())
+					Expect(os.ReadFile(filepath.Join(targetPath, "replaced", "file"))).To(BeEquivalentTo("hello"))
+
+					Expect(filepath.Join(targetPath, "target-dir", "file")).NotTo(BeAnExistingFile())
+					Expect(os.ReadFile(filepath.Join(targetPath, "target-dir", "file-19"))).To(BeEquivalentTo("file-19"))
+				})
+			})
+
 			Describe("when BaseDirectory is provided", func() {
 				It("unpacks the symlinks inside that directory", func() {
 					_, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
@@ -824,6 +962,42 @@ var _ = Describe("Tar unpacker", func() {
 		})
 	})
 
+	Context("when exclude paths are provided", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(path.Join(baseImagePath, "usr", "share", "doc", "groot"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(path.Join(baseImagePath, "usr", "share", "doc", "groot", "README"), []byte("i am groot"), 0o644)).To(Succeed())
+			Expect(os.MkdirAll(path.Join(baseImagePath, "var", "cache", "apt"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(path.Join(baseImagePath, "var", "cache", "apt", "pkgcache.bin"), []byte("cache"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(path.Join(baseImagePath, "var", "cache", "keep"), []byte("keep"), 0o644)).To(Succeed())
+		})
+
+		It("does not unpack the excluded paths", func() {
+			_, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+				Stream:       stream,
+				TargetPath:   targetPath,
+				ExcludePaths: []string{"/usr/share/doc", "/var/cache/a*"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(path.Join(targetPath, "usr", "share")).To(BeADirectory())
+			Expect(path.Join(targetPath, "usr", "share", "doc")).NotTo(BeAnExistingFile())
+			Expect(path.Join(targetPath, "var", "cache", "apt")).NotTo(BeAnExistingFile())
+			Expect(path.Join(targetPath, "var", "cache", "keep")).To(BeARegularFile())
+		})
+
+		It("counts the excluded bytes separately", func() {
+			unpackOutput, err := tarUnpacker.Unpack(logger, base_image_puller.UnpackSpec{
+				Stream:       stream,
+				TargetPath:   targetPath,
+				ExcludePaths: []string{"/usr/share/doc"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(unpackOutput.BytesExcluded).To(Equal(int64(len("i am groot"))))
+			Expect(unpackOutput.BytesWritten).To(Equal(int64(len("cache") + len("keep"))))
+		})
+	})
+
 	Context("when it fails to untar", func() {
 		JustBeforeEach(func() {
 			stream = gbytes.NewBuffer()
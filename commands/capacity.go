@@ -3,8 +3,16 @@ package commands // import "code.cloudfoundry.org/grootfs/commands"
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 
 	"code.cloudfoundry.org/grootfs/commands/config"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/garbage_collector"
+	imagemanagerpkg "code.cloudfoundry.org/grootfs/store/image_manager"
 	"code.cloudfoundry.org/lager/v3"
 	"github.com/urfave/cli/v2"
 )
@@ -26,9 +34,39 @@ var CapacityCommand = cli.Command{
 			return cli.Exit(err.Error(), 1)
 		}
 
-		_ = json.NewEncoder(os.Stdout).Encode(map[string]uint64{
-			"capacity": uint64(cfg.Init.StoreSizeBytes),
-		})
+		// a store yet to be initialized will have the configured size
+		if _, err := os.Stat(cfg.StorePath); os.IsNotExist(err) {
+			_ = json.NewEncoder(os.Stdout).Encode(storepkg.StoreCapacity{
+				Capacity: cfg.Init.StoreSizeBytes,
+			})
+			return nil
+		}
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(cfg.StorePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		imageManager := imagemanagerpkg.NewImageManager(fsDriver, cfg.StorePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(cfg.StorePath, storepkg.MetaDirName, "dependencies"),
+		)
+
+		nsFsDriver, err := createImageDriver(logger, cfg, fsDriver)
+		if err != nil {
+			logger.Error("failed-to-create-image-driver", err)
+			return cli.Exit(err.Error(), 1)
+		}
+		gc := garbage_collector.NewGC(nsFsDriver, imageManager, dependencyManager)
+		sm := storepkg.NewStoreMeasurer(cfg.StorePath, fsDriver, gc)
+
+		capacity, err := sm.Capacity(logger)
+		if err != nil {
+			logger.Error("measuring-capacity-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if err := json.NewEncoder(os.Stdout).Encode(capacity); err != nil {
+			logger.Error("encoding-capacity", err)
+			return cli.Exit(err.Error(), 1)
+		}
 
 		return nil
 	},
@@ -1,6 +1,7 @@
 package commands // import "code.cloudfoundry.org/grootfs/commands"
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -38,6 +39,18 @@ var CleanCommand = cli.Command{
 			Name:  "threshold-bytes",
 			Usage: "Disk usage of the store directory at which cleanup should trigger",
 		},
+		&cli.Int64Flag{
+			Name:  "target-bytes",
+			Usage: "Only clean up the least recently used layers needed to bring the store directory disk usage below this",
+		},
+		&cli.DurationFlag{
+			Name:  "incomplete-grace-period",
+			Usage: "How long a layer is left incomplete before being cleaned up, e.g.: 6h (default: 1h)",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the layers that would be cleaned up as JSON, without removing them",
+		},
 	},
 
 	Action: func(ctx *cli.Context) (exitError error) {
@@ -47,6 +60,10 @@ var CleanCommand = cli.Command{
 		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
 		configBuilder.WithCleanThresholdBytes(ctx.Int64("threshold-bytes"),
 			ctx.IsSet("threshold-bytes"))
+		configBuilder.WithCleanTargetBytes(ctx.Int64("target-bytes"),
+			ctx.IsSet("target-bytes"))
+		configBuilder.WithCleanIncompleteGracePeriod(ctx.Duration("incomplete-grace-period"),
+			ctx.IsSet("incomplete-grace-period"))
 
 		cfg, err := configBuilder.Build()
 		if err != nil {
@@ -99,7 +116,8 @@ var CleanCommand = cli.Command{
 		gc := garbage_collector.NewGC(nsFsDriver, imageManager, dependencyManager)
 		sm := storepkg.NewStoreMeasurer(cfg.StorePath, fsDriver, gc)
 
-		cleaner := groot.IamCleaner(locksmith, sm, gc, metricsEmitter, GET_LOCK_TIMEOUT, CLEANING_TIMEOUT)
+		cleaner := groot.IamCleaner(locksmith, sm, gc, metricsEmitter, GET_LOCK_TIMEOUT, CLEANING_TIMEOUT).
+			WithIncompleteGracePeriod(cfg.Clean.IncompleteGracePeriod)
 
 		defer func() {
 			if !timedOut {
@@ -117,7 +135,21 @@ var CleanCommand = cli.Command{
 			}
 		}()
 
-		noop, err := cleaner.Clean(logger, cfg.Clean.ThresholdBytes)
+		if ctx.Bool("dry-run") {
+			report, err := cleaner.DryRun(logger, cfg.Clean.ThresholdBytes, cfg.Clean.TargetBytes)
+			if err != nil {
+				logger.Error("dry-running-clean", err)
+				return cli.Exit(err.Error(), 1)
+			}
+
+			if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+				logger.Error("encoding-report", err)
+				return cli.Exit(err.Error(), 1)
+			}
+			return nil
+		}
+
+		noop, err := cleaner.CleanToTarget(logger, cfg.Clean.ThresholdBytes, cfg.Clean.TargetBytes)
 		if err != nil {
 			if errors.As(err, &groot.CleaningTimeoutError{}) {
 				// There was a bug where cleaner hung forever, likely on disk
@@ -131,6 +163,10 @@ var CleanCommand = cli.Command{
 		}
 
 		if noop {
+			if cfg.Clean.ThresholdBytes == 0 {
+				fmt.Println("target not reached: skipping clean")
+				return nil
+			}
 			fmt.Println("threshold not reached: skipping clean")
 			return nil
 		}
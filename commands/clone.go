@@ -0,0 +1,107 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/commands/idfinder"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var CloneCommand = cli.Command{
+	Name:        "clone",
+	Usage:       "clone [options] <source id|image path> <id>",
+	Description: "Creates a root filesystem with a copy of the changes made to an existing image",
+
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:  "disk-limit-size-bytes",
+			Usage: "Inclusive disk limit (i.e: includes all layers in the filesystem). If not specified, the source image quota is used",
+		},
+		&cli.BoolFlag{
+			Name:  "exclude-image-from-quota",
+			Usage: "Set disk limit to be exclusive (i.e.: excluding image layers)",
+		},
+		&cli.BoolFlag{
+			Name:  "with-mount",
+			Usage: "Mount the root filesystem after creation. This may require root privileges.",
+		},
+		&cli.BoolFlag{
+			Name:  "without-mount",
+			Usage: "Do not mount the root filesystem.",
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("clone")
+
+		if ctx.NArg() != 2 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		configBuilder.WithExcludeImageFromQuota(ctx.Bool("exclude-image-from-quota"),
+			ctx.IsSet("exclude-image-from-quota")).
+			WithMount(ctx.IsSet("with-mount"), ctx.IsSet("without-mount"))
+		cfg, err := configBuilder.Build()
+		logger.Debug("clone-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		sourceID, err := idfinder.FindID(storePath, ctx.Args().First())
+		if err != nil {
+			logger.Error("find-id-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		sharedLocksmith := locksmithpkg.NewSharedFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+
+		cloner := groot.IamCloner(imageManager, sharedLocksmith, dependencyManager, metricsEmitter)
+		cloneSpec := groot.CloneSpec{
+			SourceID:                  sourceID,
+			ID:                        ctx.Args().Get(1),
+			Mount:                     !cfg.Create.WithoutMount,
+			DiskLimit:                 ctx.Int64("disk-limit-size-bytes"),
+			ExcludeBaseImageFromQuota: cfg.Create.ExcludeImageFromQuota,
+		}
+		image, err := cloner.Clone(logger, cloneSpec)
+		if err != nil {
+			logger.Error("cloning-image-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if err := printContainerSpec(image); err != nil {
+			logger.Error("formatting output", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,125 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/commands/idfinder"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/grootfs/store/ref_manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var CommitCommand = cli.Command{
+	Name:        "commit",
+	Usage:       "commit <id|image path> <new-ref>",
+	Description: "Saves the changes made to an image as a new layer, so that it can be used as a base image: ref:///<new-ref>",
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("commit")
+
+		if ctx.NArg() != 2 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("commit-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		id, err := idfinder.FindID(storePath, ctx.Args().First())
+		if err != nil {
+			logger.Error("find-id-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+		ref := ctx.Args().Get(1)
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		sharedLocksmith := locksmithpkg.NewSharedFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		refManager := ref_manager.NewRefManager(filepath.Join(storePath, storepkg.MetaDirName, "refs"))
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+
+		committer := groot.IamCommitter(imageManager, refManager, sharedLocksmith, dependencyManager, metricsEmitter)
+		if _, err := committer.Commit(logger, id, ref); err != nil {
+			logger.Error("committing-image-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Printf("Image %s committed as %s\n", id, ref)
+		return nil
+	},
+}
+
+var DeleteRefCommand = cli.Command{
+	Name:        "delete-ref",
+	Usage:       "delete-ref <ref>",
+	Description: "Deletes a ref made with commit, so that its layers can be cleaned up once no image uses them",
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("delete-ref")
+
+		if ctx.NArg() != 1 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("delete-ref-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		ref := ctx.Args().First()
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		sharedLocksmith := locksmithpkg.NewSharedFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		refManager := ref_manager.NewRefManager(filepath.Join(storePath, storepkg.MetaDirName, "refs"))
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+
+		committer := groot.IamCommitter(imageManager, refManager, sharedLocksmith, dependencyManager, metricsEmitter)
+		if err := committer.DeleteRef(logger, ref); err != nil {
+			logger.Error("deleting-ref-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Printf("Ref %s deleted\n", ref)
+		return nil
+	},
+}
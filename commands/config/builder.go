@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"time"
 
 	errorspkg "github.com/pkg/errors"
 
@@ -30,10 +32,16 @@ type Create struct {
 	DiskLimitSizeBytes                int64    `yaml:"disk_limit_size_bytes"`
 	InsecureRegistries                []string `yaml:"insecure_registries"`
 	RemoteLayerClientCertificatesPath string   `yaml:"remote_layer_client_certificates_path"`
+	ExcludePaths                      []string `yaml:"exclude_paths"`
+	Squash                            bool     `yaml:"squash"`
 }
 
 type Clean struct {
 	ThresholdBytes int64 `yaml:"threshold_bytes"`
+	TargetBytes    int64 `yaml:"target_bytes"`
+	// IncompleteGracePeriod is how long a volume is left incomplete before
+	// clean reclaims it. 0 leaves it to the default.
+	IncompleteGracePeriod time.Duration `yaml:"incomplete_grace_period"`
 }
 
 type Init struct {
@@ -74,6 +82,20 @@ func (b *Builder) Build() (Config, error) {
 		return *b.config, errorspkg.New("invalid argument: clean threshold cannot be negative")
 	}
 
+	if b.config.Clean.TargetBytes < 0 {
+		return *b.config, errorspkg.New("invalid argument: clean target cannot be negative")
+	}
+
+	if b.config.Clean.IncompleteGracePeriod < 0 {
+		return *b.config, errorspkg.New("invalid argument: clean incomplete grace period cannot be negative")
+	}
+
+	for _, excludePath := range b.config.Create.ExcludePaths {
+		if _, err := filepath.Match(excludePath, ""); err != nil {
+			return *b.config, errorspkg.Errorf("invalid argument: exclude path `%s` is not a valid pattern", excludePath)
+		}
+	}
+
 	return *b.config, nil
 }
 
@@ -86,6 +108,15 @@ func (b *Builder) WithInsecureRegistries(insecureRegistries []string) *Builder {
 	return b
 }
 
+func (b *Builder) WithExcludePaths(excludePaths []string) *Builder {
+	if len(excludePaths) == 0 {
+		return b
+	}
+
+	b.config.Create.ExcludePaths = excludePaths
+	return b
+}
+
 func (b *Builder) WithStorePath(storePath string, isSet bool) *Builder {
 	if isSet || b.config.StorePath == "" {
 		b.config.StorePath = storePath
@@ -145,6 +176,13 @@ func (b *Builder) WithSkipLayerValidation(skip, isSet bool) *Builder {
 	return b
 }
 
+func (b *Builder) WithSquash(squash, isSet bool) *Builder {
+	if isSet {
+		b.config.Create.Squash = squash
+	}
+	return b
+}
+
 func (b *Builder) WithCleanThresholdBytes(threshold int64, isSet bool) *Builder {
 	if isSet {
 		b.config.Clean.ThresholdBytes = threshold
@@ -152,6 +190,20 @@ func (b *Builder) WithCleanThresholdBytes(threshold int64, isSet bool) *Builder
 	return b
 }
 
+func (b *Builder) WithCleanTargetBytes(target int64, isSet bool) *Builder {
+	if isSet {
+		b.config.Clean.TargetBytes = target
+	}
+	return b
+}
+
+func (b *Builder) WithCleanIncompleteGracePeriod(gracePeriod time.Duration, isSet bool) *Builder {
+	if isSet {
+		b.config.Clean.IncompleteGracePeriod = gracePeriod
+	}
+	return b
+}
+
 func (b *Builder) WithLogLevel(level string, isSet bool) *Builder {
 	if isSet {
 		b.config.LogLevel = level
@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"path"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/commands/config"
 	yaml "gopkg.in/yaml.v2"
@@ -27,12 +28,15 @@ var _ = Describe("Builder", func() {
 			WithoutMount:          false,
 			ExcludeImageFromQuota: true,
 			SkipLayerValidation:   true,
+			Squash:                true,
 			InsecureRegistries:    []string{"http://example.org"},
 			DiskLimitSizeBytes:    int64(1000),
 		}
 
 		cleanCfg = config.Clean{
-			ThresholdBytes: int64(0),
+			ThresholdBytes:        int64(0),
+			TargetBytes:           int64(0),
+			IncompleteGracePeriod: 2 * time.Hour,
 		}
 
 		cfg = config.Config{
@@ -96,6 +100,17 @@ var _ = Describe("Builder", func() {
 			})
 		})
 
+		Context("when clean target property is invalid", func() {
+			BeforeEach(func() {
+				cfg.Clean.TargetBytes = int64(-1)
+			})
+
+			It("returns an error", func() {
+				_, err := builder.Build()
+				Expect(err).To(MatchError("invalid argument: clean target cannot be negative"))
+			})
+		})
+
 		Context("when config is invalid", func() {
 			JustBeforeEach(func() {
 				configFilePath = path.Join(configDir, "invalid_config.yaml")
@@ -137,6 +152,36 @@ var _ = Describe("Builder", func() {
 		})
 	})
 
+	Describe("WithExcludePaths", func() {
+		BeforeEach(func() {
+			cfg.Create.ExcludePaths = []string{"/usr/share/doc"}
+		})
+
+		It("overrides the config's ExcludePaths entry", func() {
+			builder = builder.WithExcludePaths([]string{"/usr/share/man", "/var/cache/*"})
+			config, err := builder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Create.ExcludePaths).To(Equal([]string{"/usr/share/man", "/var/cache/*"}))
+		})
+
+		Context("when empty", func() {
+			It("doesn't override the config's ExcludePaths entry", func() {
+				builder = builder.WithExcludePaths([]string{})
+				config, err := builder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.Create.ExcludePaths).To(Equal([]string{"/usr/share/doc"}))
+			})
+		})
+
+		Context("when a pattern is invalid", func() {
+			It("returns an error", func() {
+				builder = builder.WithExcludePaths([]string{"/usr/share/[doc"})
+				_, err := builder.Build()
+				Expect(err).To(MatchError("invalid argument: exclude path `/usr/share/[doc` is not a valid pattern"))
+			})
+		})
+	})
+
 	Describe("WithStorePath", func() {
 		It("overrides the config's store path entry when command line flag is set", func() {
 			builder = builder.WithStorePath("/mnt/grootfs/data", true)
@@ -343,6 +388,24 @@ var _ = Describe("Builder", func() {
 		})
 	})
 
+	Describe("WithSquash", func() {
+		It("overrides the config's Squash when the flag is set", func() {
+			builder = builder.WithSquash(false, true)
+			config, err := builder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Create.Squash).To(BeFalse())
+		})
+
+		Context("when flag is not set", func() {
+			It("uses the config entry", func() {
+				builder = builder.WithSquash(false, false)
+				config, err := builder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.Create.Squash).To(BeTrue())
+			})
+		})
+	})
+
 	Describe("WithCleanThresholdBytes", func() {
 		It("overrides the config's CleanThresholdBytes entry when the flag is set", func() {
 			builder = builder.WithCleanThresholdBytes(1024, true)
@@ -361,6 +424,62 @@ var _ = Describe("Builder", func() {
 		})
 	})
 
+	Describe("WithCleanIncompleteGracePeriod", func() {
+		It("overrides the config's IncompleteGracePeriod entry when the flag is set", func() {
+			builder = builder.WithCleanIncompleteGracePeriod(6*time.Hour, true)
+			config, err := builder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Clean.IncompleteGracePeriod).To(Equal(6 * time.Hour))
+		})
+
+		Context("when the flag is not set", func() {
+			It("uses the config entry", func() {
+				builder = builder.WithCleanIncompleteGracePeriod(6*time.Hour, false)
+				config, err := builder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.Clean.IncompleteGracePeriod).To(Equal(2 * time.Hour))
+			})
+		})
+
+		Context("when the config entry is a duration string", func() {
+			It("parses it", func() {
+				Expect(os.WriteFile(configFilePath, []byte("clean:\n  incomplete_grace_period: 90m\n"), 0644)).To(Succeed())
+				builder, err := config.NewBuilder(configFilePath)
+				Expect(err).NotTo(HaveOccurred())
+
+				config, err := builder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.Clean.IncompleteGracePeriod).To(Equal(90 * time.Minute))
+			})
+		})
+
+		Context("when it is negative", func() {
+			It("returns an error", func() {
+				builder = builder.WithCleanIncompleteGracePeriod(-time.Hour, true)
+				_, err := builder.Build()
+				Expect(err).To(MatchError("invalid argument: clean incomplete grace period cannot be negative"))
+			})
+		})
+	})
+
+	Describe("WithCleanTargetBytes", func() {
+		It("overrides the config's CleanTargetBytes entry when the flag is set", func() {
+			builder = builder.WithCleanTargetBytes(2048, true)
+			config, err := builder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Clean.TargetBytes).To(Equal(int64(2048)))
+		})
+
+		Context("when flag is not set", func() {
+			It("uses the config entry", func() {
+				builder = builder.WithCleanTargetBytes(2048, false)
+				config, err := builder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.Clean.TargetBytes).To(Equal(cfg.Clean.TargetBytes))
+			})
+		})
+	})
+
 	Describe("WithLogLevel", func() {
 		It("overrides the config's Log Level entry", func() {
 			builder = builder.WithLogLevel("debug", true)
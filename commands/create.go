@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -17,6 +18,7 @@ import (
 	"code.cloudfoundry.org/grootfs/commands/config"
 	"code.cloudfoundry.org/grootfs/fetcher/layer_fetcher"
 	"code.cloudfoundry.org/grootfs/fetcher/layer_fetcher/source"
+	"code.cloudfoundry.org/grootfs/fetcher/ref_fetcher"
 	"code.cloudfoundry.org/grootfs/fetcher/tar_fetcher"
 	"code.cloudfoundry.org/grootfs/groot"
 	"code.cloudfoundry.org/grootfs/metrics"
@@ -31,6 +33,7 @@ import (
 	"code.cloudfoundry.org/grootfs/store/image_manager"
 	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
 	"code.cloudfoundry.org/grootfs/store/manager"
+	"code.cloudfoundry.org/grootfs/store/ref_manager"
 	"code.cloudfoundry.org/lager/v3"
 
 	"github.com/containers/image/v5/types"
@@ -94,6 +97,26 @@ var CreateCommand = cli.Command{
 			Name:  "clean-log-file",
 			Usage: "File to write the clean-on-create logs to. If not specified, stderr is used",
 		},
+		&cli.StringSliceFlag{
+			Name:  "uid-mapping",
+			Usage: "UID mapping for this image, overriding the store ones, e.g.: <Namespace UID>:<Host UID>:<Size>",
+		},
+		&cli.StringSliceFlag{
+			Name:  "gid-mapping",
+			Usage: "GID mapping for this image, overriding the store ones, e.g.: <Namespace GID>:<Host GID>:<Size>",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude-path",
+			Usage: "Glob of an image path to leave out when unpacking layers, e.g.: /usr/share/doc",
+		},
+		&cli.BoolFlag{
+			Name:  "squash",
+			Usage: "Mount the image on a single volume flattening all its layers",
+		},
+		&cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "Label to keep with the image, e.g.: <key>=<value>",
+		},
 	},
 
 	Action: func(ctx *cli.Context) error {
@@ -116,6 +139,8 @@ var CreateCommand = cli.Command{
 			WithCleanThresholdBytes(ctx.Int64("threshold-bytes"), ctx.IsSet("threshold-bytes")).
 			WithClean(ctx.IsSet("with-clean"), ctx.IsSet("without-clean")).
 			WithCleanLog(ctx.String("clean-log-file")).
+			WithExcludePaths(ctx.StringSlice("exclude-path")).
+			WithSquash(ctx.Bool("squash"), ctx.IsSet("squash")).
 			WithMount(ctx.IsSet("with-mount"), ctx.IsSet("without-mount"))
 
 		cfg, err := configBuilder.Build()
@@ -129,6 +154,12 @@ var CreateCommand = cli.Command{
 			return cli.Exit(err.Error(), 1)
 		}
 
+		labels, err := parseLabels(ctx.StringSlice("label"))
+		if err != nil {
+			logger.Error("parsing-command", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
 		storePath := cfg.StorePath
 		id := ctx.Args().Tail()[0]
 		baseImage := ctx.Args().First()
@@ -157,18 +188,34 @@ var CreateCommand = cli.Command{
 			return cli.Exit("Store path is not initialized. Please run init-store.", 1)
 		}
 
-		idMappings, err := storeNamespacer.Read()
+		storeIDMappings, err := storeNamespacer.Read()
 		if err != nil {
 			logger.Error("reading-namespace-file", err)
 			return cli.Exit(err.Error(), 1)
 		}
 
+		idMappings, err := imageIDMappings(ctx, storeIDMappings)
+		if err != nil {
+			logger.Error("parsing-command", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
 		shouldCloneUserNs := hasIDMappings(idMappings) && os.Getuid() != 0
 
+		// layers are only kept unmapped when the image is mounted by us, as the
+		// idmapped lowerdirs need to be in place for the mount to make sense
+		idMappedMount := hasIDMappings(idMappings) && os.Getuid() == 0 &&
+			!cfg.Create.WithoutMount && fsDriver.SupportsIDMappedMounts(logger)
+		unpackIDMappings := idMappings
+		if idMappedMount {
+			logger.Debug("using-idmapped-mounts")
+			unpackIDMappings = groot.IDMappings{}
+		}
+
 		runner := linux_command_runner.New()
 		idMapper := unpackerpkg.NewIDMapper(cfg.NewuidmapBin, cfg.NewgidmapBin, runner)
 		reexecer := sandbox.NewReexecer(logger, idMapper, idMappings)
-		unpacker := unpackerpkg.NewNSIdMapperUnpacker(storePath, reexecer, shouldCloneUserNs, idMappings)
+		unpacker := unpackerpkg.NewNSIdMapperUnpacker(storePath, reexecer, shouldCloneUserNs, unpackIDMappings)
 
 		baseDirHandler := base_image_puller.NewBasedirHandler(reexecer, shouldCloneUserNs)
 
@@ -180,7 +227,7 @@ var CreateCommand = cli.Command{
 
 		systemContext := createSystemContext(baseImageURL, cfg.Create, ctx.String("username"), ctx.String("password"))
 
-		fetcher := createFetcher(baseImageURL, systemContext, cfg.Create)
+		fetcher := createFetcher(storePath, baseImageURL, systemContext, cfg.Create)
 		defer func() {
 			err := fetcher.Close()
 			if err != nil {
@@ -216,6 +263,11 @@ var CreateCommand = cli.Command{
 			GIDMappings:                 idMappings.GIDMappings,
 			CleanOnCreate:               cfg.Create.WithClean,
 			CleanOnCreateThresholdBytes: cfg.Clean.ThresholdBytes,
+			ExcludePaths:                cfg.Create.ExcludePaths,
+			IDMappedMount:               idMappedMount,
+			StoreIDMappings:             storeIDMappings,
+			Squash:                      cfg.Create.Squash,
+			Labels:                      labels,
 		}
 		image, err := creator.Create(logger, createSpec)
 		if err != nil {
@@ -224,31 +276,10 @@ var CreateCommand = cli.Command{
 			return cli.Exit(humanizedError, 1)
 		}
 
-		containerSpec := specs.Spec{
-			Root: &specs.Root{
-				Path: image.Rootfs,
-			},
-			Process: &specs.Process{
-				Env: image.Image.Config.Env,
-			},
-			Mounts: []specs.Mount{},
-		}
-
-		for _, mount := range image.Mounts {
-			containerSpec.Mounts = append(containerSpec.Mounts, specs.Mount{
-				Destination: mount.Destination,
-				Type:        mount.Type,
-				Source:      mount.Source,
-				Options:     mount.Options,
-			})
-		}
-
-		jsonBytes, err := json.Marshal(containerSpec)
-		if err != nil {
+		if err := printContainerSpec(image); err != nil {
 			logger.Error("formatting output", err)
 			return cli.Exit(err.Error(), 1)
 		}
-		fmt.Println(string(jsonBytes))
 
 		emitMetrics(logger, metricsEmitter, sm, cfg.Create.WithClean, storePath)
 
@@ -256,6 +287,36 @@ var CreateCommand = cli.Command{
 	},
 }
 
+// printContainerSpec writes the runtime spec bits describing the image rootfs
+// to stdout
+func printContainerSpec(image groot.ImageInfo) error {
+	containerSpec := specs.Spec{
+		Root: &specs.Root{
+			Path: image.Rootfs,
+		},
+		Process: &specs.Process{
+			Env: image.Image.Config.Env,
+		},
+		Mounts: []specs.Mount{},
+	}
+
+	for _, mount := range image.Mounts {
+		containerSpec.Mounts = append(containerSpec.Mounts, specs.Mount{
+			Destination: mount.Destination,
+			Type:        mount.Type,
+			Source:      mount.Source,
+			Options:     mount.Options,
+		})
+	}
+
+	jsonBytes, err := json.Marshal(containerSpec)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBytes))
+	return nil
+}
+
 func emitMetrics(logger lager.Logger, metricsEmitter *metrics.Emitter, sm *storepkg.StoreMeasurer, cleanOnCreate bool, storePath string) {
 	if !cleanOnCreate {
 		unusedVolumesSize, err := sm.UnusedVolumesSize(logger)
@@ -290,9 +351,13 @@ func emitMetrics(logger lager.Logger, metricsEmitter *metrics.Emitter, sm *store
 	metricsEmitter.TryEmitUsage(logger, "UsedBackingStoreInBytes", usedBackingStore, "bytes")
 }
 
-func createFetcher(baseImageUrl *url.URL, systemContext types.SystemContext, createCfg config.Create) base_image_puller.Fetcher {
-	if baseImageUrl.Scheme == "" {
+func createFetcher(storePath string, baseImageUrl *url.URL, systemContext types.SystemContext, createCfg config.Create) base_image_puller.Fetcher {
+	switch baseImageUrl.Scheme {
+	case "":
 		return tar_fetcher.NewTarFetcher(baseImageUrl)
+	case "ref":
+		refManager := ref_manager.NewRefManager(filepath.Join(storePath, storepkg.MetaDirName, "refs"))
+		return ref_fetcher.NewRefFetcher(refManager, baseImageUrl)
 	}
 
 	skipOCILayerValidation := createCfg.SkipLayerValidation && baseImageUrl.Scheme == "oci"
@@ -388,6 +453,32 @@ func tryHumanize(err error, spec groot.CreateSpec) string {
 	return tryParsingErrorMessage(err).Error()
 }
 
+// imageIDMappings returns the mappings given on the command line, falling
+// back to the store ones when none are provided
+func imageIDMappings(ctx *cli.Context, storeIDMappings groot.IDMappings) (groot.IDMappings, error) {
+	if !ctx.IsSet("uid-mapping") && !ctx.IsSet("gid-mapping") {
+		return storeIDMappings, nil
+	}
+
+	uidMappings, err := parseIDMappings(ctx.StringSlice("uid-mapping"))
+	if err != nil {
+		return groot.IDMappings{}, errorspkg.Errorf("parsing uid-mapping: %s", err)
+	}
+	gidMappings, err := parseIDMappings(ctx.StringSlice("gid-mapping"))
+	if err != nil {
+		return groot.IDMappings{}, errorspkg.Errorf("parsing gid-mapping: %s", err)
+	}
+	idMappings := groot.IDMappings{UIDMappings: uidMappings, GIDMappings: gidMappings}
+
+	// images are deleted within the store user namespace when not running as
+	// root, so they can't own files outside of it
+	if os.Getuid() != 0 && !reflect.DeepEqual(idMappings, storeIDMappings) {
+		return groot.IDMappings{}, errorspkg.New("id mappings different from the store ones can only be used by root")
+	}
+
+	return idMappings, nil
+}
+
 func validateOptions(ctx *cli.Context, cfg config.Config) error {
 	if ctx.IsSet("with-clean") && ctx.IsSet("without-clean") {
 		return errorspkg.New("with-clean and without-clean cannot be used together")
@@ -3,6 +3,7 @@ package commands // import "code.cloudfoundry.org/grootfs/commands"
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"code.cloudfoundry.org/grootfs/commands/config"
 	"code.cloudfoundry.org/grootfs/commands/idfinder"
@@ -22,14 +23,35 @@ import (
 
 var DeleteCommand = cli.Command{
 	Name:        "delete",
-	Usage:       "delete <id|image path>",
-	Description: "Deletes a container image",
+	Usage:       "delete <id|image path> | delete --label <key>[=<value>]",
+	Description: "Deletes a container image, or all the images with the given labels",
+
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "Delete the images with this label instead, e.g.: <key>=<value>, or <key> for any value",
+		},
+	},
 
 	Action: func(ctx *cli.Context) error {
 		logger := ctx.App.Metadata["logger"].(lager.Logger)
 		logger = logger.Session("delete")
 
-		if ctx.NArg() != 1 {
+		labelFilter := groot.ImageFilter{}
+		for _, selector := range ctx.StringSlice("label") {
+			if err := addLabelFilter(&labelFilter, selector); err != nil {
+				logger.Error("parsing-command", err)
+				return cli.Exit(err.Error(), 1)
+			}
+		}
+		byLabel := len(ctx.StringSlice("label")) > 0
+
+		if byLabel && ctx.NArg() != 0 {
+			logger.Error("parsing-command", errorspkg.New("both an id and labels were specified"))
+			return cli.Exit("either an id or labels must be specified, not both", 1)
+		}
+
+		if !byLabel && ctx.NArg() != 1 {
 			logger.Error("parsing-command", errorspkg.New("id was not specified"))
 			return cli.Exit("id was not specified", 1)
 		}
@@ -43,12 +65,15 @@ var DeleteCommand = cli.Command{
 		}
 
 		storePath := cfg.StorePath
-		idOrPath := ctx.Args().First()
-		id, err := idfinder.FindID(storePath, idOrPath)
-		if err != nil {
-			logger.Debug("id-not-found-skipping", lager.Data{"id": idOrPath, "storePath": storePath, "errorMessage": err.Error()})
-			fmt.Printf("%s Skipping delete.\n", err)
-			return nil
+		var id string
+		if !byLabel {
+			idOrPath := ctx.Args().First()
+			id, err = idfinder.FindID(storePath, idOrPath)
+			if err != nil {
+				logger.Debug("id-not-found-skipping", lager.Data{"id": idOrPath, "storePath": storePath, "errorMessage": err.Error()})
+				fmt.Printf("%s Skipping delete.\n", err)
+				return nil
+			}
 		}
 
 		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
@@ -85,6 +110,10 @@ var DeleteCommand = cli.Command{
 			metricsEmitter.TryEmitUsage(logger, "UsedLayersSize", usedVolumesSize, "bytes")
 		}()
 
+		if byLabel {
+			return deleteLabelledImages(logger, storePath, labelFilter, deleter, groot.IamLister(imageManager, dependencyManager, fsDriver))
+		}
+
 		err = deleter.Delete(logger, id)
 		if err != nil {
 			logger.Error("deleting-image-failed", err)
@@ -95,3 +124,37 @@ var DeleteCommand = cli.Command{
 		return nil
 	},
 }
+
+// deleteLabelledImages deletes every image matching the label filter, carrying on
+// when one fails and reporting all the failures at the end. Images that can't
+// be inspected, e.g. while a create is still building them, are skipped
+func deleteLabelledImages(logger lager.Logger, storePath string, labelFilter groot.ImageFilter, deleter *groot.Deleter, lister *groot.Lister) error {
+	images, err := lister.ListImages(logger, storePath, labelFilter)
+	if err != nil {
+		logger.Error("listing-images-failed", err)
+		return cli.Exit(err.Error(), 1)
+	}
+
+	failures := []string{}
+	for _, image := range images {
+		// the labels of images that can't be inspected are unknown, so they
+		// are left alone
+		if image.Error != "" {
+			logger.Info("skipping-uninspectable-image", lager.Data{"id": image.ID, "errorMessage": image.Error})
+			fmt.Printf("Image %s skipped: %s\n", image.ID, image.Error)
+			continue
+		}
+
+		if err := deleter.Delete(logger, image.ID); err != nil {
+			logger.Error("deleting-image-failed", err, lager.Data{"id": image.ID})
+			failures = append(failures, fmt.Sprintf("%s: %s", image.ID, err))
+			continue
+		}
+		fmt.Printf("Image %s deleted\n", image.ID)
+	}
+
+	if len(failures) > 0 {
+		return cli.Exit(fmt.Sprintf("failed to delete %d image(s):\n%s", len(failures), strings.Join(failures, "\n")), 1)
+	}
+	return nil
+}
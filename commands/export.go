@@ -0,0 +1,100 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/commands/idfinder"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var ExportCommand = cli.Command{
+	Name:        "export",
+	Usage:       "export [options] <id|image path> <oci layout path>",
+	Description: "Exports an image, including the changes made to it, as an OCI image layout",
+
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "tag",
+			Usage: "Tag of the image in the layout, e.g.: oci:///<oci layout path>:<tag>",
+		},
+		&cli.StringFlag{
+			Name:  "compression",
+			Usage: "Compression of the layers <gzip|zstd>",
+			Value: groot.CompressionGzip,
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("export")
+
+		if ctx.NArg() != 2 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("export-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		id, err := idfinder.FindID(storePath, ctx.Args().First())
+		if err != nil {
+			logger.Error("find-id-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		layoutPath, err := filepath.Abs(ctx.Args().Get(1))
+		if err != nil {
+			logger.Error("parsing-layout-path-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+		_, statErr := os.Stat(layoutPath)
+		layoutPathExisted := statErr == nil
+
+		idMappings, err := groot.NewStoreNamespacer(storePath).Read()
+		if err != nil {
+			logger.Error("reading-namespace-file", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		exporter := groot.IamExporter(imageManager, dependencyManager, metricsEmitter)
+		if _, err := exporter.ExportImage(logger, id, ctx.String("compression"), layoutPath, ctx.String("tag"), idMappings); err != nil {
+			logger.Error("exporting-image-failed", err)
+			if !layoutPathExisted {
+				if err := os.RemoveAll(layoutPath); err != nil {
+					logger.Error("removing-layout-failed", err)
+				}
+			}
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Printf("Image %s exported to %s\n", id, layoutPath)
+		return nil
+	},
+}
@@ -0,0 +1,113 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/commands/idfinder"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var ExportDiffCommand = cli.Command{
+	Name:        "export-diff",
+	Usage:       "export-diff [options] <id|image path>",
+	Description: "Exports the changes made to an image as an OCI layer",
+
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "File to write the layer to. If not specified, the layer is written to stdout and its description to stderr",
+		},
+		&cli.StringFlag{
+			Name:  "compression",
+			Usage: "Compression of the layer <gzip|zstd>",
+			Value: groot.CompressionGzip,
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("export-diff")
+
+		if ctx.NArg() != 1 {
+			logger.Error("parsing-command", errorspkg.New("id was not specified"))
+			return cli.Exit("id was not specified", 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("export-diff-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		id, err := idfinder.FindID(storePath, ctx.Args().First())
+		if err != nil {
+			logger.Error("find-id-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		idMappings, err := groot.NewStoreNamespacer(storePath).Read()
+		if err != nil {
+			logger.Error("reading-namespace-file", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		var output io.Writer = os.Stdout
+		descriptorOutput := os.Stdout
+		outputPath := ctx.String("output")
+		if outputPath == "" {
+			descriptorOutput = os.Stderr
+		} else {
+			outputFile, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+			if err != nil {
+				logger.Error("creating-output-file-failed", err)
+				return cli.Exit(err.Error(), 1)
+			}
+			defer outputFile.Close()
+			output = outputFile
+		}
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		exporter := groot.IamExporter(imageManager, dependencyManager, metricsEmitter)
+		descriptor, err := exporter.ExportDiff(logger, id, ctx.String("compression"), idMappings, output)
+		if err != nil {
+			logger.Error("exporting-diff-failed", err)
+			if outputPath != "" {
+				if err := os.Remove(outputPath); err != nil {
+					logger.Error("removing-output-file-failed", err)
+				}
+			}
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if err := json.NewEncoder(descriptorOutput).Encode(descriptor); err != nil {
+			logger.Error("encoding-layer-descriptor-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		return nil
+	},
+}
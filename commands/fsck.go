@@ -0,0 +1,96 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var FsckCommand = cli.Command{
+	Name:        "fsck",
+	Usage:       "fsck [options]",
+	Description: "Checks the store for leftovers of interrupted operations",
+
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "repair",
+			Usage: "Remove the leftovers found",
+		},
+	},
+
+	Action: func(ctx *cli.Context) (exitError error) {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("fsck")
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("fsck-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		if _, err := os.Stat(storePath); os.IsNotExist(err) {
+			err := errorspkg.Errorf("no store found at %s", storePath)
+			logger.Error("store-path-failed", err, nil)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		exclusiveLocksmith := locksmithpkg.NewExclusiveFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+
+		// clean destroys the volumes it marks after releasing the global lock
+		gcLockFile, err := exclusiveLocksmith.Lock(groot.GCLockKey)
+		if err != nil {
+			logger.Error("failed-to-acquire-lock", err)
+			return cli.Exit(err.Error(), 1)
+		}
+		defer func() {
+			if err := exclusiveLocksmith.Unlock(gcLockFile); err != nil {
+				logger.Error("release-lock-failed", err, nil)
+				exitError = cli.Exit(err.Error(), 1)
+			}
+		}()
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+
+		checker := groot.IamChecker(fsDriver, imageManager, dependencyManager, exclusiveLocksmith, metricsEmitter)
+		report, err := checker.Check(logger, ctx.Bool("repair"))
+		if err != nil {
+			logger.Error("checking-store-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			logger.Error("encoding-report", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if report.Inconsistencies() > 0 && !report.Repaired {
+			return cli.Exit(fmt.Sprintf("%d inconsistencies found", report.Inconsistencies()), 1)
+		}
+		return nil
+	},
+}
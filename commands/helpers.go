@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"code.cloudfoundry.org/commandrunner/linux_command_runner"
 	"code.cloudfoundry.org/grootfs/base_image_puller"
@@ -13,6 +14,7 @@ import (
 	"code.cloudfoundry.org/grootfs/store/filesystems/namespaced"
 	"code.cloudfoundry.org/grootfs/store/image_manager"
 	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
 )
 
 type fileSystemDriver interface {
@@ -31,6 +33,8 @@ type fileSystemDriver interface {
 	DestroyVolume(logger lager.Logger, id string) error
 	MoveVolume(logger lager.Logger, from, to string) error
 	WriteVolumeMeta(logger lager.Logger, id string, data base_image_puller.VolumeMeta) error
+	ReadVolumeMeta(logger lager.Logger, id string) (base_image_puller.VolumeMeta, error)
+	TouchVolume(logger lager.Logger, id, imageID string) error
 	HandleOpaqueWhiteouts(logger lager.Logger, id string, opaqueWhiteouts []string) error
 	Marshal(logger lager.Logger) ([]byte, error)
 	MarkVolumeArtifacts(logger lager.Logger, id string) error
@@ -65,6 +69,44 @@ func parseIDMappings(args []string) ([]groot.IDMappingSpec, error) {
 	return mappings, nil
 }
 
+// parseLabels takes <key>=<value> pairs. A bare key has an empty value.
+func parseLabels(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	labels := map[string]string{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		if key == "" {
+			return nil, errorspkg.Errorf("invalid label %q: expected <key>=<value>", arg)
+		}
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// addLabelFilter adds a <key>=<value> selector to the filter, matching that
+// exact value, or a bare <key> selector, matching any value
+func addLabelFilter(filter *groot.ImageFilter, selector string) error {
+	key, value, hasValue := strings.Cut(selector, "=")
+	if key == "" {
+		return errorspkg.Errorf("invalid label %q: expected <key>[=<value>]", selector)
+	}
+
+	if !hasValue {
+		filter.LabelKeys = append(filter.LabelKeys, key)
+		return nil
+	}
+
+	if filter.Labels == nil {
+		filter.Labels = map[string]string{}
+	}
+	filter.Labels[key] = value
+	return nil
+}
+
 func hasIDMappings(idMappings groot.IDMappings) bool {
 	return len(idMappings.UIDMappings) > 0 || len(idMappings.GIDMappings) > 0
 }
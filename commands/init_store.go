@@ -7,10 +7,12 @@ import (
 
 	"code.cloudfoundry.org/grootfs/commands/config"
 	"code.cloudfoundry.org/grootfs/groot"
+	storepkg "code.cloudfoundry.org/grootfs/store"
 	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
 	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
 	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
 	"code.cloudfoundry.org/grootfs/store/manager"
+	"code.cloudfoundry.org/grootfs/store/migrator"
 	"code.cloudfoundry.org/lager/v3"
 
 	errorspkg "github.com/pkg/errors"
@@ -109,6 +111,19 @@ var InitStoreCommand = cli.Command{
 			return cli.Exit(errorspkg.Cause(err).Error(), 1)
 		}
 
+		// create probes idmapped mounts support on every run otherwise
+		if err := fsDriver.RecordIDMappedMountsSupport(logger); err != nil {
+			logger.Error("recording-idmapped-mounts-support-failed", err)
+		}
+
+		// new stores get the current version, existing ones are brought to it
+		exclusiveLocksmith := locksmithpkg.NewExclusiveFileSystem(filepath.Join(storePath, storepkg.LocksDirName))
+		storeMigrator := migrator.NewMigrator(storePath, fsDriver).WithoutMountByDefault(cfg.Create.WithoutMount)
+		if _, _, err := migrateStoreExclusively(logger, storeMigrator, exclusiveLocksmith); err != nil {
+			logger.Error("migrating-store-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
 		return nil
 	},
 }
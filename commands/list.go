@@ -1,11 +1,22 @@
 package commands // import "code.cloudfoundry.org/grootfs/commands"
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/commands/config"
 	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	imageManagerpkg "code.cloudfoundry.org/grootfs/store/image_manager"
 	"code.cloudfoundry.org/lager/v3"
 	errorspkg "github.com/pkg/errors"
 
@@ -17,6 +28,25 @@ var ListCommand = cli.Command{
 	Usage:       "list",
 	Description: "Lists images in store",
 
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "pins",
+			Usage: "List the pinned images instead",
+		},
+		&cli.BoolFlag{
+			Name:  "volumes",
+			Usage: "List the volumes instead, with their size, reference count and collection status",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Describe each image or volume as JSON",
+		},
+		&cli.StringSliceFlag{
+			Name:  "filter",
+			Usage: "Only list the images matching a filter, e.g.: id=<prefix>, chain=<chain ID>, label=<key>[=<value>], older-than=<duration>, newer-than=<duration>",
+		},
+	},
+
 	Action: func(ctx *cli.Context) error {
 		logger := ctx.App.Metadata["logger"].(lager.Logger)
 		logger = logger.Session("list")
@@ -35,20 +65,140 @@ var ListCommand = cli.Command{
 			return cli.Exit(err.Error(), 1)
 		}
 
-		lister := groot.IamLister()
-		images, err := lister.List(logger, cfg.StorePath)
+		if ctx.Bool("pins") {
+			dependencyManager := dependency_manager.NewDependencyManager(
+				filepath.Join(cfg.StorePath, storepkg.MetaDirName, "dependencies"),
+			)
+			pinner := groot.IamPinner(nil, nil, dependencyManager, metrics.NewEmitter(logger, cfg.MetronEndpoint))
+			pins, err := pinner.Pins(logger)
+			if err != nil {
+				logger.Error("listing-pins", err, lager.Data{"storePath": cfg.StorePath})
+				return cli.Exit(fmt.Sprintf("Failed to retrieve list of pins: %s", err.Error()), 1)
+			}
+
+			if ctx.Bool("json") {
+				_ = json.NewEncoder(os.Stdout).Encode(pins)
+				return nil
+			}
+
+			for _, pin := range pins {
+				if pin.Options == "" {
+					fmt.Println(pin.BaseImage)
+					continue
+				}
+				fmt.Printf("%s (%s)\n", pin.BaseImage, pin.Options)
+			}
+			return nil
+		}
+
+		fsDriver := overlayxfs.NewDriver(cfg.StorePath, cfg.TardisBin, nil, loopback.NewNoopDirectIO())
+		imageManager := imageManagerpkg.NewImageManager(fsDriver, cfg.StorePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(cfg.StorePath, storepkg.MetaDirName, "dependencies"),
+		)
+		lister := groot.IamLister(imageManager, dependencyManager, fsDriver)
+
+		if ctx.Bool("volumes") {
+			volumes, err := lister.ListVolumes(logger)
+			if err != nil {
+				logger.Error("listing-volumes", err, lager.Data{"storePath": cfg.StorePath})
+				return cli.Exit(fmt.Sprintf("Failed to retrieve list of volumes: %s", err.Error()), 1)
+			}
+
+			if ctx.Bool("json") {
+				_ = json.NewEncoder(os.Stdout).Encode(volumes)
+				return nil
+			}
+			printVolumes(volumes)
+			return nil
+		}
+
+		filters := ctx.StringSlice("filter")
+		if !ctx.Bool("json") && len(filters) == 0 {
+			images, err := lister.List(logger, cfg.StorePath)
+			if err != nil {
+				logger.Error("listing-images", err, lager.Data{"storePath": cfg.StorePath})
+				return cli.Exit(fmt.Sprintf("Failed to retrieve list of images: %s", err.Error()), 1)
+			}
+
+			if len(images) == 0 {
+				fmt.Println("Store empty")
+			}
+			for _, image := range images {
+				fmt.Println(image)
+			}
+			return nil
+		}
+
+		filter, err := parseImageFilter(filters)
+		if err != nil {
+			logger.Error("parsing-filters", err, lager.Data{"filters": filters})
+			return cli.Exit(err.Error(), 1)
+		}
+
+		images, err := lister.ListImages(logger, cfg.StorePath, filter)
 		if err != nil {
 			logger.Error("listing-images", err, lager.Data{"storePath": cfg.StorePath})
 			return cli.Exit(fmt.Sprintf("Failed to retrieve list of images: %s", err.Error()), 1)
 		}
 
-		if len(images) == 0 {
-			fmt.Println("Store empty")
+		if ctx.Bool("json") {
+			_ = json.NewEncoder(os.Stdout).Encode(images)
+			return nil
 		}
 		for _, image := range images {
-			fmt.Println(image)
+			if image.Error != "" {
+				fmt.Fprintf(os.Stderr, "failed to inspect image %s: %s\n", image.ID, image.Error)
+				continue
+			}
+			fmt.Println(image.Path)
 		}
 
 		return nil
 	},
 }
+
+// printVolumes writes a table of the volumes with their size in bytes,
+// reference count and whether they are marked for collection
+func printVolumes(volumes []groot.VolumeListing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSIZE\tREF COUNT\tMARKED FOR COLLECTION")
+	for _, volume := range volumes {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%t\n", volume.ID, volume.Size, volume.RefCount, volume.MarkedForCollection)
+	}
+	_ = w.Flush()
+}
+
+func parseImageFilter(args []string) (groot.ImageFilter, error) {
+	filter := groot.ImageFilter{}
+
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return groot.ImageFilter{}, errorspkg.Errorf("invalid filter %q: expected key=value", arg)
+		}
+
+		var err error
+		switch key {
+		case "id":
+			filter.IDPrefix = value
+		case "chain":
+			filter.ChainID = value
+		case "label":
+			if err := addLabelFilter(&filter, value); err != nil {
+				return groot.ImageFilter{}, errorspkg.Errorf("invalid filter %q: expected label=<key>[=<value>]", arg)
+			}
+		case "older-than":
+			filter.OlderThan, err = time.ParseDuration(value)
+		case "newer-than":
+			filter.NewerThan, err = time.ParseDuration(value)
+		default:
+			return groot.ImageFilter{}, errorspkg.Errorf("unknown filter %q", key)
+		}
+		if err != nil {
+			return groot.ImageFilter{}, errorspkg.Wrapf(err, "invalid filter %q", arg)
+		}
+	}
+
+	return filter, nil
+}
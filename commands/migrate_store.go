@@ -0,0 +1,90 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/grootfs/store/migrator"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var MigrateStoreCommand = cli.Command{
+	Name:        "migrate-store",
+	Usage:       "migrate-store",
+	Description: "Migrates the store layout to the version this grootfs understands",
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("migrate-store")
+
+		if ctx.NArg() != 0 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("migrate-store-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		if _, err := os.Stat(storePath); os.IsNotExist(err) {
+			err := errorspkg.Errorf("no store found at %s", storePath)
+			logger.Error("store-path-failed", err, nil)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		exclusiveLocksmith := locksmithpkg.NewExclusiveFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, nil, loopback.NewNoopDirectIO())
+		storeMigrator := migrator.NewMigrator(storePath, fsDriver).WithoutMountByDefault(cfg.Create.WithoutMount)
+		fromVersion, toVersion, err := migrateStoreExclusively(logger, storeMigrator, exclusiveLocksmith)
+		if err != nil {
+			logger.Error("migrating-store-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if fromVersion == toVersion {
+			fmt.Printf("store is up to date at version %d\n", toVersion)
+			return nil
+		}
+		fmt.Printf("store migrated from version %d to %d\n", fromVersion, toVersion)
+		return nil
+	},
+}
+
+// migrateStoreExclusively migrates the store holding the store locks
+// exclusively, as nothing else may use the store while its layout changes
+func migrateStoreExclusively(logger lager.Logger, storeMigrator *migrator.Migrator, exclusiveLocksmith groot.Locksmith) (fromVersion, toVersion int, err error) {
+	for _, lockKey := range []string{groot.GCLockKey, groot.GlobalLockKey} {
+		lockFile, lockErr := exclusiveLocksmith.Lock(lockKey)
+		if lockErr != nil {
+			return 0, 0, errorspkg.Wrapf(lockErr, "acquiring the %s lock", lockKey)
+		}
+		defer func() {
+			if unlockErr := exclusiveLocksmith.Unlock(lockFile); unlockErr != nil {
+				logger.Error("release-lock-failed", unlockErr, lager.Data{"lockKey": lockKey})
+				if err == nil {
+					err = unlockErr
+				}
+			}
+		}()
+	}
+
+	return storeMigrator.Migrate(logger)
+}
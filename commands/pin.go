@@ -0,0 +1,221 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/commandrunner/linux_command_runner"
+	"code.cloudfoundry.org/grootfs/base_image_puller"
+	unpackerpkg "code.cloudfoundry.org/grootfs/base_image_puller/unpacker"
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	"code.cloudfoundry.org/grootfs/sandbox"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/namespaced"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/grootfs/store/manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var PinCommand = cli.Command{
+	Name:        "pin",
+	Usage:       "pin [options] <image>",
+	Description: "Pulls the provided image and keeps its layers from being cleaned up",
+
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "insecure-registry",
+			Usage: "Whitelist a private registry",
+		},
+		&cli.StringFlag{
+			Name:  "username",
+			Usage: "Username to authenticate in image registry",
+		},
+		&cli.StringFlag{
+			Name:  "password",
+			Usage: "Password to authenticate in image registry",
+		},
+		&cli.StringSliceFlag{
+			Name:  "uid-mapping",
+			Usage: "UID mapping of the images to pin the layers for, overriding the store ones, e.g.: <Namespace UID>:<Host UID>:<Size>",
+		},
+		&cli.StringSliceFlag{
+			Name:  "gid-mapping",
+			Usage: "GID mapping of the images to pin the layers for, overriding the store ones, e.g.: <Namespace GID>:<Host GID>:<Size>",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude-path",
+			Usage: "Glob of an image path the images to pin the layers for leave out, e.g.: /usr/share/doc",
+		},
+		&cli.BoolFlag{
+			Name:  "with-mount",
+			Usage: "Pin the layers of images created with --with-mount",
+		},
+		&cli.BoolFlag{
+			Name:  "without-mount",
+			Usage: "Pin the layers of images created with --without-mount",
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("pin")
+
+		if ctx.NArg() != 1 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		configBuilder.WithInsecureRegistries(ctx.StringSlice("insecure-registry")).
+			WithExcludePaths(ctx.StringSlice("exclude-path")).
+			WithMount(ctx.IsSet("with-mount"), ctx.IsSet("without-mount"))
+		cfg, err := configBuilder.Build()
+		logger.Debug("pin-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		baseImageURL, err := url.Parse(ctx.Args().First())
+		if err != nil {
+			logger.Error("base-image-url-parsing-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		sharedLocksmith := locksmithpkg.NewSharedFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+		exclusiveLocksmith := locksmithpkg.NewExclusiveFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+		initStoreLocksmith := locksmithpkg.NewExclusiveFileSystem(filepath.Join("/", "var", "run"))
+
+		storeNamespacer := groot.NewStoreNamespacer(storePath)
+		manager := manager.New(storePath, storeNamespacer, fsDriver, fsDriver, fsDriver, initStoreLocksmith)
+		if !manager.IsStoreInitialized(logger) {
+			logger.Error("store-verification-failed", errors.New("store is not initialized"))
+			return cli.Exit("Store path is not initialized. Please run init-store.", 1)
+		}
+
+		storeIDMappings, err := storeNamespacer.Read()
+		if err != nil {
+			logger.Error("reading-namespace-file", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		idMappings, err := imageIDMappings(ctx, storeIDMappings)
+		if err != nil {
+			logger.Error("parsing-command", err)
+			return cli.Exit(err.Error(), 1)
+		}
+		shouldCloneUserNs := hasIDMappings(idMappings) && os.Getuid() != 0
+
+		// the layers pinned are the ones create would use for the same options
+		idMappedMount := hasIDMappings(idMappings) && os.Getuid() == 0 &&
+			!cfg.Create.WithoutMount && fsDriver.SupportsIDMappedMounts(logger)
+		unpackIDMappings := idMappings
+		if idMappedMount {
+			unpackIDMappings = groot.IDMappings{}
+		}
+
+		runner := linux_command_runner.New()
+		idMapper := unpackerpkg.NewIDMapper(cfg.NewuidmapBin, cfg.NewgidmapBin, runner)
+		reexecer := sandbox.NewReexecer(logger, idMapper, idMappings)
+		unpacker := unpackerpkg.NewNSIdMapperUnpacker(storePath, reexecer, shouldCloneUserNs, unpackIDMappings)
+		baseDirHandler := base_image_puller.NewBasedirHandler(reexecer, shouldCloneUserNs)
+		nsFsDriver := namespaced.New(fsDriver, reexecer, shouldCloneUserNs)
+
+		systemContext := createSystemContext(baseImageURL, cfg.Create, ctx.String("username"), ctx.String("password"))
+		fetcher := createFetcher(storePath, baseImageURL, systemContext, cfg.Create)
+		defer func() {
+			if err := fetcher.Close(); err != nil {
+				logger.Error("closing-fetcher", err)
+			}
+		}()
+
+		baseImagePuller := base_image_puller.NewBaseImagePuller(
+			fetcher,
+			unpacker,
+			nsFsDriver,
+			metricsEmitter,
+			exclusiveLocksmith,
+			baseDirHandler,
+		)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+
+		pinner := groot.IamPinner(baseImagePuller, sharedLocksmith, dependencyManager, metricsEmitter)
+		if err := pinner.Pin(logger, groot.PinSpec{
+			BaseImageURL:    baseImageURL,
+			ExcludePaths:    cfg.Create.ExcludePaths,
+			UIDMappings:     idMappings.UIDMappings,
+			GIDMappings:     idMappings.GIDMappings,
+			IDMappedMount:   idMappedMount,
+			StoreIDMappings: storeIDMappings,
+		}); err != nil {
+			logger.Error("pinning-image-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Printf("Image %s pinned\n", baseImageURL.String())
+		return nil
+	},
+}
+
+var UnpinCommand = cli.Command{
+	Name:        "unpin",
+	Usage:       "unpin <image>",
+	Description: "Lets the layers of a pinned image be cleaned up again",
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("unpin")
+
+		if ctx.NArg() != 1 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("unpin-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		baseImageURL, err := url.Parse(ctx.Args().First())
+		if err != nil {
+			logger.Error("base-image-url-parsing-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(cfg.StorePath, storepkg.MetaDirName, "dependencies"),
+		)
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		pinner := groot.IamPinner(nil, nil, dependencyManager, metricsEmitter)
+		if err := pinner.Unpin(logger, baseImageURL); err != nil {
+			logger.Error("unpinning-image-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Printf("Image %s unpinned\n", baseImageURL.String())
+		return nil
+	},
+}
@@ -0,0 +1,116 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/commands/idfinder"
+	"code.cloudfoundry.org/grootfs/groot"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/dependency_manager"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	imageManagerpkg "code.cloudfoundry.org/grootfs/store/image_manager"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var RemountCommand = cli.Command{
+	Name:        "remount",
+	Usage:       "remount [--all | <id|image path>]",
+	Description: "Mounts the rootfs of images again, e.g. after a reboot",
+
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "Remount every image in the store",
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("remount")
+
+		if ctx.Bool("all") == (ctx.NArg() == 1) || ctx.NArg() > 1 {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("remount-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		if os.Getuid() != 0 {
+			err := errorspkg.Errorf("images in store %s can only be remounted by Root user", storePath)
+			logger.Error("remount-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if _, err := os.Stat(storePath); os.IsNotExist(err) {
+			err := errorspkg.Errorf("no store found at %s", storePath)
+			logger.Error("store-path-failed", err, nil)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, nil, loopback.NewNoopDirectIO())
+		imageManager := imageManagerpkg.NewImageManager(fsDriver, storePath)
+		dependencyManager := dependency_manager.NewDependencyManager(
+			filepath.Join(storePath, storepkg.MetaDirName, "dependencies"),
+		)
+		sharedLocksmith := locksmithpkg.NewSharedFileSystem(filepath.Join(storePath, storepkg.LocksDirName))
+		remounter := groot.IamRemounter(imageManager, sharedLocksmith, dependencyManager)
+
+		ids := []string{}
+		if ctx.Bool("all") {
+			imagePaths, err := groot.IamLister(nil, nil, nil).List(logger, storePath)
+			if err != nil {
+				logger.Error("listing-images", err)
+				return cli.Exit(err.Error(), 1)
+			}
+			for _, imagePath := range imagePaths {
+				ids = append(ids, filepath.Base(imagePath))
+			}
+		} else {
+			idOrPath := ctx.Args().First()
+			id, err := idfinder.FindID(storePath, idOrPath)
+			if err != nil {
+				logger.Error("find-id-failed", err, lager.Data{"id": idOrPath, "storePath": storePath})
+				return cli.Exit(err.Error(), 1)
+			}
+			ids = append(ids, id)
+		}
+
+		// an image failing to remount does not stop the others from being
+		// remounted, so that this can run at boot
+		failures := []string{}
+		for _, id := range ids {
+			remounted, err := remounter.Remount(logger, id)
+			if err != nil {
+				logger.Error("remounting-image-failed", err, lager.Data{"id": id})
+				failures = append(failures, fmt.Sprintf("%s: %s", id, err))
+				continue
+			}
+
+			if remounted {
+				fmt.Printf("Image %s remounted\n", id)
+			} else {
+				fmt.Printf("Image %s skipped: already mounted or not mounted by grootfs\n", id)
+			}
+		}
+
+		if len(failures) > 0 {
+			return cli.Exit(fmt.Sprintf("failed to remount %d image(s):\n%s", len(failures), strings.Join(failures, "\n")), 1)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,91 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/commands/idfinder"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/metrics"
+	storepkg "code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/mount"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var ResizeCommand = cli.Command{
+	Name:        "resize",
+	Usage:       "resize --disk-limit-size-bytes <bytes> [options] <id|image path>",
+	Description: "Changes the disk limit of an image",
+
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:  "disk-limit-size-bytes",
+			Usage: "Inclusive disk limit (i.e: includes all layers in the filesystem)",
+		},
+		&cli.BoolFlag{
+			Name:  "exclude-image-from-quota",
+			Usage: "Set disk limit to be exclusive (i.e.: excluding image layers)",
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("resize")
+
+		if ctx.NArg() != 1 {
+			logger.Error("parsing-command", errorspkg.New("id was not specified"))
+			return cli.Exit("id was not specified", 1)
+		}
+
+		if !ctx.IsSet("disk-limit-size-bytes") {
+			logger.Error("parsing-command", errorspkg.New("disk limit was not specified"))
+			return cli.Exit("disk limit was not specified", 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		configBuilder.WithExcludeImageFromQuota(ctx.Bool("exclude-image-from-quota"),
+			ctx.IsSet("exclude-image-from-quota"))
+		cfg, err := configBuilder.Build()
+		logger.Debug("resize-config", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		id, err := idfinder.FindID(storePath, ctx.Args().First())
+		if err != nil {
+			logger.Error("find-id-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		var unmounter overlayxfs.Unmounter = mount.RootfulUnmounter{}
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, unmounter, loopback.NewNoopDirectIO())
+		imageManager := image_manager.NewImageManager(fsDriver, storePath)
+		metricsEmitter := metrics.NewEmitter(logger, cfg.MetronEndpoint)
+
+		storeLocksDir := filepath.Join(storePath, storepkg.LocksDirName)
+		exclusiveLocksmith := locksmithpkg.NewExclusiveFileSystem(storeLocksDir).WithMetrics(metricsEmitter)
+
+		resizer := groot.IamResizer(imageManager, exclusiveLocksmith, metricsEmitter)
+		resizeSpec := groot.ResizeSpec{
+			ID:                        id,
+			DiskLimit:                 ctx.Int64("disk-limit-size-bytes"),
+			ExcludeBaseImageFromQuota: cfg.Create.ExcludeImageFromQuota,
+		}
+		if err := resizer.Resize(logger, resizeSpec); err != nil {
+			logger.Error("resizing-image-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Printf("Image %s resized\n", id)
+		return nil
+	},
+}
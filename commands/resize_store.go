@@ -0,0 +1,77 @@
+package commands // import "code.cloudfoundry.org/grootfs/commands"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
+	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
+	locksmithpkg "code.cloudfoundry.org/grootfs/store/locksmith"
+	"code.cloudfoundry.org/grootfs/store/manager"
+	"code.cloudfoundry.org/lager/v3"
+
+	errorspkg "github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var ResizeStoreCommand = cli.Command{
+	Name:        "resize-store",
+	Usage:       "resize-store --store <path> --store-size-bytes <size>",
+	Description: "Grow the filesystem of a Store Directory",
+
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:  "store-size-bytes",
+			Usage: "New size of the filesystem backing the Store Directory",
+		},
+	},
+
+	Action: func(ctx *cli.Context) error {
+		logger := ctx.App.Metadata["logger"].(lager.Logger)
+		logger = logger.Session("resize-store")
+
+		if ctx.NArg() != 0 || !ctx.IsSet("store-size-bytes") {
+			logger.Error("parsing-command", errorspkg.New("invalid arguments"), lager.Data{"args": ctx.Args()})
+			return cli.Exit(fmt.Sprintf("invalid arguments - usage: %s", ctx.Command.Usage), 1)
+		}
+
+		configBuilder := ctx.App.Metadata["configBuilder"].(*config.Builder)
+		cfg, err := configBuilder.Build()
+		logger.Debug("resize-store", lager.Data{"currentConfig": cfg})
+		if err != nil {
+			logger.Error("config-builder-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		storePath := cfg.StorePath
+		storeSizeBytes := ctx.Int64("store-size-bytes")
+		if storeSizeBytes < manager.MinStoreSizeBytes {
+			err := errorspkg.Errorf("store size must be at least %d bytes", manager.MinStoreSizeBytes)
+			logger.Error("resize-store-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		if os.Getuid() != 0 {
+			err := errorspkg.Errorf("store %s can only be resized by Root user", storePath)
+			logger.Error("resize-store-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fsDriver := overlayxfs.NewDriver(storePath, cfg.TardisBin, nil, loopback.NewNoopDirectIO())
+		namespacer := groot.NewStoreNamespacer(storePath)
+
+		initLocksDir := filepath.Join("/", "var", "run")
+		initStoreLocksmith := locksmithpkg.NewExclusiveFileSystem(initLocksDir)
+
+		manager := manager.New(storePath, namespacer, fsDriver, fsDriver, fsDriver, initStoreLocksmith)
+		if err := manager.ResizeStore(logger, storeSizeBytes); err != nil {
+			logger.Error("resize-store-failed", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
+		return nil
+	},
+}
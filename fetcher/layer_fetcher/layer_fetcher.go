@@ -12,6 +12,7 @@ import (
 	"code.cloudfoundry.org/lager/v3"
 
 	"github.com/containers/image/v5/types"
+	digestpkg "github.com/opencontainers/go-digest"
 	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	errorspkg "github.com/pkg/errors"
 )
@@ -61,9 +62,19 @@ func (f *LayerFetcher) BaseImageInfo(logger lager.Logger) (groot.BaseImageInfo,
 		return groot.BaseImageInfo{}, err
 	}
 
+	// the digest is only recorded in the volumes metadata, so it is not worth
+	// failing for
+	var manifestDigest string
+	if rawManifest, _, err := manifest.Manifest(context.TODO()); err != nil {
+		logger.Error("fetching-raw-manifest-failed", err)
+	} else {
+		manifestDigest = digestpkg.FromBytes(rawManifest).String()
+	}
+
 	return groot.BaseImageInfo{
-		LayerInfos: f.createLayerInfos(logger, manifest, config),
-		Config:     *config,
+		LayerInfos:     f.createLayerInfos(logger, manifest, config),
+		Config:         *config,
+		ManifestDigest: manifestDigest,
 	}, nil
 }
 
@@ -148,6 +148,18 @@ var _ = Describe("LayerFetcher", func() {
 
 			Expect(baseImageInfo.Config).To(Equal(expectedConfig))
 		})
+
+		It("returns the digest of the manifest", func() {
+			fakeManifest := new(layer_fetcherfakes.FakeManifest)
+			fakeManifest.OCIConfigReturns(&specsv1.Image{}, nil)
+			fakeManifest.ManifestReturns([]byte(`{"schemaVersion": 2}`), "application/vnd.oci.image.manifest.v1+json", nil)
+			fakeSource.ManifestReturns(fakeManifest, nil)
+
+			baseImageInfo, err := fetcher.BaseImageInfo(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(baseImageInfo.ManifestDigest).To(Equal(digestpkg.FromString(`{"schemaVersion": 2}`).String()))
+		})
 	})
 
 	Describe("StreamBlob", func() {
@@ -0,0 +1,13 @@
+package ref_fetcher_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRefFetcher(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ref Fetcher Suite")
+}
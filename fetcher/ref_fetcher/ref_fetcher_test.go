@@ -0,0 +1,67 @@
+package ref_fetcher_test
+
+import (
+	"net/url"
+	"os"
+
+	fetcherpkg "code.cloudfoundry.org/grootfs/fetcher/ref_fetcher"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store/ref_manager"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var _ = Describe("Ref Fetcher", func() {
+	var (
+		fetcher    *fetcherpkg.RefFetcher
+		refsPath   string
+		refManager *ref_manager.RefManager
+		logger     lager.Logger
+	)
+
+	BeforeEach(func() {
+		var err error
+		refsPath, err = os.MkdirTemp("", "refs")
+		Expect(err).NotTo(HaveOccurred())
+
+		refManager = ref_manager.NewRefManager(refsPath)
+		logger = lagertest.NewTestLogger("ref-fetcher")
+
+		baseImageURL, err := url.Parse("ref:///my-ref")
+		Expect(err).NotTo(HaveOccurred())
+		fetcher = fetcherpkg.NewRefFetcher(refManager, baseImageURL)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(refsPath)).To(Succeed())
+	})
+
+	Describe("BaseImageInfo", func() {
+		It("returns the base image info saved for the ref", func() {
+			baseImageInfo := groot.BaseImageInfo{
+				LayerInfos: []groot.LayerInfo{{ChainID: "chain-1"}, {ChainID: "chain-2", ParentChainID: "chain-1"}},
+				Config:     specsv1.Image{Author: "Groot"},
+			}
+			Expect(refManager.Save("my-ref", baseImageInfo)).To(Succeed())
+
+			Expect(fetcher.BaseImageInfo(logger)).To(Equal(baseImageInfo))
+		})
+
+		Context("when the ref does not exist", func() {
+			It("returns an error", func() {
+				_, err := fetcher.BaseImageInfo(logger)
+				Expect(err).To(MatchError(ContainSubstring("ref `my-ref` not found")))
+			})
+		})
+	})
+
+	Describe("StreamBlob", func() {
+		It("returns an error, as ref layers can't be downloaded", func() {
+			_, _, err := fetcher.StreamBlob(logger, groot.LayerInfo{ChainID: "chain-1"})
+			Expect(err).To(MatchError("volume `chain-1` of ref `my-ref` is missing from the store"))
+		})
+	})
+})
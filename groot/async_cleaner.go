@@ -4,30 +4,33 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/commands/config"
 	"code.cloudfoundry.org/lager/v3"
 )
 
 type asyncCleaner struct {
-	logFile        string
-	logLevel       string
-	storePath      string
-	metronEndpoint string
-	tardisBin      string
-	newuidmapBin   string
-	newgidmapBin   string
+	logFile               string
+	logLevel              string
+	storePath             string
+	metronEndpoint        string
+	tardisBin             string
+	newuidmapBin          string
+	newgidmapBin          string
+	incompleteGracePeriod time.Duration
 }
 
 func YouAreCleaner(cfg config.Config) Cleaner {
 	return &asyncCleaner{
-		logFile:        cfg.Create.CleanLogFile,
-		logLevel:       cfg.LogLevel,
-		storePath:      cfg.StorePath,
-		metronEndpoint: cfg.MetronEndpoint,
-		tardisBin:      cfg.TardisBin,
-		newuidmapBin:   cfg.NewuidmapBin,
-		newgidmapBin:   cfg.NewgidmapBin,
+		logFile:               cfg.Create.CleanLogFile,
+		logLevel:              cfg.LogLevel,
+		storePath:             cfg.StorePath,
+		metronEndpoint:        cfg.MetronEndpoint,
+		tardisBin:             cfg.TardisBin,
+		newuidmapBin:          cfg.NewuidmapBin,
+		newgidmapBin:          cfg.NewgidmapBin,
+		incompleteGracePeriod: cfg.Clean.IncompleteGracePeriod,
 	}
 }
 
@@ -57,6 +60,9 @@ func (c *asyncCleaner) Clean(logger lager.Logger, cleanThresholdBytes int64) (bo
 	}
 
 	cleanCommandArgs = append(cleanCommandArgs, "clean", "--threshold-bytes", strconv.FormatInt(cleanThresholdBytes, 10))
+	if c.incompleteGracePeriod > 0 {
+		cleanCommandArgs = append(cleanCommandArgs, "--incomplete-grace-period", c.incompleteGracePeriod.String())
+	}
 	cleanCommand := exec.Command(os.Args[0], cleanCommandArgs...)
 	if !useLogFile {
 		cleanCommand.Stderr = os.Stderr
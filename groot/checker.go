@@ -0,0 +1,125 @@
+package groot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+const MetricStoreCheckTime = "StoreCheckTime"
+
+//go:generate counterfeiter . StoreChecker
+
+// StoreChecker finds, and optionally repairs, the inconsistencies left in the
+// store filesystem by interrupted operations
+type StoreChecker interface {
+	CheckStore(logger lager.Logger, repair bool) (FsckReport, error)
+}
+
+// FsckReport lists the inconsistencies found in the store, by class
+type FsckReport struct {
+	Repaired             bool     `json:"repaired"`
+	IncompleteVolumes    []string `json:"incomplete_volumes"`
+	DanglingLinks        []string `json:"dangling_links"`
+	OrphanedProjectIDs   []string `json:"orphaned_project_ids"`
+	OrphanedVolumesMeta  []string `json:"orphaned_volumes_meta"`
+	OrphanedDependencies []string `json:"orphaned_dependencies"`
+	ImagesWithoutInfo    []string `json:"images_without_info"`
+}
+
+// Inconsistencies is the number of inconsistencies in the report
+func (r FsckReport) Inconsistencies() int {
+	return len(r.IncompleteVolumes) + len(r.DanglingLinks) + len(r.OrphanedProjectIDs) +
+		len(r.OrphanedVolumesMeta) + len(r.OrphanedDependencies) + len(r.ImagesWithoutInfo)
+}
+
+type Checker struct {
+	storeChecker      StoreChecker
+	imageManager      ImageManager
+	dependencyManager DependencyManager
+	locksmith         Locksmith
+	metricsEmitter    MetricsEmitter
+}
+
+func IamChecker(
+	storeChecker StoreChecker, imageManager ImageManager,
+	dependencyManager DependencyManager, locksmith Locksmith,
+	metricsEmitter MetricsEmitter) *Checker {
+	return &Checker{
+		storeChecker:      storeChecker,
+		imageManager:      imageManager,
+		dependencyManager: dependencyManager,
+		locksmith:         locksmith,
+		metricsEmitter:    metricsEmitter,
+	}
+}
+
+// Check looks for inconsistencies in the store, and repairs them if asked to.
+// It holds the global lock, so that nothing in progress is mistaken for one.
+func (c *Checker) Check(logger lager.Logger, repair bool) (FsckReport, error) {
+	defer c.metricsEmitter.TryEmitDurationFrom(logger, MetricStoreCheckTime, time.Now())
+
+	logger = logger.Session("groot-checking", lager.Data{"repair": repair})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	lockFile, err := c.locksmith.Lock(GlobalLockKey)
+	if err != nil {
+		return FsckReport{}, err
+	}
+	defer func() {
+		if err := c.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	report, err := c.storeChecker.CheckStore(logger, repair)
+	if err != nil {
+		return FsckReport{}, errorspkg.Wrap(err, "checking store")
+	}
+
+	// images removed by the store check leave their dependencies behind, so
+	// these are checked last
+	report.OrphanedDependencies, err = c.orphanedDependencies(logger, repair)
+	if err != nil {
+		return FsckReport{}, err
+	}
+	report.Repaired = repair
+
+	return report, nil
+}
+
+func (c *Checker) orphanedDependencies(logger lager.Logger, repair bool) ([]string, error) {
+	registeredIDs, err := c.dependencyManager.RegisteredIDs()
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing dependencies")
+	}
+
+	imagePrefix := fmt.Sprintf(ImageReferenceFormat, "")
+	orphanedDependencies := []string{}
+	for _, registeredID := range registeredIDs {
+		if !strings.HasPrefix(registeredID, imagePrefix) {
+			continue
+		}
+
+		exists, err := c.imageManager.Exists(strings.TrimPrefix(registeredID, imagePrefix))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		orphanedDependencies = append(orphanedDependencies, registeredID)
+		if repair {
+			if err := c.dependencyManager.Deregister(registeredID); err != nil {
+				return nil, errorspkg.Wrapf(err, "deregistering dependencies of `%s`", registeredID)
+			}
+		}
+	}
+
+	return orphanedDependencies, nil
+}
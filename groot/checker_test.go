@@ -0,0 +1,133 @@
+package groot_test
+
+import (
+	"errors"
+	"os"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		fakeStoreChecker      *grootfakes.FakeStoreChecker
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeLocksmith         *grootfakes.FakeLocksmith
+		fakeMetricsEmitter    *grootfakes.FakeMetricsEmitter
+		checker               *groot.Checker
+		logger                lager.Logger
+		repair                bool
+		report                groot.FsckReport
+		checkErr              error
+	)
+
+	BeforeEach(func() {
+		fakeStoreChecker = new(grootfakes.FakeStoreChecker)
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeLocksmith = new(grootfakes.FakeLocksmith)
+		fakeMetricsEmitter = new(grootfakes.FakeMetricsEmitter)
+
+		fakeLocksmith.LockReturns(&os.File{}, nil)
+		fakeStoreChecker.CheckStoreReturns(groot.FsckReport{
+			IncompleteVolumes: []string{"chain-1-incomplete-123"},
+		}, nil)
+		fakeDependencyManager.RegisteredIDsReturns([]string{"image:existing", "image:gone", "ref:my-ref"}, nil)
+		fakeImageManager.ExistsStub = func(id string) (bool, error) {
+			return id == "existing", nil
+		}
+
+		checker = groot.IamChecker(fakeStoreChecker, fakeImageManager, fakeDependencyManager, fakeLocksmith, fakeMetricsEmitter)
+		logger = lagertest.NewTestLogger("checker")
+		repair = false
+	})
+
+	JustBeforeEach(func() {
+		report, checkErr = checker.Check(logger, repair)
+	})
+
+	It("reports the store inconsistencies", func() {
+		Expect(checkErr).NotTo(HaveOccurred())
+		Expect(report.IncompleteVolumes).To(ConsistOf("chain-1-incomplete-123"))
+		Expect(report.Repaired).To(BeFalse())
+
+		Expect(fakeStoreChecker.CheckStoreCallCount()).To(Equal(1))
+		_, storeRepair := fakeStoreChecker.CheckStoreArgsForCall(0)
+		Expect(storeRepair).To(BeFalse())
+	})
+
+	It("reports the dependencies of images that are gone", func() {
+		Expect(checkErr).NotTo(HaveOccurred())
+		Expect(report.OrphanedDependencies).To(ConsistOf("image:gone"))
+		Expect(report.Inconsistencies()).To(Equal(2))
+		Expect(fakeDependencyManager.DeregisterCallCount()).To(BeZero())
+	})
+
+	It("holds the global lock while checking", func() {
+		Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+		Expect(fakeLocksmith.LockArgsForCall(0)).To(Equal(groot.GlobalLockKey))
+		Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+	})
+
+	It("emits the check duration", func() {
+		Expect(fakeMetricsEmitter.TryEmitDurationFromCallCount()).To(Equal(1))
+		_, name, _ := fakeMetricsEmitter.TryEmitDurationFromArgsForCall(0)
+		Expect(name).To(Equal(groot.MetricStoreCheckTime))
+	})
+
+	Context("when repairing", func() {
+		BeforeEach(func() {
+			repair = true
+		})
+
+		It("repairs the store", func() {
+			Expect(checkErr).NotTo(HaveOccurred())
+			Expect(report.Repaired).To(BeTrue())
+			_, storeRepair := fakeStoreChecker.CheckStoreArgsForCall(0)
+			Expect(storeRepair).To(BeTrue())
+		})
+
+		It("deregisters the orphaned dependencies", func() {
+			Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(1))
+			Expect(fakeDependencyManager.DeregisterArgsForCall(0)).To(Equal("image:gone"))
+		})
+
+		Context("when deregistering fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.DeregisterReturns(errors.New("read-only"))
+			})
+
+			It("returns an error", func() {
+				Expect(checkErr).To(MatchError(ContainSubstring("read-only")))
+			})
+		})
+	})
+
+	Context("when checking the store fails", func() {
+		BeforeEach(func() {
+			fakeStoreChecker.CheckStoreReturns(groot.FsckReport{}, errors.New("no images dir"))
+		})
+
+		It("returns an error", func() {
+			Expect(checkErr).To(MatchError(ContainSubstring("no images dir")))
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when acquiring the lock fails", func() {
+		BeforeEach(func() {
+			fakeLocksmith.LockReturns(nil, errors.New("locked"))
+		})
+
+		It("does not check anything", func() {
+			Expect(checkErr).To(MatchError("locked"))
+			Expect(fakeStoreChecker.CheckStoreCallCount()).To(BeZero())
+		})
+	})
+})
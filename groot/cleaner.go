@@ -8,31 +8,65 @@ import (
 	errorspkg "github.com/pkg/errors"
 )
 
+// IncompleteVolumeGracePeriod is how long an incomplete volume is left to
+// its creation before clean reclaims it, unless configured otherwise
+const IncompleteVolumeGracePeriod = time.Hour
+
 //go:generate counterfeiter . Cleaner
 type Cleaner interface {
 	Clean(logger lager.Logger, cacheSize int64) (bool, error)
 }
 
 type cleaner struct {
-	storeMeasurer    StoreMeasurer
-	garbageCollector GarbageCollector
-	locksmith        Locksmith
-	metricsEmitter   MetricsEmitter
-	getLockTimeout   time.Duration
-	cleaningTimeout  time.Duration
+	storeMeasurer         StoreMeasurer
+	garbageCollector      GarbageCollector
+	locksmith             Locksmith
+	metricsEmitter        MetricsEmitter
+	getLockTimeout        time.Duration
+	cleaningTimeout       time.Duration
+	incompleteGracePeriod time.Duration
 }
 
 func IamCleaner(locksmith Locksmith, sm StoreMeasurer,
 	gc GarbageCollector, metricsEmitter MetricsEmitter,
 	getLockTimeout time.Duration, cleaningTimeout time.Duration) *cleaner {
 	return &cleaner{
-		locksmith:        locksmith,
-		storeMeasurer:    sm,
-		garbageCollector: gc,
-		metricsEmitter:   metricsEmitter,
-		getLockTimeout:   getLockTimeout,
-		cleaningTimeout:  cleaningTimeout,
+		locksmith:             locksmith,
+		storeMeasurer:         sm,
+		garbageCollector:      gc,
+		metricsEmitter:        metricsEmitter,
+		getLockTimeout:        getLockTimeout,
+		cleaningTimeout:       cleaningTimeout,
+		incompleteGracePeriod: IncompleteVolumeGracePeriod,
+	}
+}
+
+// WithIncompleteGracePeriod overrides how long incomplete volumes are left to
+// their creation before being reclaimed. 0 keeps the default.
+func (c *cleaner) WithIncompleteGracePeriod(gracePeriod time.Duration) *cleaner {
+	if gracePeriod > 0 {
+		c.incompleteGracePeriod = gracePeriod
 	}
+	return c
+}
+
+// CleanReport describes what a clean would collect
+type CleanReport struct {
+	Noop                bool             `json:"noop"`
+	Volumes             []CleanCandidate `json:"volumes"`
+	ProjectedBytesFreed int64            `json:"projected_bytes_freed"`
+}
+
+type CleanCandidate struct {
+	ID       string    `json:"id"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+	// ImageIDs are the images that last used the volume, most recent first
+	ImageIDs    []string `json:"image_ids,omitempty"`
+	SourceImage string   `json:"source_image,omitempty"`
+	// Incomplete volumes are left over by crashed creations, and are
+	// reclaimed whatever the store usage
+	Incomplete bool `json:"incomplete,omitempty"`
 }
 
 type CleaningTimeoutError struct {
@@ -45,16 +79,22 @@ func (e CleaningTimeoutError) Error() string {
 }
 
 func (c *cleaner) Clean(logger lager.Logger, threshold int64) (bool, error) {
+	return c.CleanToTarget(logger, threshold, 0)
+}
+
+// CleanToTarget collects unused volumes, least recently used first, until the
+// store usage is below the target. A target of 0 collects all of them.
+func (c *cleaner) CleanToTarget(logger lager.Logger, threshold, target int64) (bool, error) {
 	logger = logger.Session("groot-cleaning")
 	cleaningTimeoutForLogs := fmt.Sprintf("%vs", c.cleaningTimeout.Seconds())
-	logger.Info("starting", lager.Data{"cleaning_timeout": cleaningTimeoutForLogs})
+	logger.Info("starting", lager.Data{"cleaning_timeout": cleaningTimeoutForLogs, "target": target})
 
 	finishedCleaning := make(chan string, 1)
 	var noop bool
 	var err error
 
 	go func() {
-		noop, err = c.run(logger, threshold)
+		noop, err = c.run(logger, threshold, target)
 		finishedCleaning <- "all done cleaning!"
 	}()
 
@@ -66,41 +106,196 @@ func (c *cleaner) Clean(logger lager.Logger, threshold int64) (bool, error) {
 	}
 }
 
-func (c *cleaner) run(logger lager.Logger, threshold int64) (bool, error) {
+func (c *cleaner) run(logger lager.Logger, threshold, target int64) (bool, error) {
 	defer c.metricsEmitter.TryEmitDurationFrom(logger, MetricImageCleanTime, time.Now())
 	defer logger.Info("ending")
 
-	if threshold > 0 {
-		committedQuota, err := c.storeMeasurer.CommittedQuota(logger)
-		if err != nil {
-			return false, errorspkg.Wrap(err, "failed to calculate committed quota")
+	if err := validateLimits(threshold, target); err != nil {
+		return true, err
+	}
+
+	// leftovers of crashed creations are reclaimed whatever the store usage
+	c.reclaimIncompleteVolumes(logger)
+
+	noop, bytesToFree, err := c.bytesToFree(logger, threshold, target)
+	if err != nil || noop {
+		return noop, err
+	}
+
+	return false, c.collectGarbage(logger, bytesToFree)
+}
+
+func (c *cleaner) reclaimIncompleteVolumes(logger lager.Logger) {
+	// the store is only locked when there is something to reclaim
+	staleVolumes, err := c.garbageCollector.StaleIncompleteVolumes(logger, c.incompleteGracePeriod)
+	if err != nil {
+		logger.Error("listing-incomplete-failed", err)
+		return
+	}
+	if len(staleVolumes) == 0 {
+		return
+	}
+
+	lockFile, err := c.locksmith.LockWithTimeout(GlobalLockKey, c.getLockTimeout)
+	if err != nil {
+		logger.Error("reclaiming-incomplete-acquiring-lock-failed", err)
+		return
+	}
+	defer func() {
+		if err := c.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("unlocking-failed", err)
 		}
-		logger.Debug(fmt.Sprintf("commitedQuota in bytes is: %d", committedQuota))
+	}()
+
+	reclaimedBytes, err := c.garbageCollector.ReclaimIncompleteVolumes(logger, c.incompleteGracePeriod)
+	if err != nil {
+		logger.Error("reclaiming-incomplete-failed", err)
+	}
+	c.metricsEmitter.TryEmitUsage(logger, MetricReclaimedIncompleteSize, reclaimedBytes, "bytes")
+}
+
+// DryRun reports the volumes CleanToTarget would collect, without marking
+// them, along with the stale incomplete volumes it would reclaim
+func (c *cleaner) DryRun(logger lager.Logger, threshold, target int64) (CleanReport, error) {
+	logger = logger.Session("groot-cleaning-dry-run", lager.Data{"threshold": threshold, "target": target})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	noop, bytesToFree, err := c.bytesToFree(logger, threshold, target)
+	if err != nil {
+		return CleanReport{}, err
+	}
+	report := CleanReport{Noop: noop, Volumes: []CleanCandidate{}}
 
-		totalVolumesSize, err := c.storeMeasurer.TotalVolumesSize(logger)
+	staleVolumes, err := c.garbageCollector.StaleIncompleteVolumes(logger, c.incompleteGracePeriod)
+	if err != nil {
+		return CleanReport{}, errorspkg.Wrap(err, "listing incomplete volumes")
+	}
+	if len(staleVolumes) > 0 {
+		staleCandidates, err := c.garbageCollector.CleanCandidates(logger, staleVolumes)
 		if err != nil {
-			return false, errorspkg.Wrap(err, "failed to calculate total volumes size")
+			return CleanReport{}, errorspkg.Wrap(err, "describing incomplete volumes")
+		}
+		for _, candidate := range staleCandidates {
+			candidate.Incomplete = true
+			report.Volumes = append(report.Volumes, candidate)
+			report.ProjectedBytesFreed += candidate.Size
 		}
-		logger.Debug(fmt.Sprintf("totalVolumesSize in bytes is: %d", totalVolumesSize))
-		logger.Debug(fmt.Sprintf("threshold in bytes is: %d", threshold))
+	}
 
-		if (committedQuota + totalVolumesSize) < threshold {
-			return true, nil
+	if noop {
+		return report, nil
+	}
+
+	lockFile, err := c.locksmith.LockWithTimeout(GlobalLockKey, c.getLockTimeout)
+	if err != nil {
+		return CleanReport{}, errorspkg.Wrap(err, "garbage collector acquiring lock")
+	}
+	defer func() {
+		if err := c.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("unlocking-failed", err)
 		}
-	} else if threshold < 0 {
-		return true, errorspkg.New("Threshold must be greater than 0")
+	}()
+
+	volumeIDs, err := c.volumesToCollect(logger, bytesToFree)
+	if err != nil {
+		return CleanReport{}, err
+	}
+
+	candidates, err := c.garbageCollector.CleanCandidates(logger, volumeIDs)
+	if err != nil {
+		return CleanReport{}, errorspkg.Wrap(err, "describing unused volumes")
+	}
+
+	for _, candidate := range candidates {
+		report.Volumes = append(report.Volumes, candidate)
+		report.ProjectedBytesFreed += candidate.Size
+	}
+
+	return report, nil
+}
+
+func validateLimits(threshold, target int64) error {
+	if threshold < 0 {
+		return errorspkg.New("Threshold must be greater than 0")
+	}
+	if target < 0 {
+		return errorspkg.New("Target must be greater than 0")
+	}
+	return nil
+}
+
+// bytesToFree measures the store against the threshold and target. 0 bytes
+// means that every unused volume is to be collected.
+func (c *cleaner) bytesToFree(logger lager.Logger, threshold, target int64) (bool, int64, error) {
+	if err := validateLimits(threshold, target); err != nil {
+		return true, 0, err
+	}
+
+	if threshold == 0 && target == 0 {
+		return false, 0, nil
+	}
+
+	storeUsage, err := c.storeUsage(logger)
+	if err != nil {
+		return false, 0, err
+	}
+	logger.Debug(fmt.Sprintf("threshold in bytes is: %d", threshold))
+	logger.Debug(fmt.Sprintf("target in bytes is: %d", target))
+
+	if storeUsage < threshold || storeUsage < target {
+		return true, 0, nil
+	}
+
+	if target > 0 {
+		return false, storeUsage - target + 1, nil
+	}
+	return false, 0, nil
+}
+
+func (c *cleaner) storeUsage(logger lager.Logger) (int64, error) {
+	committedQuota, err := c.storeMeasurer.CommittedQuota(logger)
+	if err != nil {
+		return 0, errorspkg.Wrap(err, "failed to calculate committed quota")
+	}
+	logger.Debug(fmt.Sprintf("commitedQuota in bytes is: %d", committedQuota))
+
+	totalVolumesSize, err := c.storeMeasurer.TotalVolumesSize(logger)
+	if err != nil {
+		return 0, errorspkg.Wrap(err, "failed to calculate total volumes size")
+	}
+	logger.Debug(fmt.Sprintf("totalVolumesSize in bytes is: %d", totalVolumesSize))
+
+	return committedQuota + totalVolumesSize, nil
+}
+
+// volumesToCollect returns the least recently used unused volumes adding up
+// to bytesToFree, or all of them when bytesToFree is 0
+func (c *cleaner) volumesToCollect(logger lager.Logger, bytesToFree int64) ([]string, error) {
+	unusedVolumes, err := c.garbageCollector.UnusedVolumes(logger)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "finding unused volumes")
 	}
 
-	return false, c.collectGarbage(logger)
+	if bytesToFree == 0 {
+		return unusedVolumes, nil
+	}
+
+	unusedVolumes, err = c.garbageCollector.LeastRecentlyUsed(logger, unusedVolumes, bytesToFree)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "sorting unused volumes")
+	}
+
+	return unusedVolumes, nil
 }
 
-func (c *cleaner) collectGarbage(logger lager.Logger) error {
+func (c *cleaner) collectGarbage(logger lager.Logger, bytesToFree int64) error {
 	lockFile, err := c.locksmith.LockWithTimeout(GlobalLockKey, c.getLockTimeout)
 	if err != nil {
 		return errorspkg.Wrap(err, "garbage collector acquiring lock")
 	}
 
-	unusedVolumes, err := c.garbageCollector.UnusedVolumes(logger)
+	unusedVolumes, err := c.volumesToCollect(logger, bytesToFree)
 	if err != nil {
 		logger.Error("finding-unused-failed", err)
 	}
@@ -263,5 +263,313 @@ var _ = Describe("Cleaner", func() {
 			})
 
 		})
+		Context("when there are stale incomplete volumes", func() {
+			BeforeEach(func() {
+				fakeGarbageCollector.StaleIncompleteVolumesReturns([]string{"layer-incomplete-1234"}, nil)
+				fakeGarbageCollector.ReclaimIncompleteVolumesReturns(2048, nil)
+				fakeStoreMeasurer.TotalVolumesSizeReturns(10, nil)
+			})
+
+			It("reclaims them under the global lock, with the grace period", func() {
+				_, err := cleaner.Clean(logger, 1000)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGarbageCollector.ReclaimIncompleteVolumesCallCount()).To(Equal(1))
+				_, gracePeriod := fakeGarbageCollector.ReclaimIncompleteVolumesArgsForCall(0)
+				Expect(gracePeriod).To(Equal(groot.IncompleteVolumeGracePeriod))
+
+				Expect(fakeLocksmith.LockWithTimeoutCallCount()).To(Equal(1))
+				key, _ := fakeLocksmith.LockWithTimeoutArgsForCall(0)
+				Expect(key).To(Equal(groot.GlobalLockKey))
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+			})
+
+			Context("when the grace period is configured", func() {
+				BeforeEach(func() {
+					cleaner = groot.IamCleaner(fakeLocksmith, fakeStoreMeasurer,
+						fakeGarbageCollector, fakeMetricsEmitter, getLockTimeout, cleaningTimeout,
+					).WithIncompleteGracePeriod(6 * time.Hour)
+				})
+
+				It("uses it", func() {
+					_, err := cleaner.Clean(logger, 1000)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, staleGracePeriod := fakeGarbageCollector.StaleIncompleteVolumesArgsForCall(0)
+					Expect(staleGracePeriod).To(Equal(6 * time.Hour))
+					_, reclaimGracePeriod := fakeGarbageCollector.ReclaimIncompleteVolumesArgsForCall(0)
+					Expect(reclaimGracePeriod).To(Equal(6 * time.Hour))
+				})
+			})
+
+			It("emits the reclaimed size", func() {
+				_, err := cleaner.Clean(logger, 1000)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMetricsEmitter.TryEmitUsageCallCount()).To(Equal(1))
+				_, name, usage, units := fakeMetricsEmitter.TryEmitUsageArgsForCall(0)
+				Expect(name).To(Equal(groot.MetricReclaimedIncompleteSize))
+				Expect(usage).To(BeEquivalentTo(2048))
+				Expect(units).To(Equal("bytes"))
+			})
+
+			Context("when reclaiming fails", func() {
+				BeforeEach(func() {
+					fakeGarbageCollector.ReclaimIncompleteVolumesReturns(0, errors.New("busy"))
+				})
+
+				It("still cleans", func() {
+					_, err := cleaner.Clean(logger, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeGarbageCollector.CollectCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when there are no stale incomplete volumes", func() {
+			It("does not reclaim anything", func() {
+				_, err := cleaner.Clean(logger, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeGarbageCollector.ReclaimIncompleteVolumesCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("CleanToTarget", func() {
+		var (
+			threshold int64
+			target    int64
+			noop      bool
+			cleanErr  error
+		)
+
+		BeforeEach(func() {
+			threshold = 0
+			target = 1000
+			fakeStoreMeasurer.TotalVolumesSizeReturns(1400, nil)
+			fakeStoreMeasurer.CommittedQuotaReturns(100, nil)
+			fakeGarbageCollector.UnusedVolumesReturns([]string{"vol-a", "vol-b", "vol-c"}, nil)
+			fakeGarbageCollector.LeastRecentlyUsedReturns([]string{"vol-b", "vol-a"}, nil)
+		})
+
+		JustBeforeEach(func() {
+			targetCleaner := groot.IamCleaner(fakeLocksmith, fakeStoreMeasurer,
+				fakeGarbageCollector, fakeMetricsEmitter, getLockTimeout, cleaningTimeout)
+			noop, cleanErr = targetCleaner.CleanToTarget(logger, threshold, target)
+		})
+
+		It("marks the least recently used volumes needed to go below the target", func() {
+			Expect(cleanErr).NotTo(HaveOccurred())
+			Expect(noop).To(BeFalse())
+
+			Expect(fakeGarbageCollector.LeastRecentlyUsedCallCount()).To(Equal(1))
+			_, volumeIDs, bytes := fakeGarbageCollector.LeastRecentlyUsedArgsForCall(0)
+			Expect(volumeIDs).To(Equal([]string{"vol-a", "vol-b", "vol-c"}))
+			Expect(bytes).To(BeEquivalentTo(501))
+
+			Expect(fakeGarbageCollector.MarkUnusedCallCount()).To(Equal(1))
+			_, markedVolumes := fakeGarbageCollector.MarkUnusedArgsForCall(0)
+			Expect(markedVolumes).To(Equal([]string{"vol-b", "vol-a"}))
+			Expect(fakeGarbageCollector.CollectCallCount()).To(Equal(1))
+		})
+
+		Context("when the store is already below the target", func() {
+			BeforeEach(func() {
+				target = 2000
+			})
+
+			It("does not remove anything", func() {
+				Expect(cleanErr).NotTo(HaveOccurred())
+				Expect(noop).To(BeTrue())
+				Expect(fakeGarbageCollector.MarkUnusedCallCount()).To(Equal(0))
+				Expect(fakeGarbageCollector.CollectCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the store is below the threshold", func() {
+			BeforeEach(func() {
+				threshold = 2000
+			})
+
+			It("does not remove anything", func() {
+				Expect(cleanErr).NotTo(HaveOccurred())
+				Expect(noop).To(BeTrue())
+				Expect(fakeGarbageCollector.CollectCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the target is 0", func() {
+			BeforeEach(func() {
+				target = 0
+			})
+
+			It("marks all the unused volumes", func() {
+				Expect(cleanErr).NotTo(HaveOccurred())
+				Expect(fakeGarbageCollector.LeastRecentlyUsedCallCount()).To(Equal(0))
+				_, markedVolumes := fakeGarbageCollector.MarkUnusedArgsForCall(0)
+				Expect(markedVolumes).To(Equal([]string{"vol-a", "vol-b", "vol-c"}))
+			})
+		})
+
+		Context("when the target is negative", func() {
+			BeforeEach(func() {
+				target = -1
+			})
+
+			It("indicates a no-op and returns an error", func() {
+				Expect(noop).To(BeTrue())
+				Expect(cleanErr).To(MatchError("Target must be greater than 0"))
+			})
+		})
+
+		Context("when sorting the unused volumes fails", func() {
+			BeforeEach(func() {
+				fakeGarbageCollector.LeastRecentlyUsedReturns(nil, errors.New("no meta"))
+			})
+
+			It("does not mark any volume", func() {
+				Expect(cleanErr).NotTo(HaveOccurred())
+				_, markedVolumes := fakeGarbageCollector.MarkUnusedArgsForCall(0)
+				Expect(markedVolumes).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("DryRun", func() {
+		var (
+			threshold int64
+			target    int64
+			report    groot.CleanReport
+			dryRunErr error
+		)
+
+		BeforeEach(func() {
+			threshold = 0
+			target = 0
+			fakeStoreMeasurer.TotalVolumesSizeReturns(1400, nil)
+			fakeStoreMeasurer.CommittedQuotaReturns(100, nil)
+			fakeGarbageCollector.UnusedVolumesReturns([]string{"vol-a", "vol-b"}, nil)
+			fakeGarbageCollector.CleanCandidatesReturns([]groot.CleanCandidate{
+				{ID: "vol-a", Size: 300, ImageIDs: []string{"image-1"}},
+				{ID: "vol-b", Size: 200, SourceImage: "docker:///cfgarden/empty"},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			dryRunCleaner := groot.IamCleaner(fakeLocksmith, fakeStoreMeasurer,
+				fakeGarbageCollector, fakeMetricsEmitter, getLockTimeout, cleaningTimeout)
+			report, dryRunErr = dryRunCleaner.DryRun(logger, threshold, target)
+		})
+
+		It("reports the unused volumes and the bytes they would free", func() {
+			Expect(dryRunErr).NotTo(HaveOccurred())
+			Expect(report.Noop).To(BeFalse())
+			Expect(report.Volumes).To(HaveLen(2))
+			Expect(report.Volumes[0].ImageIDs).To(Equal([]string{"image-1"}))
+			Expect(report.ProjectedBytesFreed).To(BeEquivalentTo(500))
+
+			_, volumeIDs := fakeGarbageCollector.CleanCandidatesArgsForCall(0)
+			Expect(volumeIDs).To(Equal([]string{"vol-a", "vol-b"}))
+		})
+
+		It("does not mark or collect anything", func() {
+			Expect(fakeGarbageCollector.MarkUnusedCallCount()).To(Equal(0))
+			Expect(fakeGarbageCollector.CollectCallCount()).To(Equal(0))
+		})
+
+		It("holds the global lock", func() {
+			Expect(fakeLocksmith.LockWithTimeoutCallCount()).To(Equal(1))
+			key, _ := fakeLocksmith.LockWithTimeoutArgsForCall(0)
+			Expect(key).To(Equal(groot.GlobalLockKey))
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when a target is provided", func() {
+			BeforeEach(func() {
+				target = 1000
+				fakeGarbageCollector.LeastRecentlyUsedReturns([]string{"vol-b"}, nil)
+			})
+
+			It("reports the least recently used volumes only", func() {
+				Expect(dryRunErr).NotTo(HaveOccurred())
+				_, volumeIDs := fakeGarbageCollector.CleanCandidatesArgsForCall(0)
+				Expect(volumeIDs).To(Equal([]string{"vol-b"}))
+			})
+		})
+
+		Context("when the threshold is not reached", func() {
+			BeforeEach(func() {
+				threshold = 2000
+			})
+
+			It("reports a no-op", func() {
+				Expect(dryRunErr).NotTo(HaveOccurred())
+				Expect(report.Noop).To(BeTrue())
+				Expect(report.Volumes).To(BeEmpty())
+				Expect(fakeLocksmith.LockWithTimeoutCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when there are stale incomplete volumes", func() {
+			BeforeEach(func() {
+				fakeGarbageCollector.StaleIncompleteVolumesReturns([]string{"vol-c-incomplete-1"}, nil)
+				fakeGarbageCollector.CleanCandidatesStub = func(_ lager.Logger, volumeIDs []string) ([]groot.CleanCandidate, error) {
+					candidates := []groot.CleanCandidate{}
+					for _, volumeID := range volumeIDs {
+						candidates = append(candidates, groot.CleanCandidate{ID: volumeID, Size: 100})
+					}
+					return candidates, nil
+				}
+			})
+
+			It("reports them along with the unused volumes", func() {
+				Expect(dryRunErr).NotTo(HaveOccurred())
+				Expect(report.Volumes).To(HaveLen(3))
+				Expect(report.Volumes[0].ID).To(Equal("vol-c-incomplete-1"))
+				Expect(report.Volumes[0].Incomplete).To(BeTrue())
+				Expect(report.Volumes[1].Incomplete).To(BeFalse())
+				Expect(report.ProjectedBytesFreed).To(BeEquivalentTo(300))
+			})
+
+			It("uses the incomplete grace period", func() {
+				_, gracePeriod := fakeGarbageCollector.StaleIncompleteVolumesArgsForCall(0)
+				Expect(gracePeriod).To(Equal(groot.IncompleteVolumeGracePeriod))
+			})
+
+			Context("when the threshold is not reached", func() {
+				BeforeEach(func() {
+					threshold = 2000
+				})
+
+				It("still reports them, as clean reclaims them anyway", func() {
+					Expect(dryRunErr).NotTo(HaveOccurred())
+					Expect(report.Noop).To(BeTrue())
+					Expect(report.Volumes).To(HaveLen(1))
+					Expect(report.Volumes[0].ID).To(Equal("vol-c-incomplete-1"))
+					Expect(report.ProjectedBytesFreed).To(BeEquivalentTo(100))
+				})
+			})
+
+			Context("when listing them fails", func() {
+				BeforeEach(func() {
+					fakeGarbageCollector.StaleIncompleteVolumesReturns(nil, errors.New("no volumes"))
+				})
+
+				It("returns an error", func() {
+					Expect(dryRunErr).To(MatchError(ContainSubstring("no volumes")))
+				})
+			})
+		})
+
+		Context("when finding the unused volumes fails", func() {
+			BeforeEach(func() {
+				fakeGarbageCollector.UnusedVolumesReturns(nil, errors.New("no deps"))
+			})
+
+			It("returns an error", func() {
+				Expect(dryRunErr).To(MatchError(ContainSubstring("no deps")))
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+			})
+		})
 	})
 })
@@ -0,0 +1,106 @@
+package groot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+const MetricImageCloneTime = "ImageCloneTime"
+
+type CloneSpec struct {
+	SourceID                  string
+	ID                        string
+	DiskLimit                 int64
+	Mount                     bool
+	ExcludeBaseImageFromQuota bool
+}
+
+type Cloner struct {
+	imageManager      ImageManager
+	locksmith         Locksmith
+	dependencyManager DependencyManager
+	metricsEmitter    MetricsEmitter
+}
+
+func IamCloner(
+	imageManager ImageManager, locksmith Locksmith,
+	dependencyManager DependencyManager, metricsEmitter MetricsEmitter) *Cloner {
+	return &Cloner{
+		imageManager:      imageManager,
+		locksmith:         locksmith,
+		dependencyManager: dependencyManager,
+		metricsEmitter:    metricsEmitter,
+	}
+}
+
+// Clone creates a new image on the base volumes of the source image, with a
+// copy of the changes made to it. The clone depends on the same volumes as
+// the source image, so they are kept around until both images are deleted.
+func (c *Cloner) Clone(logger lager.Logger, spec CloneSpec) (ImageInfo, error) {
+	defer c.metricsEmitter.TryEmitDurationFrom(logger, MetricImageCloneTime, time.Now())
+
+	logger = logger.Session("groot-cloning", lager.Data{"spec": spec})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if strings.ContainsAny(spec.ID, "/") {
+		return ImageInfo{}, errorspkg.Errorf("id `%s` contains invalid characters: `/`", spec.ID)
+	}
+
+	ok, err := c.imageManager.Exists(spec.ID)
+	if err != nil {
+		return ImageInfo{}, errorspkg.Wrap(err, "checking id exists")
+	}
+	if ok {
+		return ImageInfo{}, errorspkg.Errorf("image for id `%s` already exists", spec.ID)
+	}
+
+	lockFile, err := c.locksmith.Lock(GlobalLockKey)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	defer func() {
+		if err := c.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	ok, err = c.imageManager.Exists(spec.SourceID)
+	if err != nil {
+		return ImageInfo{}, errorspkg.Wrap(err, "checking source id exists")
+	}
+	if !ok {
+		return ImageInfo{}, errorspkg.Errorf("source image `%s` not found", spec.SourceID)
+	}
+
+	baseChainIDs, err := c.dependencyManager.Dependencies(fmt.Sprintf(ImageReferenceFormat, spec.SourceID))
+	if err != nil {
+		return ImageInfo{}, errorspkg.Wrap(err, "fetching source image dependencies")
+	}
+
+	image, err := c.imageManager.Clone(logger, spec.SourceID, ImageSpec{
+		ID:                        spec.ID,
+		Mount:                     spec.Mount,
+		DiskLimit:                 spec.DiskLimit,
+		ExcludeBaseImageFromQuota: spec.ExcludeBaseImageFromQuota,
+		BaseVolumeIDs:             baseChainIDs,
+	})
+	if err != nil {
+		return ImageInfo{}, errorspkg.Wrap(err, "cloning image")
+	}
+
+	imageRefName := fmt.Sprintf(ImageReferenceFormat, spec.ID)
+	if err := c.dependencyManager.Register(imageRefName, baseChainIDs); err != nil {
+		if destroyErr := c.imageManager.Destroy(logger, spec.ID); destroyErr != nil {
+			logger.Error("failed-to-destroy-image", destroyErr)
+		}
+
+		return ImageInfo{}, err
+	}
+
+	return image, nil
+}
@@ -0,0 +1,167 @@
+package groot_test
+
+import (
+	"errors"
+	"os"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cloner", func() {
+	var (
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeLocksmith         *grootfakes.FakeLocksmith
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeMetricsEmitter    *grootfakes.FakeMetricsEmitter
+		lockFile              *os.File
+		cloner                *groot.Cloner
+		logger                lager.Logger
+		spec                  groot.CloneSpec
+	)
+
+	BeforeEach(func() {
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeLocksmith = new(grootfakes.FakeLocksmith)
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeMetricsEmitter = new(grootfakes.FakeMetricsEmitter)
+
+		lockFile = &os.File{}
+		fakeLocksmith.LockReturns(lockFile, nil)
+		fakeImageManager.ExistsStub = func(id string) (bool, error) {
+			return id == "src-id", nil
+		}
+		fakeDependencyManager.DependenciesReturns([]string{"chain-1", "chain-2"}, nil)
+		fakeImageManager.CloneReturns(groot.ImageInfo{Path: "/store/images/dst-id", Rootfs: "/store/images/dst-id/rootfs"}, nil)
+
+		cloner = groot.IamCloner(fakeImageManager, fakeLocksmith, fakeDependencyManager, fakeMetricsEmitter)
+		logger = lagertest.NewTestLogger("cloner")
+		spec = groot.CloneSpec{SourceID: "src-id", ID: "dst-id", Mount: true, DiskLimit: 1024}
+	})
+
+	Describe("Clone", func() {
+		It("clones the source image on its base volumes", func() {
+			image, err := cloner.Clone(logger, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(image.Rootfs).To(Equal("/store/images/dst-id/rootfs"))
+
+			Expect(fakeDependencyManager.DependenciesArgsForCall(0)).To(Equal("image:src-id"))
+
+			Expect(fakeImageManager.CloneCallCount()).To(Equal(1))
+			_, srcID, imageSpec := fakeImageManager.CloneArgsForCall(0)
+			Expect(srcID).To(Equal("src-id"))
+			Expect(imageSpec).To(Equal(groot.ImageSpec{
+				ID:            "dst-id",
+				Mount:         true,
+				DiskLimit:     1024,
+				BaseVolumeIDs: []string{"chain-1", "chain-2"},
+			}))
+		})
+
+		It("registers the clone dependencies", func() {
+			_, err := cloner.Clone(logger, spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(1))
+			id, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(id).To(Equal("image:dst-id"))
+			Expect(chainIDs).To(Equal([]string{"chain-1", "chain-2"}))
+		})
+
+		It("holds the global lock while cloning", func() {
+			fakeImageManager.CloneStub = func(_ lager.Logger, _ string, _ groot.ImageSpec) (groot.ImageInfo, error) {
+				Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(0))
+				return groot.ImageInfo{}, nil
+			}
+
+			_, err := cloner.Clone(logger, spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLocksmith.LockArgsForCall(0)).To(Equal(groot.GlobalLockKey))
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+			Expect(fakeLocksmith.UnlockArgsForCall(0)).To(Equal(lockFile))
+		})
+
+		It("emits metrics for cloning", func() {
+			_, err := cloner.Clone(logger, spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeMetricsEmitter.TryEmitDurationFromCallCount()).To(Equal(1))
+			_, name, start := fakeMetricsEmitter.TryEmitDurationFromArgsForCall(0)
+			Expect(name).To(Equal(groot.MetricImageCloneTime))
+			Expect(start).NotTo(BeZero())
+		})
+
+		Context("when the id contains invalid characters", func() {
+			It("returns an error", func() {
+				spec.ID = "dst/id"
+				_, err := cloner.Clone(logger, spec)
+				Expect(err).To(MatchError(ContainSubstring("invalid characters")))
+				Expect(fakeImageManager.CloneCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the destination image already exists", func() {
+			It("returns an error", func() {
+				spec.ID = "src-id"
+				_, err := cloner.Clone(logger, spec)
+				Expect(err).To(MatchError(ContainSubstring("image for id `src-id` already exists")))
+				Expect(fakeImageManager.CloneCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the source image does not exist", func() {
+			It("returns an error", func() {
+				spec.SourceID = "not-here"
+				_, err := cloner.Clone(logger, spec)
+				Expect(err).To(MatchError(ContainSubstring("source image `not-here` not found")))
+				Expect(fakeImageManager.CloneCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when taking the lock fails", func() {
+			BeforeEach(func() {
+				fakeLocksmith.LockReturns(nil, errors.New("lock failed"))
+			})
+
+			It("returns an error", func() {
+				_, err := cloner.Clone(logger, spec)
+				Expect(err).To(MatchError(ContainSubstring("lock failed")))
+				Expect(fakeImageManager.CloneCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the image manager fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.CloneReturns(groot.ImageInfo{}, errors.New("copying upper dir failed"))
+			})
+
+			It("returns an error", func() {
+				_, err := cloner.Clone(logger, spec)
+				Expect(err).To(MatchError(ContainSubstring("copying upper dir failed")))
+				Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when registering the dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.RegisterReturns(errors.New("registering failed"))
+			})
+
+			It("destroys the clone and returns an error", func() {
+				_, err := cloner.Clone(logger, spec)
+				Expect(err).To(MatchError(ContainSubstring("registering failed")))
+
+				Expect(fakeImageManager.DestroyCallCount()).To(Equal(1))
+				_, id := fakeImageManager.DestroyArgsForCall(0)
+				Expect(id).To(Equal("dst-id"))
+			})
+		})
+	})
+})
@@ -0,0 +1,192 @@
+package groot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	digestpkg "github.com/opencontainers/go-digest"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	errorspkg "github.com/pkg/errors"
+)
+
+const MetricImageCommitTime = "ImageCommitTime"
+
+//go:generate counterfeiter . RefManager
+
+type RefManager interface {
+	Exists(name string) (bool, error)
+	Save(name string, baseImageInfo BaseImageInfo) error
+	Delete(name string) error
+}
+
+type Committer struct {
+	imageManager      ImageManager
+	refManager        RefManager
+	locksmith         Locksmith
+	dependencyManager DependencyManager
+	metricsEmitter    MetricsEmitter
+}
+
+func IamCommitter(
+	imageManager ImageManager, refManager RefManager,
+	locksmith Locksmith, dependencyManager DependencyManager,
+	metricsEmitter MetricsEmitter) *Committer {
+	return &Committer{
+		imageManager:      imageManager,
+		refManager:        refManager,
+		locksmith:         locksmith,
+		dependencyManager: dependencyManager,
+		metricsEmitter:    metricsEmitter,
+	}
+}
+
+// Commit turns the changes made to an image into a new volume on top of the
+// image base volumes, and registers the resulting chain under the given ref.
+func (c *Committer) Commit(logger lager.Logger, id, ref string) (LayerInfo, error) {
+	defer c.metricsEmitter.TryEmitDurationFrom(logger, MetricImageCommitTime, time.Now())
+
+	logger = logger.Session("groot-committing", lager.Data{"imageID": id, "ref": ref})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if err := validateRef(ref); err != nil {
+		return LayerInfo{}, err
+	}
+
+	lockFile, err := c.locksmith.Lock(GlobalLockKey)
+	if err != nil {
+		return LayerInfo{}, err
+	}
+	defer func() {
+		if err := c.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	exists, err := c.refManager.Exists(ref)
+	if err != nil {
+		return LayerInfo{}, errorspkg.Wrap(err, "checking ref exists")
+	}
+	if exists {
+		return LayerInfo{}, errorspkg.Errorf("ref `%s` already exists", ref)
+	}
+
+	baseChainIDs, err := c.dependencyManager.Dependencies(fmt.Sprintf(ImageReferenceFormat, id))
+	if err != nil {
+		return LayerInfo{}, errorspkg.Wrap(err, "fetching image dependencies")
+	}
+
+	parentChainID := ""
+	if len(baseChainIDs) > 0 {
+		parentChainID = baseChainIDs[len(baseChainIDs)-1]
+	}
+
+	layerInfo, baseImage, err := c.imageManager.Commit(logger, id, parentChainID)
+	if err != nil {
+		return LayerInfo{}, err
+	}
+
+	refName := fmt.Sprintf(RefReferenceFormat, ref)
+	chainIDs := append(append([]string{}, baseChainIDs...), layerInfo.ChainID)
+	if err := c.dependencyManager.Register(refName, chainIDs); err != nil {
+		return LayerInfo{}, errorspkg.Wrap(err, "registering ref dependencies")
+	}
+
+	if err := c.refManager.Save(ref, committedImageInfo(baseChainIDs, baseImage, layerInfo)); err != nil {
+		if deregisterErr := c.dependencyManager.Deregister(refName); deregisterErr != nil {
+			logger.Error("failed-to-deregister-ref-dependencies", deregisterErr)
+		}
+		return LayerInfo{}, errorspkg.Wrap(err, "saving ref")
+	}
+
+	return layerInfo, nil
+}
+
+// DeleteRef removes a committed ref. Its volumes are left for clean to
+// collect once no image uses them.
+func (c *Committer) DeleteRef(logger lager.Logger, ref string) error {
+	logger = logger.Session("groot-deleting-ref", lager.Data{"ref": ref})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if err := validateRef(ref); err != nil {
+		return err
+	}
+
+	lockFile, err := c.locksmith.Lock(GlobalLockKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	exists, err := c.refManager.Exists(ref)
+	if err != nil {
+		return errorspkg.Wrap(err, "checking ref exists")
+	}
+	if !exists {
+		return errorspkg.Errorf("ref `%s` not found", ref)
+	}
+
+	// the ref goes first, so that no image is created from it once its
+	// volumes can be collected
+	if err := c.refManager.Delete(ref); err != nil {
+		return errorspkg.Wrap(err, "deleting ref")
+	}
+
+	if err := c.dependencyManager.Deregister(fmt.Sprintf(RefReferenceFormat, ref)); err != nil {
+		return errorspkg.Wrap(err, "deregistering ref dependencies")
+	}
+
+	return nil
+}
+
+func validateRef(ref string) error {
+	if ref == "" || strings.ContainsAny(ref, "/") {
+		return errorspkg.Errorf("ref `%s` is empty or contains invalid characters: `/`", ref)
+	}
+
+	return nil
+}
+
+// committedImageInfo describes the committed image as a base image whose
+// layers are all in the store already
+func committedImageInfo(baseChainIDs []string, baseImage specsv1.Image, layerInfo LayerInfo) BaseImageInfo {
+	// the diff ids of images from tarballs or older stores can't be told apart
+	knownDiffIDs := len(baseImage.RootFS.DiffIDs) == len(baseChainIDs)
+
+	layerInfos := []LayerInfo{}
+	parentChainID := ""
+	for i, chainID := range baseChainIDs {
+		baseLayerInfo := LayerInfo{ChainID: chainID, ParentChainID: parentChainID}
+		if knownDiffIDs {
+			baseLayerInfo.DiffID = baseImage.RootFS.DiffIDs[i].Encoded()
+		}
+		layerInfos = append(layerInfos, baseLayerInfo)
+		parentChainID = chainID
+	}
+	layerInfos = append(layerInfos, layerInfo)
+
+	config := baseImage
+	if knownDiffIDs {
+		config.RootFS.Type = "layers"
+		config.RootFS.DiffIDs = append(append([]digestpkg.Digest{}, baseImage.RootFS.DiffIDs...),
+			digestpkg.NewDigestFromEncoded(digestpkg.SHA256, layerInfo.DiffID))
+	}
+	now := time.Now().UTC()
+	config.Created = &now
+	config.History = append(append([]specsv1.History{}, baseImage.History...), specsv1.History{
+		Created:   &now,
+		CreatedBy: "grootfs commit",
+	})
+
+	return BaseImageInfo{
+		LayerInfos: layerInfos,
+		Config:     config,
+	}
+}
@@ -0,0 +1,262 @@
+package groot_test
+
+import (
+	"errors"
+	"os"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	digestpkg "github.com/opencontainers/go-digest"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Committer", func() {
+	var (
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeRefManager        *grootfakes.FakeRefManager
+		fakeLocksmith         *grootfakes.FakeLocksmith
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeMetricsEmitter    *grootfakes.FakeMetricsEmitter
+		lockFile              *os.File
+		committer             *groot.Committer
+		logger                lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeRefManager = new(grootfakes.FakeRefManager)
+		fakeLocksmith = new(grootfakes.FakeLocksmith)
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeMetricsEmitter = new(grootfakes.FakeMetricsEmitter)
+
+		lockFile = &os.File{}
+		fakeLocksmith.LockReturns(lockFile, nil)
+		fakeDependencyManager.DependenciesReturns([]string{"chain-1", "chain-2"}, nil)
+		fakeImageManager.CommitReturns(
+			groot.LayerInfo{ChainID: "chain-3", ParentChainID: "chain-2", DiffID: "diff-3"},
+			specsv1.Image{
+				Author: "Groot",
+				RootFS: specsv1.RootFS{
+					Type:    "layers",
+					DiffIDs: []digestpkg.Digest{"sha256:diff-1", "sha256:diff-2"},
+				},
+			},
+			nil,
+		)
+
+		committer = groot.IamCommitter(fakeImageManager, fakeRefManager, fakeLocksmith, fakeDependencyManager, fakeMetricsEmitter)
+		logger = lagertest.NewTestLogger("committer")
+	})
+
+	Describe("Commit", func() {
+		It("commits the image on top of its last base volume", func() {
+			layerInfo, err := committer.Commit(logger, "some-id", "my-ref")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layerInfo.ChainID).To(Equal("chain-3"))
+
+			Expect(fakeDependencyManager.DependenciesArgsForCall(0)).To(Equal("image:some-id"))
+			_, id, parentChainID := fakeImageManager.CommitArgsForCall(0)
+			Expect(id).To(Equal("some-id"))
+			Expect(parentChainID).To(Equal("chain-2"))
+		})
+
+		It("registers the ref dependencies", func() {
+			_, err := committer.Commit(logger, "some-id", "my-ref")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(1))
+			id, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(id).To(Equal("ref:my-ref"))
+			Expect(chainIDs).To(Equal([]string{"chain-1", "chain-2", "chain-3"}))
+		})
+
+		It("saves the ref as a base image made of the committed volumes", func() {
+			_, err := committer.Commit(logger, "some-id", "my-ref")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeRefManager.SaveCallCount()).To(Equal(1))
+			name, baseImageInfo := fakeRefManager.SaveArgsForCall(0)
+			Expect(name).To(Equal("my-ref"))
+			Expect(baseImageInfo.LayerInfos).To(Equal([]groot.LayerInfo{
+				{ChainID: "chain-1", DiffID: "diff-1"},
+				{ChainID: "chain-2", ParentChainID: "chain-1", DiffID: "diff-2"},
+				{ChainID: "chain-3", ParentChainID: "chain-2", DiffID: "diff-3"},
+			}))
+			Expect(baseImageInfo.Config.Author).To(Equal("Groot"))
+			Expect(baseImageInfo.Config.RootFS.DiffIDs).To(Equal([]digestpkg.Digest{"sha256:diff-1", "sha256:diff-2", "sha256:diff-3"}))
+			Expect(baseImageInfo.Config.History).To(HaveLen(1))
+			Expect(baseImageInfo.Config.History[0].CreatedBy).To(Equal("grootfs commit"))
+		})
+
+		It("holds the global lock while committing", func() {
+			_, err := committer.Commit(logger, "some-id", "my-ref")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+			Expect(fakeLocksmith.LockArgsForCall(0)).To(Equal(groot.GlobalLockKey))
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+			Expect(fakeLocksmith.UnlockArgsForCall(0)).To(Equal(lockFile))
+		})
+
+		It("emits metrics for committing", func() {
+			_, err := committer.Commit(logger, "some-id", "my-ref")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeMetricsEmitter.TryEmitDurationFromCallCount()).To(Equal(1))
+			_, name, start := fakeMetricsEmitter.TryEmitDurationFromArgsForCall(0)
+			Expect(name).To(Equal(groot.MetricImageCommitTime))
+			Expect(start).NotTo(BeZero())
+		})
+
+		Context("when the base image diff ids don't match its volumes", func() {
+			BeforeEach(func() {
+				fakeImageManager.CommitReturns(
+					groot.LayerInfo{ChainID: "chain-3", ParentChainID: "chain-2", DiffID: "diff-3"},
+					specsv1.Image{},
+					nil,
+				)
+			})
+
+			It("leaves the diff ids out", func() {
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, baseImageInfo := fakeRefManager.SaveArgsForCall(0)
+				Expect(baseImageInfo.LayerInfos[0].DiffID).To(BeEmpty())
+				Expect(baseImageInfo.Config.RootFS.DiffIDs).To(BeEmpty())
+			})
+		})
+
+		Context("when the ref is invalid", func() {
+			It("returns an error", func() {
+				_, err := committer.Commit(logger, "some-id", "my/ref")
+				Expect(err).To(MatchError(ContainSubstring("invalid characters")))
+				Expect(fakeImageManager.CommitCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the ref already exists", func() {
+			BeforeEach(func() {
+				fakeRefManager.ExistsReturns(true, nil)
+			})
+
+			It("returns an error", func() {
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).To(MatchError("ref `my-ref` already exists"))
+				Expect(fakeImageManager.CommitCallCount()).To(Equal(0))
+			})
+
+			It("checks it while holding the global lock", func() {
+				fakeRefManager.ExistsStub = func(string) (bool, error) {
+					Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+					Expect(fakeLocksmith.UnlockCallCount()).To(Equal(0))
+					return true, nil
+				}
+
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).To(HaveOccurred())
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when fetching the image dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.DependenciesReturns(nil, errors.New("image `some-id` not found"))
+			})
+
+			It("returns an error", func() {
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).To(MatchError(ContainSubstring("image `some-id` not found")))
+			})
+		})
+
+		Context("when committing the image fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.CommitReturns(groot.LayerInfo{}, specsv1.Image{}, errors.New("failed to commit"))
+			})
+
+			It("returns an error and doesn't save the ref", func() {
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).To(MatchError(ContainSubstring("failed to commit")))
+				Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(0))
+				Expect(fakeRefManager.SaveCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when saving the ref fails", func() {
+			BeforeEach(func() {
+				fakeRefManager.SaveReturns(errors.New("disk full"))
+			})
+
+			It("returns an error", func() {
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).To(MatchError(ContainSubstring("disk full")))
+			})
+
+			It("deregisters the ref dependencies", func() {
+				_, err := committer.Commit(logger, "some-id", "my-ref")
+				Expect(err).To(HaveOccurred())
+
+				Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(1))
+				Expect(fakeDependencyManager.DeregisterArgsForCall(0)).To(Equal("ref:my-ref"))
+			})
+		})
+	})
+
+	Describe("DeleteRef", func() {
+		BeforeEach(func() {
+			fakeRefManager.ExistsReturns(true, nil)
+		})
+
+		It("deletes the ref and deregisters its dependencies", func() {
+			Expect(committer.DeleteRef(logger, "my-ref")).To(Succeed())
+
+			Expect(fakeRefManager.DeleteCallCount()).To(Equal(1))
+			Expect(fakeRefManager.DeleteArgsForCall(0)).To(Equal("my-ref"))
+			Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(1))
+			Expect(fakeDependencyManager.DeregisterArgsForCall(0)).To(Equal("ref:my-ref"))
+		})
+
+		It("holds the global lock while deleting", func() {
+			Expect(committer.DeleteRef(logger, "my-ref")).To(Succeed())
+
+			Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+			Expect(fakeLocksmith.LockArgsForCall(0)).To(Equal(groot.GlobalLockKey))
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when the ref does not exist", func() {
+			BeforeEach(func() {
+				fakeRefManager.ExistsReturns(false, nil)
+			})
+
+			It("returns an error", func() {
+				Expect(committer.DeleteRef(logger, "my-ref")).To(MatchError("ref `my-ref` not found"))
+				Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the ref is invalid", func() {
+			It("returns an error", func() {
+				Expect(committer.DeleteRef(logger, "my/ref")).To(MatchError(ContainSubstring("invalid characters")))
+				Expect(fakeRefManager.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when deleting the ref fails", func() {
+			BeforeEach(func() {
+				fakeRefManager.DeleteReturns(errors.New("read-only"))
+			})
+
+			It("returns an error and keeps its dependencies", func() {
+				Expect(committer.DeleteRef(logger, "my-ref")).To(MatchError(ContainSubstring("read-only")))
+				Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(0))
+			})
+		})
+	})
+})
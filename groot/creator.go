@@ -1,9 +1,12 @@
 package groot
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,7 +14,17 @@ import (
 	errorspkg "github.com/pkg/errors"
 )
 
-const ImageReferenceFormat = "image:%s"
+const (
+	ImageReferenceFormat = "image:%s"
+	RefReferenceFormat   = "ref:%s"
+	PinReferenceFormat   = "pin:%s"
+)
+
+// SquashedVolumeID is the id of the volume flattening all the volumes of the
+// chain ending with the given chain ID
+func SquashedVolumeID(chainID string) string {
+	return chainID + "-squashed"
+}
 
 type CreateSpec struct {
 	ID                          string
@@ -23,6 +36,18 @@ type CreateSpec struct {
 	CleanOnCreateThresholdBytes int64
 	UIDMappings                 []IDMappingSpec
 	GIDMappings                 []IDMappingSpec
+	ExcludePaths                []string
+	// IDMappedMount keeps the base image layers unmapped in the store and
+	// applies the id mappings when the image is mounted instead
+	IDMappedMount bool
+	// StoreIDMappings are the store default mappings. Volumes are only shared
+	// with images whose layers end up with the same ids on disk.
+	StoreIDMappings IDMappings
+	// Squash mounts the image on a single volume flattening all the base
+	// image layers
+	Squash bool
+	// Labels are user-defined key/value pairs kept with the image
+	Labels map[string]string
 }
 
 type Creator struct {
@@ -67,7 +92,7 @@ func (c *Creator) Create(logger lager.Logger, spec CreateSpec) (info ImageInfo,
 		return ImageInfo{}, errorspkg.Errorf("image for id `%s` already exists", spec.ID)
 	}
 
-	ownerUid, ownerGid := c.parseOwner(spec.UIDMappings, spec.GIDMappings)
+	ownerUid, ownerGid := parseOwner(spec.UIDMappings, spec.GIDMappings)
 	baseImageSpec := BaseImageSpec{
 		DiskLimit:                 spec.DiskLimit,
 		ExcludeBaseImageFromQuota: spec.ExcludeBaseImageFromQuota,
@@ -75,13 +100,31 @@ func (c *Creator) Create(logger lager.Logger, spec CreateSpec) (info ImageInfo,
 		GIDMappings:               spec.GIDMappings,
 		OwnerUID:                  ownerUid,
 		OwnerGID:                  ownerGid,
+		ExcludePaths:              spec.ExcludePaths,
+	}
+
+	idMappedMount := spec.IDMappedMount && (len(spec.UIDMappings) > 0 || len(spec.GIDMappings) > 0)
+	if idMappedMount {
+		baseImageSpec.UIDMappings = nil
+		baseImageSpec.GIDMappings = nil
+		baseImageSpec.OwnerUID, baseImageSpec.OwnerGID = parseOwner(nil, nil)
 	}
 
 	baseImageInfo, err := c.baseImagePuller.FetchBaseImageInfo(logger)
 	if err != nil {
 		return ImageInfo{}, err
 	}
+	baseImageInfo.LayerInfos = withExclusions(baseImageInfo.LayerInfos, spec.ExcludePaths)
+	baseImageInfo.LayerInfos = withIDMappings(baseImageInfo.LayerInfos,
+		IDMappings{UIDMappings: baseImageSpec.UIDMappings, GIDMappings: baseImageSpec.GIDMappings},
+		spec.StoreIDMappings,
+	)
 	baseImageChainIDs := chainIDs(baseImageInfo.LayerInfos)
+	if spec.BaseImageURL != nil {
+		baseImageSpec.BaseImageReference = spec.BaseImageURL.String()
+	}
+	baseImageSpec.ManifestDigest = baseImageInfo.ManifestDigest
+	baseImageSpec.ImageID = spec.ID
 
 	lockFile, err := c.locksmith.Lock(GlobalLockKey)
 	if err != nil {
@@ -112,6 +155,15 @@ func (c *Creator) Create(logger lager.Logger, spec CreateSpec) (info ImageInfo,
 		BaseImage:                 baseImageInfo.Config,
 		OwnerUID:                  ownerUid,
 		OwnerGID:                  ownerGid,
+		Squash:                    spec.Squash,
+		Labels:                    spec.Labels,
+		BaseImageReference:        baseImageSpec.BaseImageReference,
+		ManifestDigest:            baseImageInfo.ManifestDigest,
+	}
+	if idMappedMount || idMappingsKey(IDMappings{UIDMappings: spec.UIDMappings, GIDMappings: spec.GIDMappings}) != idMappingsKey(spec.StoreIDMappings) {
+		imageSpec.UIDMappings = spec.UIDMappings
+		imageSpec.GIDMappings = spec.GIDMappings
+		imageSpec.IDMappedMount = idMappedMount
 	}
 
 	image, err := c.imageManager.Create(logger, imageSpec)
@@ -139,7 +191,63 @@ func chainIDs(layerInfos []LayerInfo) []string {
 	return chainIDs
 }
 
-func (c *Creator) parseOwner(uidMappings, gidMappings []IDMappingSpec) (int, int) {
+// withExclusions folds the exclusion set into the layer chain IDs, so that
+// volumes unpacked with exclusions never get mixed up with unfiltered ones.
+func withExclusions(layerInfos []LayerInfo, excludePaths []string) []LayerInfo {
+	if len(excludePaths) == 0 {
+		return layerInfos
+	}
+
+	sortedPaths := append([]string{}, excludePaths...)
+	sort.Strings(sortedPaths)
+
+	return rechain(layerInfos, "exclude="+strings.Join(sortedPaths, ":"))
+}
+
+// withIDMappings keeps volumes whose files are owned by different ids apart.
+// Volumes matching the store mappings keep their original chain IDs.
+func withIDMappings(layerInfos []LayerInfo, volumeMappings, storeMappings IDMappings) []LayerInfo {
+	volumeMappingsKey := idMappingsKey(volumeMappings)
+	if volumeMappingsKey == idMappingsKey(storeMappings) {
+		return layerInfos
+	}
+
+	if volumeMappingsKey == "" {
+		return rechain(layerInfos, "unmapped")
+	}
+
+	mappingsSha := sha256.Sum256([]byte(volumeMappingsKey))
+	return rechain(layerInfos, "mappings="+hex.EncodeToString(mappingsSha[:]))
+}
+
+func idMappingsKey(mappings IDMappings) string {
+	if len(mappings.UIDMappings) == 0 && len(mappings.GIDMappings) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("uid=%s gid=%s",
+		strings.Join(normalizeIDMappings(mappings.UIDMappings), ","),
+		strings.Join(normalizeIDMappings(mappings.GIDMappings), ","),
+	)
+}
+
+// rechain derives new chain IDs from the given ones and the qualifier, for
+// volumes whose contents differ from a plain unpack of the same layers.
+func rechain(layerInfos []LayerInfo, qualifier string) []LayerInfo {
+	rechainedLayerInfos := []LayerInfo{}
+	parentChainID := ""
+	for _, layerInfo := range layerInfos {
+		chainIDSha := sha256.Sum256([]byte(fmt.Sprintf("%s %s", layerInfo.ChainID, qualifier)))
+		layerInfo.ChainID = hex.EncodeToString(chainIDSha[:])
+		layerInfo.ParentChainID = parentChainID
+		rechainedLayerInfos = append(rechainedLayerInfos, layerInfo)
+		parentChainID = layerInfo.ChainID
+	}
+
+	return rechainedLayerInfos
+}
+
+func parseOwner(uidMappings, gidMappings []IDMappingSpec) (int, int) {
 	uid := os.Getuid()
 	gid := os.Getgid()
 
@@ -132,6 +132,10 @@ var _ = Describe("Creator", func() {
 				BaseImageURL: baseImageUrl,
 				UIDMappings:  uidMappings,
 				GIDMappings:  gidMappings,
+				StoreIDMappings: groot.IDMappings{
+					UIDMappings: uidMappings,
+					GIDMappings: gidMappings,
+				},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -143,6 +147,37 @@ var _ = Describe("Creator", func() {
 			Expect(imageSpec.OwnerGID).To(Equal(3))
 		})
 
+		It("records the base image and the image in the volumes", func() {
+			baseImageInfo.ManifestDigest = "sha256:manifest"
+			fakeBaseImagePuller.FetchBaseImageInfoReturns(baseImageInfo, nil)
+
+			_, err := creator.Create(logger, groot.CreateSpec{
+				ID:           "some-id",
+				BaseImageURL: baseImageUrl,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, imageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+			Expect(imageSpec.BaseImageReference).To(Equal(baseImageUrl.String()))
+			Expect(imageSpec.ManifestDigest).To(Equal("sha256:manifest"))
+			Expect(imageSpec.ImageID).To(Equal("some-id"))
+		})
+
+		It("records the base image with the image", func() {
+			baseImageInfo.ManifestDigest = "sha256:manifest"
+			fakeBaseImagePuller.FetchBaseImageInfoReturns(baseImageInfo, nil)
+
+			_, err := creator.Create(logger, groot.CreateSpec{
+				ID:           "some-id",
+				BaseImageURL: baseImageUrl,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+			Expect(imageSpec.BaseImageReference).To(Equal(baseImageUrl.String()))
+			Expect(imageSpec.ManifestDigest).To(Equal("sha256:manifest"))
+		})
+
 		It("makes an image", func() {
 
 			uidMappings := []groot.IDMappingSpec{groot.IDMappingSpec{HostID: 50, NamespaceID: 0, Size: 1}}
@@ -152,6 +187,10 @@ var _ = Describe("Creator", func() {
 				BaseImageURL: baseImageUrl,
 				UIDMappings:  uidMappings,
 				GIDMappings:  gidMappings,
+				StoreIDMappings: groot.IDMappings{
+					UIDMappings: uidMappings,
+					GIDMappings: gidMappings,
+				},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -163,8 +202,9 @@ var _ = Describe("Creator", func() {
 				BaseImage: specsv1.Image{
 					Author: "Groot",
 				},
-				OwnerUID: 50,
-				OwnerGID: 60,
+				OwnerUID:           50,
+				OwnerGID:           60,
+				BaseImageReference: baseImageUrl.String(),
 			}))
 		})
 
@@ -415,11 +455,250 @@ var _ = Describe("Creator", func() {
 					BaseImage: specsv1.Image{
 						Author: "Groot",
 					},
-					OwnerUID:  os.Getuid(),
-					OwnerGID:  os.Getgid(),
-					DiskLimit: int64(1024),
+					OwnerUID:           os.Getuid(),
+					OwnerGID:           os.Getgid(),
+					DiskLimit:          int64(1024),
+					BaseImageReference: baseImageUrl.String(),
 				}))
 			})
 		})
+
+		Context("when squashing is requested", func() {
+			It("asks the image manager to squash the image", func() {
+				_, err := creator.Create(logger, groot.CreateSpec{
+					ID:           "some-id",
+					BaseImageURL: baseImageUrl,
+					Squash:       true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+				Expect(imageSpec.Squash).To(BeTrue())
+				Expect(imageSpec.BaseVolumeIDs).To(Equal([]string{"id-1", "id-2"}))
+			})
+		})
+
+		Context("when labels are given", func() {
+			It("asks the image manager to keep them with the image", func() {
+				_, err := creator.Create(logger, groot.CreateSpec{
+					ID:           "some-id",
+					BaseImageURL: baseImageUrl,
+					Labels:       map[string]string{"app": "some-app"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+				Expect(imageSpec.Labels).To(Equal(map[string]string{"app": "some-app"}))
+			})
+		})
+
+		Context("when exclude paths are given", func() {
+			var createSpec groot.CreateSpec
+
+			BeforeEach(func() {
+				createSpec = groot.CreateSpec{
+					ID:           "some-id",
+					BaseImageURL: baseImageUrl,
+					ExcludePaths: []string{"/usr/share/doc", "/usr/share/man"},
+				}
+			})
+
+			It("passes them to the base image puller", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+				Expect(baseImageSpec.ExcludePaths).To(ConsistOf("/usr/share/doc", "/usr/share/man"))
+			})
+
+			It("pulls the layers under different chain IDs", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, pulledImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(0)
+				Expect(pulledImageInfo.LayerInfos).To(HaveLen(2))
+				Expect(pulledImageInfo.LayerInfos[0].ChainID).NotTo(Equal("id-1"))
+				Expect(pulledImageInfo.LayerInfos[0].ParentChainID).To(BeEmpty())
+				Expect(pulledImageInfo.LayerInfos[1].ChainID).NotTo(Equal("id-2"))
+				Expect(pulledImageInfo.LayerInfos[1].ParentChainID).To(Equal(pulledImageInfo.LayerInfos[0].ChainID))
+
+				_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+				Expect(imageSpec.BaseVolumeIDs).To(Equal([]string{
+					pulledImageInfo.LayerInfos[0].ChainID,
+					pulledImageInfo.LayerInfos[1].ChainID,
+				}))
+
+				_, registeredChainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+				Expect(registeredChainIDs).To(Equal(imageSpec.BaseVolumeIDs))
+			})
+
+			It("derives the same chain IDs regardless of the exclusions order", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				createSpec.ExcludePaths = []string{"/usr/share/man", "/usr/share/doc"}
+				_, err = creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, firstImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(0)
+				_, secondImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(1)
+				Expect(firstImageInfo.LayerInfos).To(Equal(secondImageInfo.LayerInfos))
+			})
+		})
+
+		Context("when the mappings differ from the store ones", func() {
+			var createSpec groot.CreateSpec
+
+			BeforeEach(func() {
+				createSpec = groot.CreateSpec{
+					ID:           "some-id",
+					BaseImageURL: baseImageUrl,
+					UIDMappings:  []groot.IDMappingSpec{{HostID: 50, NamespaceID: 0, Size: 1}, {HostID: 200000, NamespaceID: 1, Size: 1000}},
+					GIDMappings:  []groot.IDMappingSpec{{HostID: 60, NamespaceID: 0, Size: 1}, {HostID: 200000, NamespaceID: 1, Size: 1000}},
+					StoreIDMappings: groot.IDMappings{
+						UIDMappings: []groot.IDMappingSpec{{HostID: 50, NamespaceID: 0, Size: 1}, {HostID: 100000, NamespaceID: 1, Size: 1000}},
+						GIDMappings: []groot.IDMappingSpec{{HostID: 60, NamespaceID: 0, Size: 1}, {HostID: 100000, NamespaceID: 1, Size: 1000}},
+					},
+				}
+			})
+
+			It("pulls the layers with the image mappings", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+				Expect(baseImageSpec.UIDMappings).To(Equal(createSpec.UIDMappings))
+				Expect(baseImageSpec.GIDMappings).To(Equal(createSpec.GIDMappings))
+			})
+
+			It("passes the mappings on to the image manager", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+				Expect(imageSpec.UIDMappings).To(Equal(createSpec.UIDMappings))
+				Expect(imageSpec.GIDMappings).To(Equal(createSpec.GIDMappings))
+				Expect(imageSpec.IDMappedMount).To(BeFalse())
+			})
+
+			It("pulls the layers under different chain IDs", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, pulledImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(0)
+				Expect(pulledImageInfo.LayerInfos).To(HaveLen(2))
+				Expect(pulledImageInfo.LayerInfos[0].ChainID).NotTo(Equal("id-1"))
+				Expect(pulledImageInfo.LayerInfos[1].ChainID).NotTo(Equal("id-2"))
+				Expect(pulledImageInfo.LayerInfos[1].ParentChainID).To(Equal(pulledImageInfo.LayerInfos[0].ChainID))
+			})
+
+			It("derives the same chain IDs regardless of the mappings order", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				createSpec.UIDMappings = []groot.IDMappingSpec{createSpec.UIDMappings[1], createSpec.UIDMappings[0]}
+				_, err = creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, firstImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(0)
+				_, secondImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(1)
+				Expect(firstImageInfo.LayerInfos).To(Equal(secondImageInfo.LayerInfos))
+			})
+
+			It("derives different chain IDs for different mappings", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				createSpec.GIDMappings = createSpec.StoreIDMappings.GIDMappings
+				_, err = creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, firstImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(0)
+				_, secondImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(1)
+				Expect(firstImageInfo.LayerInfos[0].ChainID).NotTo(Equal(secondImageInfo.LayerInfos[0].ChainID))
+			})
+		})
+
+		Context("when the layers are idmapped at mount time", func() {
+			var createSpec groot.CreateSpec
+
+			BeforeEach(func() {
+				createSpec = groot.CreateSpec{
+					ID:            "some-id",
+					BaseImageURL:  baseImageUrl,
+					UIDMappings:   []groot.IDMappingSpec{{HostID: 50, NamespaceID: 0, Size: 1}},
+					GIDMappings:   []groot.IDMappingSpec{{HostID: 60, NamespaceID: 0, Size: 1}},
+					IDMappedMount: true,
+				}
+				createSpec.StoreIDMappings = groot.IDMappings{
+					UIDMappings: createSpec.UIDMappings,
+					GIDMappings: createSpec.GIDMappings,
+				}
+			})
+
+			It("pulls the layers unmapped and owned by the current user", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+				Expect(baseImageSpec.UIDMappings).To(BeEmpty())
+				Expect(baseImageSpec.GIDMappings).To(BeEmpty())
+				Expect(baseImageSpec.OwnerUID).To(Equal(os.Getuid()))
+				Expect(baseImageSpec.OwnerGID).To(Equal(os.Getgid()))
+			})
+
+			It("pulls the layers under different chain IDs", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, pulledImageInfo, _ := fakeBaseImagePuller.PullArgsForCall(0)
+				Expect(pulledImageInfo.LayerInfos).To(HaveLen(2))
+				Expect(pulledImageInfo.LayerInfos[0].ChainID).NotTo(Equal("id-1"))
+				Expect(pulledImageInfo.LayerInfos[1].ChainID).NotTo(Equal("id-2"))
+				Expect(pulledImageInfo.LayerInfos[1].ParentChainID).To(Equal(pulledImageInfo.LayerInfos[0].ChainID))
+			})
+
+			It("passes the mappings on to the image manager", func() {
+				_, err := creator.Create(logger, createSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+				Expect(imageSpec.UIDMappings).To(Equal(createSpec.UIDMappings))
+				Expect(imageSpec.GIDMappings).To(Equal(createSpec.GIDMappings))
+				Expect(imageSpec.IDMappedMount).To(BeTrue())
+				Expect(imageSpec.OwnerUID).To(Equal(50))
+				Expect(imageSpec.OwnerGID).To(Equal(60))
+			})
+
+			Context("and the store has no mappings", func() {
+				BeforeEach(func() {
+					createSpec.StoreIDMappings = groot.IDMappings{}
+				})
+
+				It("shares the volumes of images without mappings", func() {
+					_, err := creator.Create(logger, createSpec)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+					Expect(imageSpec.BaseVolumeIDs).To(Equal([]string{"id-1", "id-2"}))
+				})
+			})
+
+			Context("but there are no mappings", func() {
+				BeforeEach(func() {
+					createSpec.UIDMappings = nil
+					createSpec.GIDMappings = nil
+					createSpec.StoreIDMappings = groot.IDMappings{}
+				})
+
+				It("keeps the original chain IDs", func() {
+					_, err := creator.Create(logger, createSpec)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, imageSpec := fakeImageManager.CreateArgsForCall(0)
+					Expect(imageSpec.BaseVolumeIDs).To(Equal([]string{"id-1", "id-2"}))
+				})
+			})
+		})
 	})
 })
@@ -0,0 +1,293 @@
+package groot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/klauspost/compress/zstd"
+	digestpkg "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	errorspkg "github.com/pkg/errors"
+)
+
+const (
+	MetricImageExportTime = "ImageExportTime"
+
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// LayerDescriptor identifies an exported layer: its digest and size are the
+// ones of the compressed blob, and its diff id the one of the uncompressed tar
+type LayerDescriptor struct {
+	MediaType string `json:"media_type"`
+	Digest    string `json:"digest"`
+	DiffID    string `json:"diff_id"`
+	Size      int64  `json:"size"`
+}
+
+type Exporter struct {
+	imageManager      ImageManager
+	dependencyManager DependencyManager
+	metricsEmitter    MetricsEmitter
+}
+
+func IamExporter(imageManager ImageManager, dependencyManager DependencyManager, metricsEmitter MetricsEmitter) *Exporter {
+	return &Exporter{
+		imageManager:      imageManager,
+		dependencyManager: dependencyManager,
+		metricsEmitter:    metricsEmitter,
+	}
+}
+
+// ExportDiff writes the changes made to an image as a compressed OCI layer.
+// The owners are mapped back through the given mappings, so that the layer
+// has the ids seen inside the image.
+func (e *Exporter) ExportDiff(logger lager.Logger, id, compression string, idMappings IDMappings, w io.Writer) (LayerDescriptor, error) {
+	defer e.metricsEmitter.TryEmitDurationFrom(logger, MetricImageExportTime, time.Now())
+
+	logger = logger.Session("groot-exporting-diff", lager.Data{"imageID": id, "compression": compression})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	return writeLayer(compression, w, func(tarWriter io.Writer) error {
+		return e.imageManager.ExportDiff(logger, id, idMappings, tarWriter)
+	})
+}
+
+// ExportImage writes the image, i.e. its base volumes and the changes made to
+// it, as a standalone OCI image layout, which can be used as an oci:/// base
+// image. The layout is tagged when a tag is given.
+func (e *Exporter) ExportImage(logger lager.Logger, id, compression, layoutPath, tag string, idMappings IDMappings) (specsv1.Descriptor, error) {
+	defer e.metricsEmitter.TryEmitDurationFrom(logger, MetricImageExportTime, time.Now())
+
+	logger = logger.Session("groot-exporting-image", lager.Data{"imageID": id, "layoutPath": layoutPath, "compression": compression})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if _, err := layerMediaType(compression); err != nil {
+		return specsv1.Descriptor{}, err
+	}
+
+	if _, err := os.Stat(filepath.Join(layoutPath, specsv1.ImageLayoutFile)); err == nil {
+		return specsv1.Descriptor{}, errorspkg.Errorf("`%s` already contains an image layout", layoutPath)
+	}
+
+	baseImage, err := e.imageManager.BaseImage(id)
+	if err != nil {
+		return specsv1.Descriptor{}, err
+	}
+
+	volumeIDs, err := e.dependencyManager.Dependencies(fmt.Sprintf(ImageReferenceFormat, id))
+	if err != nil {
+		return specsv1.Descriptor{}, errorspkg.Wrap(err, "fetching image dependencies")
+	}
+
+	blobsPath := filepath.Join(layoutPath, "blobs", string(digestpkg.SHA256))
+	if err := os.MkdirAll(blobsPath, 0755); err != nil {
+		return specsv1.Descriptor{}, errorspkg.Wrap(err, "creating image layout")
+	}
+
+	layers := []LayerDescriptor{}
+	for _, volumeID := range volumeIDs {
+		volumeID := volumeID
+		layer, err := writeLayerBlob(blobsPath, compression, func(tarWriter io.Writer) error {
+			return e.imageManager.ExportVolume(logger, id, volumeID, idMappings, tarWriter)
+		})
+		if err != nil {
+			return specsv1.Descriptor{}, err
+		}
+		layers = append(layers, layer)
+	}
+
+	layer, err := writeLayerBlob(blobsPath, compression, func(tarWriter io.Writer) error {
+		return e.imageManager.ExportDiff(logger, id, idMappings, tarWriter)
+	})
+	if err != nil {
+		return specsv1.Descriptor{}, err
+	}
+	layers = append(layers, layer)
+
+	configDescriptor, err := writeJSONBlob(blobsPath, specsv1.MediaTypeImageConfig, exportedImageConfig(baseImage, layers))
+	if err != nil {
+		return specsv1.Descriptor{}, errorspkg.Wrap(err, "writing image config")
+	}
+
+	manifest := specsv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: specsv1.MediaTypeImageManifest,
+		Config:    configDescriptor,
+	}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, specsv1.Descriptor{
+			MediaType: layer.MediaType,
+			Digest:    digestpkg.Digest(layer.Digest),
+			Size:      layer.Size,
+		})
+	}
+
+	manifestDescriptor, err := writeJSONBlob(blobsPath, specsv1.MediaTypeImageManifest, manifest)
+	if err != nil {
+		return specsv1.Descriptor{}, errorspkg.Wrap(err, "writing image manifest")
+	}
+	if tag != "" {
+		manifestDescriptor.Annotations = map[string]string{specsv1.AnnotationRefName: tag}
+	}
+
+	index := specsv1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: specsv1.MediaTypeImageIndex,
+		Manifests: []specsv1.Descriptor{manifestDescriptor},
+	}
+	if err := writeJSONFile(filepath.Join(layoutPath, "index.json"), index); err != nil {
+		return specsv1.Descriptor{}, errorspkg.Wrap(err, "writing image index")
+	}
+
+	// the layout marker goes last, so that incomplete layouts are not mistaken
+	// for complete ones
+	imageLayout := specsv1.ImageLayout{Version: specsv1.ImageLayoutVersion}
+	if err := writeJSONFile(filepath.Join(layoutPath, specsv1.ImageLayoutFile), imageLayout); err != nil {
+		return specsv1.Descriptor{}, errorspkg.Wrap(err, "writing image layout marker")
+	}
+
+	return manifestDescriptor, nil
+}
+
+// exportedImageConfig describes the exported layers in the base image config
+func exportedImageConfig(baseImage specsv1.Image, layers []LayerDescriptor) specsv1.Image {
+	config := baseImage
+	if config.OS == "" {
+		config.OS = "linux"
+	}
+	if config.Architecture == "" {
+		config.Architecture = runtime.GOARCH
+	}
+
+	config.RootFS = specsv1.RootFS{Type: "layers", DiffIDs: []digestpkg.Digest{}}
+	for _, layer := range layers {
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, digestpkg.Digest(layer.DiffID))
+	}
+
+	now := time.Now().UTC()
+	config.Created = &now
+	config.History = append(append([]specsv1.History{}, baseImage.History...), specsv1.History{
+		Created:   &now,
+		CreatedBy: "grootfs export",
+	})
+
+	return config
+}
+
+// writeLayer compresses the tarball written by writeTar into w
+func writeLayer(compression string, w io.Writer, writeTar func(io.Writer) error) (LayerDescriptor, error) {
+	blobDigester := digestpkg.SHA256.Digester()
+	blobCounter := &countingWriter{w: io.MultiWriter(w, blobDigester.Hash())}
+
+	mediaType, err := layerMediaType(compression)
+	if err != nil {
+		return LayerDescriptor{}, err
+	}
+
+	var compressor io.WriteCloser
+	if compression == CompressionZstd {
+		if compressor, err = zstd.NewWriter(blobCounter); err != nil {
+			return LayerDescriptor{}, errorspkg.Wrap(err, "creating zstd writer")
+		}
+	} else {
+		compressor = gzip.NewWriter(blobCounter)
+	}
+
+	diffIDDigester := digestpkg.SHA256.Digester()
+	if err := writeTar(io.MultiWriter(compressor, diffIDDigester.Hash())); err != nil {
+		return LayerDescriptor{}, err
+	}
+
+	if err := compressor.Close(); err != nil {
+		return LayerDescriptor{}, errorspkg.Wrap(err, "compressing layer")
+	}
+
+	return LayerDescriptor{
+		MediaType: mediaType,
+		Digest:    blobDigester.Digest().String(),
+		DiffID:    diffIDDigester.Digest().String(),
+		Size:      blobCounter.count,
+	}, nil
+}
+
+func layerMediaType(compression string) (string, error) {
+	switch compression {
+	case CompressionGzip:
+		return specsv1.MediaTypeImageLayerGzip, nil
+	case CompressionZstd:
+		return specsv1.MediaTypeImageLayerZstd, nil
+	default:
+		return "", errorspkg.Errorf("compression `%s` is not supported, use `%s` or `%s`", compression, CompressionGzip, CompressionZstd)
+	}
+}
+
+// writeLayerBlob writes a layer into the blobs folder of an image layout
+func writeLayerBlob(blobsPath, compression string, writeTar func(io.Writer) error) (LayerDescriptor, error) {
+	blobFile, err := os.CreateTemp(blobsPath, "layer-")
+	if err != nil {
+		return LayerDescriptor{}, errorspkg.Wrap(err, "creating layer blob")
+	}
+	defer os.Remove(blobFile.Name())
+	defer blobFile.Close()
+
+	layer, err := writeLayer(compression, blobFile, writeTar)
+	if err != nil {
+		return LayerDescriptor{}, err
+	}
+
+	if err := os.Rename(blobFile.Name(), filepath.Join(blobsPath, digestpkg.Digest(layer.Digest).Encoded())); err != nil {
+		return LayerDescriptor{}, errorspkg.Wrap(err, "moving layer blob")
+	}
+
+	return layer, nil
+}
+
+func writeJSONBlob(blobsPath, mediaType string, content interface{}) (specsv1.Descriptor, error) {
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		return specsv1.Descriptor{}, err
+	}
+
+	digest := digestpkg.FromBytes(contentBytes)
+	if err := os.WriteFile(filepath.Join(blobsPath, digest.Encoded()), contentBytes, 0644); err != nil {
+		return specsv1.Descriptor{}, err
+	}
+
+	return specsv1.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest,
+		Size:      int64(len(contentBytes)),
+	}, nil
+}
+
+func writeJSONFile(path string, content interface{}) error {
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, contentBytes, 0644)
+}
+
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,278 @@
+package groot_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/klauspost/compress/zstd"
+	digestpkg "github.com/opencontainers/go-digest"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeMetricsEmitter    *grootfakes.FakeMetricsEmitter
+		exporter              *groot.Exporter
+		logger                lager.Logger
+		output                *bytes.Buffer
+		idMappings            groot.IDMappings
+	)
+
+	BeforeEach(func() {
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeMetricsEmitter = new(grootfakes.FakeMetricsEmitter)
+		fakeImageManager.ExportDiffStub = func(_ lager.Logger, _ string, _ groot.IDMappings, w io.Writer) error {
+			_, err := w.Write([]byte("layer-contents"))
+			return err
+		}
+
+		idMappings = groot.IDMappings{
+			UIDMappings: []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+		}
+		output = new(bytes.Buffer)
+
+		exporter = groot.IamExporter(fakeImageManager, fakeDependencyManager, fakeMetricsEmitter)
+		logger = lagertest.NewTestLogger("exporter")
+	})
+
+	Describe("ExportDiff", func() {
+		It("exports the image diff with the given mappings", func() {
+			_, err := exporter.ExportDiff(logger, "some-id", groot.CompressionGzip, idMappings, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeImageManager.ExportDiffCallCount()).To(Equal(1))
+			_, id, mappings, _ := fakeImageManager.ExportDiffArgsForCall(0)
+			Expect(id).To(Equal("some-id"))
+			Expect(mappings).To(Equal(idMappings))
+		})
+
+		It("writes a gzip layer and describes it", func() {
+			descriptor, err := exporter.ExportDiff(logger, "some-id", groot.CompressionGzip, idMappings, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			gzipReader, err := gzip.NewReader(bytes.NewReader(output.Bytes()))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(io.ReadAll(gzipReader)).To(BeEquivalentTo("layer-contents"))
+
+			Expect(descriptor).To(Equal(groot.LayerDescriptor{
+				MediaType: specsv1.MediaTypeImageLayerGzip,
+				Digest:    digestpkg.FromBytes(output.Bytes()).String(),
+				DiffID:    digestpkg.FromString("layer-contents").String(),
+				Size:      int64(output.Len()),
+			}))
+		})
+
+		It("writes a zstd layer when asked to", func() {
+			descriptor, err := exporter.ExportDiff(logger, "some-id", groot.CompressionZstd, idMappings, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			zstdReader, err := zstd.NewReader(bytes.NewReader(output.Bytes()))
+			Expect(err).NotTo(HaveOccurred())
+			defer zstdReader.Close()
+			Expect(io.ReadAll(zstdReader)).To(BeEquivalentTo("layer-contents"))
+
+			Expect(descriptor.MediaType).To(Equal(specsv1.MediaTypeImageLayerZstd))
+			Expect(descriptor.Digest).To(Equal(digestpkg.FromBytes(output.Bytes()).String()))
+			Expect(descriptor.DiffID).To(Equal(digestpkg.FromString("layer-contents").String()))
+		})
+
+		It("emits metrics for exporting", func() {
+			_, err := exporter.ExportDiff(logger, "some-id", groot.CompressionGzip, idMappings, output)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeMetricsEmitter.TryEmitDurationFromCallCount()).To(Equal(1))
+			_, name, start := fakeMetricsEmitter.TryEmitDurationFromArgsForCall(0)
+			Expect(name).To(Equal(groot.MetricImageExportTime))
+			Expect(start).NotTo(BeZero())
+		})
+
+		Context("when the compression is not supported", func() {
+			It("returns an error", func() {
+				_, err := exporter.ExportDiff(logger, "some-id", "bzip2", idMappings, output)
+				Expect(err).To(MatchError(fmt.Sprintf("compression `bzip2` is not supported, use `%s` or `%s`", groot.CompressionGzip, groot.CompressionZstd)))
+				Expect(fakeImageManager.ExportDiffCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when exporting the image diff fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.ExportDiffStub = nil
+				fakeImageManager.ExportDiffReturns(errors.New("image not found: some-id"))
+			})
+
+			It("returns an error", func() {
+				_, err := exporter.ExportDiff(logger, "some-id", groot.CompressionGzip, idMappings, output)
+				Expect(err).To(MatchError("image not found: some-id"))
+			})
+		})
+	})
+
+	Describe("ExportImage", func() {
+		var layoutPath string
+
+		readBlob := func(digest digestpkg.Digest, content interface{}) {
+			blob, err := os.ReadFile(filepath.Join(layoutPath, "blobs", "sha256", digest.Encoded()))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digestpkg.FromBytes(blob)).To(Equal(digest))
+			Expect(json.Unmarshal(blob, content)).To(Succeed())
+		}
+
+		readLayer := func(digest digestpkg.Digest) string {
+			blob, err := os.Open(filepath.Join(layoutPath, "blobs", "sha256", digest.Encoded()))
+			Expect(err).NotTo(HaveOccurred())
+			defer blob.Close()
+			gzipReader, err := gzip.NewReader(blob)
+			Expect(err).NotTo(HaveOccurred())
+			contents, err := io.ReadAll(gzipReader)
+			Expect(err).NotTo(HaveOccurred())
+			return string(contents)
+		}
+
+		BeforeEach(func() {
+			var err error
+			layoutPath, err = os.MkdirTemp("", "oci-layout")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeDependencyManager.DependenciesReturns([]string{"chain-1", "chain-2"}, nil)
+			fakeImageManager.ExportVolumeStub = func(_ lager.Logger, _, volumeID string, _ groot.IDMappings, w io.Writer) error {
+				_, err := w.Write([]byte("volume-" + volumeID))
+				return err
+			}
+			fakeImageManager.BaseImageReturns(specsv1.Image{
+				Author: "Groot",
+				Config: specsv1.ImageConfig{Env: []string{"HELLO=world"}},
+			}, nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(layoutPath)).To(Succeed())
+		})
+
+		It("exports the image base volumes and diff with the given mappings", func() {
+			_, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "", idMappings)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDependencyManager.DependenciesArgsForCall(0)).To(Equal("image:some-id"))
+			Expect(fakeImageManager.ExportVolumeCallCount()).To(Equal(2))
+			_, id, volumeID, mappings, _ := fakeImageManager.ExportVolumeArgsForCall(0)
+			Expect(id).To(Equal("some-id"))
+			Expect(volumeID).To(Equal("chain-1"))
+			Expect(mappings).To(Equal(idMappings))
+			_, _, volumeID, _, _ = fakeImageManager.ExportVolumeArgsForCall(1)
+			Expect(volumeID).To(Equal("chain-2"))
+
+			Expect(fakeImageManager.ExportDiffCallCount()).To(Equal(1))
+		})
+
+		It("writes an image layout with the volumes and the diff as layers", func() {
+			manifestDescriptor, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "", idMappings)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(filepath.Join(layoutPath, "oci-layout"))).To(MatchJSON(`{"imageLayoutVersion":"1.0.0"}`))
+
+			var index specsv1.Index
+			indexBytes, err := os.ReadFile(filepath.Join(layoutPath, "index.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(indexBytes, &index)).To(Succeed())
+			Expect(index.Manifests).To(Equal([]specsv1.Descriptor{manifestDescriptor}))
+
+			var manifest specsv1.Manifest
+			readBlob(manifestDescriptor.Digest, &manifest)
+			Expect(manifest.MediaType).To(Equal(specsv1.MediaTypeImageManifest))
+			Expect(manifest.Layers).To(HaveLen(3))
+			Expect(readLayer(manifest.Layers[0].Digest)).To(Equal("volume-chain-1"))
+			Expect(readLayer(manifest.Layers[1].Digest)).To(Equal("volume-chain-2"))
+			Expect(readLayer(manifest.Layers[2].Digest)).To(Equal("layer-contents"))
+			for _, layer := range manifest.Layers {
+				Expect(layer.MediaType).To(Equal(specsv1.MediaTypeImageLayerGzip))
+			}
+
+			var config specsv1.Image
+			readBlob(manifest.Config.Digest, &config)
+			Expect(config.Author).To(Equal("Groot"))
+			Expect(config.Config.Env).To(ConsistOf("HELLO=world"))
+			Expect(config.OS).To(Equal("linux"))
+			Expect(config.RootFS.DiffIDs).To(Equal([]digestpkg.Digest{
+				digestpkg.FromString("volume-chain-1"),
+				digestpkg.FromString("volume-chain-2"),
+				digestpkg.FromString("layer-contents"),
+			}))
+		})
+
+		It("doesn't leave temporary blobs behind", func() {
+			_, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "", idMappings)
+			Expect(err).NotTo(HaveOccurred())
+
+			blobs, err := os.ReadDir(filepath.Join(layoutPath, "blobs", "sha256"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blobs).To(HaveLen(5))
+		})
+
+		Context("when a tag is given", func() {
+			It("tags the image", func() {
+				manifestDescriptor, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "debug", idMappings)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(manifestDescriptor.Annotations).To(HaveKeyWithValue(specsv1.AnnotationRefName, "debug"))
+			})
+		})
+
+		Context("when the path already contains an image layout", func() {
+			BeforeEach(func() {
+				Expect(os.WriteFile(filepath.Join(layoutPath, "oci-layout"), []byte("{}"), 0644)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				_, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "", idMappings)
+				Expect(err).To(MatchError(ContainSubstring("already contains an image layout")))
+				Expect(fakeImageManager.ExportDiffCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the compression is not supported", func() {
+			It("returns an error", func() {
+				_, err := exporter.ExportImage(logger, "some-id", "bzip2", layoutPath, "", idMappings)
+				Expect(err).To(MatchError(ContainSubstring("compression `bzip2` is not supported")))
+			})
+		})
+
+		Context("when fetching the image dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.DependenciesReturns(nil, errors.New("image `some-id` not found"))
+			})
+
+			It("returns an error", func() {
+				_, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "", idMappings)
+				Expect(err).To(MatchError(ContainSubstring("image `some-id` not found")))
+			})
+		})
+
+		Context("when exporting a volume fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.ExportVolumeStub = nil
+				fakeImageManager.ExportVolumeReturns(errors.New("volume does not exist"))
+			})
+
+			It("returns an error and doesn't write the layout marker", func() {
+				_, err := exporter.ExportImage(logger, "some-id", groot.CompressionGzip, layoutPath, "", idMappings)
+				Expect(err).To(MatchError(ContainSubstring("volume does not exist")))
+				Expect(filepath.Join(layoutPath, "oci-layout")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+})
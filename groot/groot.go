@@ -12,10 +12,12 @@ import (
 const (
 	GlobalLockKey                      = "global-groot-lock"
 	GCLockKey                          = "groot-gc-lock"
+	ImageLockKeyFormat                 = "image-%s-lock"
 	MetricImageCreationTime            = "ImageCreationTime"
 	MetricImageDeletionTime            = "ImageDeletionTime"
 	MetricImageStatsTime               = "ImageStatsTime"
 	MetricImageCleanTime               = "ImageCleanTime"
+	MetricReclaimedIncompleteSize      = "ReclaimedIncompleteLayersSize"
 	MetricDiskCachePercentage          = "DiskCachePercentage"
 	MetricDiskCommittedPercentage      = "DiskCommittedPercentage"
 	MetricDiskPurgeableCachePercentage = "DiskPurgeableCachePercentage"
@@ -63,6 +65,13 @@ type BaseImageSpec struct {
 	GIDMappings               []IDMappingSpec
 	OwnerUID                  int
 	OwnerGID                  int
+	ExcludePaths              []string
+	// BaseImageReference and ManifestDigest are recorded in the metadata of
+	// the volumes pulled for the image, and ImageID in the metadata of all the
+	// volumes it uses
+	BaseImageReference string
+	ManifestDigest     string
+	ImageID            string
 }
 
 type LayerInfo struct {
@@ -77,8 +86,9 @@ type LayerInfo struct {
 }
 
 type BaseImageInfo struct {
-	LayerInfos []LayerInfo
-	Config     specsv1.Image
+	LayerInfos     []LayerInfo
+	Config         specsv1.Image
+	ManifestDigest string
 }
 
 type BaseImagePuller interface {
@@ -95,13 +105,35 @@ type ImageSpec struct {
 	BaseImage                 specsv1.Image
 	OwnerUID                  int
 	OwnerGID                  int
+	// UIDMappings and GIDMappings are only set when the image mappings differ
+	// from the store ones, or when the layers are idmapped at mount time
+	UIDMappings []IDMappingSpec
+	GIDMappings []IDMappingSpec
+	// IDMappedMount tells the base volumes are stored unmapped, to be idmapped
+	// with the image mappings when the image is mounted
+	IDMappedMount bool
+	Squash        bool
+	// Labels are user-defined key/value pairs kept with the image
+	Labels map[string]string
+	// BaseImageReference and ManifestDigest identify the base image the image
+	// is created from, and are recorded with it
+	BaseImageReference string
+	ManifestDigest     string
 }
 
 type ImageManager interface {
 	Exists(id string) (bool, error)
 	Create(logger lager.Logger, spec ImageSpec) (ImageInfo, error)
+	Clone(logger lager.Logger, srcID string, spec ImageSpec) (ImageInfo, error)
 	Destroy(logger lager.Logger, id string) error
 	Stats(logger lager.Logger, id string) (VolumeStats, error)
+	Commit(logger lager.Logger, id, parentChainID string) (LayerInfo, specsv1.Image, error)
+	ExportDiff(logger lager.Logger, id string, idMappings IDMappings, w io.Writer) error
+	ExportVolume(logger lager.Logger, id, volumeID string, idMappings IDMappings, w io.Writer) error
+	BaseImage(id string) (specsv1.Image, error)
+	Resize(logger lager.Logger, id string, diskLimit int64, exclusiveDiskLimit bool) error
+	Inspect(logger lager.Logger, id string) (ImageInspection, error)
+	Remount(logger lager.Logger, id string, baseVolumeIDs []string) (bool, error)
 }
 
 type RootFSConfigurer interface {
@@ -111,12 +143,18 @@ type RootFSConfigurer interface {
 type DependencyManager interface {
 	Register(id string, chainIDs []string) error
 	Deregister(id string) error
+	Dependencies(id string) ([]string, error)
+	RegisteredIDs() ([]string, error)
 }
 
 type GarbageCollector interface {
 	UnusedVolumes(logger lager.Logger) ([]string, error)
 	MarkUnused(logger lager.Logger, unusedVolumes []string) error
 	Collect(logger lager.Logger) error
+	LeastRecentlyUsed(logger lager.Logger, volumeIDs []string, bytes int64) ([]string, error)
+	CleanCandidates(logger lager.Logger, volumeIDs []string) ([]CleanCandidate, error)
+	StaleIncompleteVolumes(logger lager.Logger, gracePeriod time.Duration) ([]string, error)
+	ReclaimIncompleteVolumes(logger lager.Logger, gracePeriod time.Duration) (int64, error)
 }
 
 type StoreMeasurer interface {
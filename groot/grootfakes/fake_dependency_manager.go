@@ -8,6 +8,19 @@ import (
 )
 
 type FakeDependencyManager struct {
+	DependenciesStub        func(string) ([]string, error)
+	dependenciesMutex       sync.RWMutex
+	dependenciesArgsForCall []struct {
+		arg1 string
+	}
+	dependenciesReturns struct {
+		result1 []string
+		result2 error
+	}
+	dependenciesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	DeregisterStub        func(string) error
 	deregisterMutex       sync.RWMutex
 	deregisterArgsForCall []struct {
@@ -31,10 +44,86 @@ type FakeDependencyManager struct {
 	registerReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RegisteredIDsStub        func() ([]string, error)
+	registeredIDsMutex       sync.RWMutex
+	registeredIDsArgsForCall []struct {
+	}
+	registeredIDsReturns struct {
+		result1 []string
+		result2 error
+	}
+	registeredIDsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeDependencyManager) Dependencies(arg1 string) ([]string, error) {
+	fake.dependenciesMutex.Lock()
+	ret, specificReturn := fake.dependenciesReturnsOnCall[len(fake.dependenciesArgsForCall)]
+	fake.dependenciesArgsForCall = append(fake.dependenciesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DependenciesStub
+	fakeReturns := fake.dependenciesReturns
+	fake.recordInvocation("Dependencies", []interface{}{arg1})
+	fake.dependenciesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDependencyManager) DependenciesCallCount() int {
+	fake.dependenciesMutex.RLock()
+	defer fake.dependenciesMutex.RUnlock()
+	return len(fake.dependenciesArgsForCall)
+}
+
+func (fake *FakeDependencyManager) DependenciesCalls(stub func(string) ([]string, error)) {
+	fake.dependenciesMutex.Lock()
+	defer fake.dependenciesMutex.Unlock()
+	fake.DependenciesStub = stub
+}
+
+func (fake *FakeDependencyManager) DependenciesArgsForCall(i int) string {
+	fake.dependenciesMutex.RLock()
+	defer fake.dependenciesMutex.RUnlock()
+	argsForCall := fake.dependenciesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDependencyManager) DependenciesReturns(result1 []string, result2 error) {
+	fake.dependenciesMutex.Lock()
+	defer fake.dependenciesMutex.Unlock()
+	fake.DependenciesStub = nil
+	fake.dependenciesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDependencyManager) DependenciesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.dependenciesMutex.Lock()
+	defer fake.dependenciesMutex.Unlock()
+	fake.DependenciesStub = nil
+	if fake.dependenciesReturnsOnCall == nil {
+		fake.dependenciesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.dependenciesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDependencyManager) Deregister(arg1 string) error {
 	fake.deregisterMutex.Lock()
 	ret, specificReturn := fake.deregisterReturnsOnCall[len(fake.deregisterArgsForCall)]
@@ -163,13 +252,73 @@ func (fake *FakeDependencyManager) RegisterReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeDependencyManager) RegisteredIDs() ([]string, error) {
+	fake.registeredIDsMutex.Lock()
+	ret, specificReturn := fake.registeredIDsReturnsOnCall[len(fake.registeredIDsArgsForCall)]
+	fake.registeredIDsArgsForCall = append(fake.registeredIDsArgsForCall, struct {
+	}{})
+	stub := fake.RegisteredIDsStub
+	fakeReturns := fake.registeredIDsReturns
+	fake.recordInvocation("RegisteredIDs", []interface{}{})
+	fake.registeredIDsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsCallCount() int {
+	fake.registeredIDsMutex.RLock()
+	defer fake.registeredIDsMutex.RUnlock()
+	return len(fake.registeredIDsArgsForCall)
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsCalls(stub func() ([]string, error)) {
+	fake.registeredIDsMutex.Lock()
+	defer fake.registeredIDsMutex.Unlock()
+	fake.RegisteredIDsStub = stub
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsReturns(result1 []string, result2 error) {
+	fake.registeredIDsMutex.Lock()
+	defer fake.registeredIDsMutex.Unlock()
+	fake.RegisteredIDsStub = nil
+	fake.registeredIDsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.registeredIDsMutex.Lock()
+	defer fake.registeredIDsMutex.Unlock()
+	fake.RegisteredIDsStub = nil
+	if fake.registeredIDsReturnsOnCall == nil {
+		fake.registeredIDsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.registeredIDsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDependencyManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.dependenciesMutex.RLock()
+	defer fake.dependenciesMutex.RUnlock()
 	fake.deregisterMutex.RLock()
 	defer fake.deregisterMutex.RUnlock()
 	fake.registerMutex.RLock()
 	defer fake.registerMutex.RUnlock()
+	fake.registeredIDsMutex.RLock()
+	defer fake.registeredIDsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -3,12 +3,27 @@ package grootfakes
 
 import (
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/groot"
-	"code.cloudfoundry.org/lager/v3"
+	lager "code.cloudfoundry.org/lager/v3"
 )
 
 type FakeGarbageCollector struct {
+	CleanCandidatesStub        func(lager.Logger, []string) ([]groot.CleanCandidate, error)
+	cleanCandidatesMutex       sync.RWMutex
+	cleanCandidatesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 []string
+	}
+	cleanCandidatesReturns struct {
+		result1 []groot.CleanCandidate
+		result2 error
+	}
+	cleanCandidatesReturnsOnCall map[int]struct {
+		result1 []groot.CleanCandidate
+		result2 error
+	}
 	CollectStub        func(lager.Logger) error
 	collectMutex       sync.RWMutex
 	collectArgsForCall []struct {
@@ -20,6 +35,21 @@ type FakeGarbageCollector struct {
 	collectReturnsOnCall map[int]struct {
 		result1 error
 	}
+	LeastRecentlyUsedStub        func(lager.Logger, []string, int64) ([]string, error)
+	leastRecentlyUsedMutex       sync.RWMutex
+	leastRecentlyUsedArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 []string
+		arg3 int64
+	}
+	leastRecentlyUsedReturns struct {
+		result1 []string
+		result2 error
+	}
+	leastRecentlyUsedReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	MarkUnusedStub        func(lager.Logger, []string) error
 	markUnusedMutex       sync.RWMutex
 	markUnusedArgsForCall []struct {
@@ -32,6 +62,34 @@ type FakeGarbageCollector struct {
 	markUnusedReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ReclaimIncompleteVolumesStub        func(lager.Logger, time.Duration) (int64, error)
+	reclaimIncompleteVolumesMutex       sync.RWMutex
+	reclaimIncompleteVolumesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 time.Duration
+	}
+	reclaimIncompleteVolumesReturns struct {
+		result1 int64
+		result2 error
+	}
+	reclaimIncompleteVolumesReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	StaleIncompleteVolumesStub        func(lager.Logger, time.Duration) ([]string, error)
+	staleIncompleteVolumesMutex       sync.RWMutex
+	staleIncompleteVolumesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 time.Duration
+	}
+	staleIncompleteVolumesReturns struct {
+		result1 []string
+		result2 error
+	}
+	staleIncompleteVolumesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	UnusedVolumesStub        func(lager.Logger) ([]string, error)
 	unusedVolumesMutex       sync.RWMutex
 	unusedVolumesArgsForCall []struct {
@@ -49,6 +107,76 @@ type FakeGarbageCollector struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeGarbageCollector) CleanCandidates(arg1 lager.Logger, arg2 []string) ([]groot.CleanCandidate, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.cleanCandidatesMutex.Lock()
+	ret, specificReturn := fake.cleanCandidatesReturnsOnCall[len(fake.cleanCandidatesArgsForCall)]
+	fake.cleanCandidatesArgsForCall = append(fake.cleanCandidatesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.CleanCandidatesStub
+	fakeReturns := fake.cleanCandidatesReturns
+	fake.recordInvocation("CleanCandidates", []interface{}{arg1, arg2Copy})
+	fake.cleanCandidatesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGarbageCollector) CleanCandidatesCallCount() int {
+	fake.cleanCandidatesMutex.RLock()
+	defer fake.cleanCandidatesMutex.RUnlock()
+	return len(fake.cleanCandidatesArgsForCall)
+}
+
+func (fake *FakeGarbageCollector) CleanCandidatesCalls(stub func(lager.Logger, []string) ([]groot.CleanCandidate, error)) {
+	fake.cleanCandidatesMutex.Lock()
+	defer fake.cleanCandidatesMutex.Unlock()
+	fake.CleanCandidatesStub = stub
+}
+
+func (fake *FakeGarbageCollector) CleanCandidatesArgsForCall(i int) (lager.Logger, []string) {
+	fake.cleanCandidatesMutex.RLock()
+	defer fake.cleanCandidatesMutex.RUnlock()
+	argsForCall := fake.cleanCandidatesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGarbageCollector) CleanCandidatesReturns(result1 []groot.CleanCandidate, result2 error) {
+	fake.cleanCandidatesMutex.Lock()
+	defer fake.cleanCandidatesMutex.Unlock()
+	fake.CleanCandidatesStub = nil
+	fake.cleanCandidatesReturns = struct {
+		result1 []groot.CleanCandidate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGarbageCollector) CleanCandidatesReturnsOnCall(i int, result1 []groot.CleanCandidate, result2 error) {
+	fake.cleanCandidatesMutex.Lock()
+	defer fake.cleanCandidatesMutex.Unlock()
+	fake.CleanCandidatesStub = nil
+	if fake.cleanCandidatesReturnsOnCall == nil {
+		fake.cleanCandidatesReturnsOnCall = make(map[int]struct {
+			result1 []groot.CleanCandidate
+			result2 error
+		})
+	}
+	fake.cleanCandidatesReturnsOnCall[i] = struct {
+		result1 []groot.CleanCandidate
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGarbageCollector) Collect(arg1 lager.Logger) error {
 	fake.collectMutex.Lock()
 	ret, specificReturn := fake.collectReturnsOnCall[len(fake.collectArgsForCall)]
@@ -110,6 +238,77 @@ func (fake *FakeGarbageCollector) CollectReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeGarbageCollector) LeastRecentlyUsed(arg1 lager.Logger, arg2 []string, arg3 int64) ([]string, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.leastRecentlyUsedMutex.Lock()
+	ret, specificReturn := fake.leastRecentlyUsedReturnsOnCall[len(fake.leastRecentlyUsedArgsForCall)]
+	fake.leastRecentlyUsedArgsForCall = append(fake.leastRecentlyUsedArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []string
+		arg3 int64
+	}{arg1, arg2Copy, arg3})
+	stub := fake.LeastRecentlyUsedStub
+	fakeReturns := fake.leastRecentlyUsedReturns
+	fake.recordInvocation("LeastRecentlyUsed", []interface{}{arg1, arg2Copy, arg3})
+	fake.leastRecentlyUsedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGarbageCollector) LeastRecentlyUsedCallCount() int {
+	fake.leastRecentlyUsedMutex.RLock()
+	defer fake.leastRecentlyUsedMutex.RUnlock()
+	return len(fake.leastRecentlyUsedArgsForCall)
+}
+
+func (fake *FakeGarbageCollector) LeastRecentlyUsedCalls(stub func(lager.Logger, []string, int64) ([]string, error)) {
+	fake.leastRecentlyUsedMutex.Lock()
+	defer fake.leastRecentlyUsedMutex.Unlock()
+	fake.LeastRecentlyUsedStub = stub
+}
+
+func (fake *FakeGarbageCollector) LeastRecentlyUsedArgsForCall(i int) (lager.Logger, []string, int64) {
+	fake.leastRecentlyUsedMutex.RLock()
+	defer fake.leastRecentlyUsedMutex.RUnlock()
+	argsForCall := fake.leastRecentlyUsedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGarbageCollector) LeastRecentlyUsedReturns(result1 []string, result2 error) {
+	fake.leastRecentlyUsedMutex.Lock()
+	defer fake.leastRecentlyUsedMutex.Unlock()
+	fake.LeastRecentlyUsedStub = nil
+	fake.leastRecentlyUsedReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGarbageCollector) LeastRecentlyUsedReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.leastRecentlyUsedMutex.Lock()
+	defer fake.leastRecentlyUsedMutex.Unlock()
+	fake.LeastRecentlyUsedStub = nil
+	if fake.leastRecentlyUsedReturnsOnCall == nil {
+		fake.leastRecentlyUsedReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.leastRecentlyUsedReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGarbageCollector) MarkUnused(arg1 lager.Logger, arg2 []string) error {
 	var arg2Copy []string
 	if arg2 != nil {
@@ -177,6 +376,136 @@ func (fake *FakeGarbageCollector) MarkUnusedReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeGarbageCollector) ReclaimIncompleteVolumes(arg1 lager.Logger, arg2 time.Duration) (int64, error) {
+	fake.reclaimIncompleteVolumesMutex.Lock()
+	ret, specificReturn := fake.reclaimIncompleteVolumesReturnsOnCall[len(fake.reclaimIncompleteVolumesArgsForCall)]
+	fake.reclaimIncompleteVolumesArgsForCall = append(fake.reclaimIncompleteVolumesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 time.Duration
+	}{arg1, arg2})
+	stub := fake.ReclaimIncompleteVolumesStub
+	fakeReturns := fake.reclaimIncompleteVolumesReturns
+	fake.recordInvocation("ReclaimIncompleteVolumes", []interface{}{arg1, arg2})
+	fake.reclaimIncompleteVolumesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGarbageCollector) ReclaimIncompleteVolumesCallCount() int {
+	fake.reclaimIncompleteVolumesMutex.RLock()
+	defer fake.reclaimIncompleteVolumesMutex.RUnlock()
+	return len(fake.reclaimIncompleteVolumesArgsForCall)
+}
+
+func (fake *FakeGarbageCollector) ReclaimIncompleteVolumesCalls(stub func(lager.Logger, time.Duration) (int64, error)) {
+	fake.reclaimIncompleteVolumesMutex.Lock()
+	defer fake.reclaimIncompleteVolumesMutex.Unlock()
+	fake.ReclaimIncompleteVolumesStub = stub
+}
+
+func (fake *FakeGarbageCollector) ReclaimIncompleteVolumesArgsForCall(i int) (lager.Logger, time.Duration) {
+	fake.reclaimIncompleteVolumesMutex.RLock()
+	defer fake.reclaimIncompleteVolumesMutex.RUnlock()
+	argsForCall := fake.reclaimIncompleteVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGarbageCollector) ReclaimIncompleteVolumesReturns(result1 int64, result2 error) {
+	fake.reclaimIncompleteVolumesMutex.Lock()
+	defer fake.reclaimIncompleteVolumesMutex.Unlock()
+	fake.ReclaimIncompleteVolumesStub = nil
+	fake.reclaimIncompleteVolumesReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGarbageCollector) ReclaimIncompleteVolumesReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.reclaimIncompleteVolumesMutex.Lock()
+	defer fake.reclaimIncompleteVolumesMutex.Unlock()
+	fake.ReclaimIncompleteVolumesStub = nil
+	if fake.reclaimIncompleteVolumesReturnsOnCall == nil {
+		fake.reclaimIncompleteVolumesReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.reclaimIncompleteVolumesReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGarbageCollector) StaleIncompleteVolumes(arg1 lager.Logger, arg2 time.Duration) ([]string, error) {
+	fake.staleIncompleteVolumesMutex.Lock()
+	ret, specificReturn := fake.staleIncompleteVolumesReturnsOnCall[len(fake.staleIncompleteVolumesArgsForCall)]
+	fake.staleIncompleteVolumesArgsForCall = append(fake.staleIncompleteVolumesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 time.Duration
+	}{arg1, arg2})
+	stub := fake.StaleIncompleteVolumesStub
+	fakeReturns := fake.staleIncompleteVolumesReturns
+	fake.recordInvocation("StaleIncompleteVolumes", []interface{}{arg1, arg2})
+	fake.staleIncompleteVolumesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGarbageCollector) StaleIncompleteVolumesCallCount() int {
+	fake.staleIncompleteVolumesMutex.RLock()
+	defer fake.staleIncompleteVolumesMutex.RUnlock()
+	return len(fake.staleIncompleteVolumesArgsForCall)
+}
+
+func (fake *FakeGarbageCollector) StaleIncompleteVolumesCalls(stub func(lager.Logger, time.Duration) ([]string, error)) {
+	fake.staleIncompleteVolumesMutex.Lock()
+	defer fake.staleIncompleteVolumesMutex.Unlock()
+	fake.StaleIncompleteVolumesStub = stub
+}
+
+func (fake *FakeGarbageCollector) StaleIncompleteVolumesArgsForCall(i int) (lager.Logger, time.Duration) {
+	fake.staleIncompleteVolumesMutex.RLock()
+	defer fake.staleIncompleteVolumesMutex.RUnlock()
+	argsForCall := fake.staleIncompleteVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGarbageCollector) StaleIncompleteVolumesReturns(result1 []string, result2 error) {
+	fake.staleIncompleteVolumesMutex.Lock()
+	defer fake.staleIncompleteVolumesMutex.Unlock()
+	fake.StaleIncompleteVolumesStub = nil
+	fake.staleIncompleteVolumesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGarbageCollector) StaleIncompleteVolumesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.staleIncompleteVolumesMutex.Lock()
+	defer fake.staleIncompleteVolumesMutex.Unlock()
+	fake.StaleIncompleteVolumesStub = nil
+	if fake.staleIncompleteVolumesReturnsOnCall == nil {
+		fake.staleIncompleteVolumesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.staleIncompleteVolumesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGarbageCollector) UnusedVolumes(arg1 lager.Logger) ([]string, error) {
 	fake.unusedVolumesMutex.Lock()
 	ret, specificReturn := fake.unusedVolumesReturnsOnCall[len(fake.unusedVolumesArgsForCall)]
@@ -244,10 +573,18 @@ func (fake *FakeGarbageCollector) UnusedVolumesReturnsOnCall(i int, result1 []st
 func (fake *FakeGarbageCollector) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.cleanCandidatesMutex.RLock()
+	defer fake.cleanCandidatesMutex.RUnlock()
 	fake.collectMutex.RLock()
 	defer fake.collectMutex.RUnlock()
+	fake.leastRecentlyUsedMutex.RLock()
+	defer fake.leastRecentlyUsedMutex.RUnlock()
 	fake.markUnusedMutex.RLock()
 	defer fake.markUnusedMutex.RUnlock()
+	fake.reclaimIncompleteVolumesMutex.RLock()
+	defer fake.reclaimIncompleteVolumesMutex.RUnlock()
+	fake.staleIncompleteVolumesMutex.RLock()
+	defer fake.staleIncompleteVolumesMutex.RUnlock()
 	fake.unusedVolumesMutex.RLock()
 	defer fake.unusedVolumesMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -2,13 +2,60 @@
 package grootfakes
 
 import (
+	"io"
 	"sync"
 
 	"code.cloudfoundry.org/grootfs/groot"
-	"code.cloudfoundry.org/lager/v3"
+	lager "code.cloudfoundry.org/lager/v3"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type FakeImageManager struct {
+	BaseImageStub        func(string) (v1.Image, error)
+	baseImageMutex       sync.RWMutex
+	baseImageArgsForCall []struct {
+		arg1 string
+	}
+	baseImageReturns struct {
+		result1 v1.Image
+		result2 error
+	}
+	baseImageReturnsOnCall map[int]struct {
+		result1 v1.Image
+		result2 error
+	}
+	CloneStub        func(lager.Logger, string, groot.ImageSpec) (groot.ImageInfo, error)
+	cloneMutex       sync.RWMutex
+	cloneArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 groot.ImageSpec
+	}
+	cloneReturns struct {
+		result1 groot.ImageInfo
+		result2 error
+	}
+	cloneReturnsOnCall map[int]struct {
+		result1 groot.ImageInfo
+		result2 error
+	}
+	CommitStub        func(lager.Logger, string, string) (groot.LayerInfo, v1.Image, error)
+	commitMutex       sync.RWMutex
+	commitArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}
+	commitReturns struct {
+		result1 groot.LayerInfo
+		result2 v1.Image
+		result3 error
+	}
+	commitReturnsOnCall map[int]struct {
+		result1 groot.LayerInfo
+		result2 v1.Image
+		result3 error
+	}
 	CreateStub        func(lager.Logger, groot.ImageSpec) (groot.ImageInfo, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
@@ -48,6 +95,78 @@ type FakeImageManager struct {
 		result1 bool
 		result2 error
 	}
+	ExportDiffStub        func(lager.Logger, string, groot.IDMappings, io.Writer) error
+	exportDiffMutex       sync.RWMutex
+	exportDiffArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 groot.IDMappings
+		arg4 io.Writer
+	}
+	exportDiffReturns struct {
+		result1 error
+	}
+	exportDiffReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ExportVolumeStub        func(lager.Logger, string, string, groot.IDMappings, io.Writer) error
+	exportVolumeMutex       sync.RWMutex
+	exportVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+		arg4 groot.IDMappings
+		arg5 io.Writer
+	}
+	exportVolumeReturns struct {
+		result1 error
+	}
+	exportVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InspectStub        func(lager.Logger, string) (groot.ImageInspection, error)
+	inspectMutex       sync.RWMutex
+	inspectArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	inspectReturns struct {
+		result1 groot.ImageInspection
+		result2 error
+	}
+	inspectReturnsOnCall map[int]struct {
+		result1 groot.ImageInspection
+		result2 error
+	}
+	RemountStub        func(lager.Logger, string, []string) (bool, error)
+	remountMutex       sync.RWMutex
+	remountArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 []string
+	}
+	remountReturns struct {
+		result1 bool
+		result2 error
+	}
+	remountReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ResizeStub        func(lager.Logger, string, int64, bool) error
+	resizeMutex       sync.RWMutex
+	resizeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 int64
+		arg4 bool
+	}
+	resizeReturns struct {
+		result1 error
+	}
+	resizeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StatsStub        func(lager.Logger, string) (groot.VolumeStats, error)
 	statsMutex       sync.RWMutex
 	statsArgsForCall []struct {
@@ -66,6 +185,205 @@ type FakeImageManager struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeImageManager) BaseImage(arg1 string) (v1.Image, error) {
+	fake.baseImageMutex.Lock()
+	ret, specificReturn := fake.baseImageReturnsOnCall[len(fake.baseImageArgsForCall)]
+	fake.baseImageArgsForCall = append(fake.baseImageArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.BaseImageStub
+	fakeReturns := fake.baseImageReturns
+	fake.recordInvocation("BaseImage", []interface{}{arg1})
+	fake.baseImageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeImageManager) BaseImageCallCount() int {
+	fake.baseImageMutex.RLock()
+	defer fake.baseImageMutex.RUnlock()
+	return len(fake.baseImageArgsForCall)
+}
+
+func (fake *FakeImageManager) BaseImageCalls(stub func(string) (v1.Image, error)) {
+	fake.baseImageMutex.Lock()
+	defer fake.baseImageMutex.Unlock()
+	fake.BaseImageStub = stub
+}
+
+func (fake *FakeImageManager) BaseImageArgsForCall(i int) string {
+	fake.baseImageMutex.RLock()
+	defer fake.baseImageMutex.RUnlock()
+	argsForCall := fake.baseImageArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageManager) BaseImageReturns(result1 v1.Image, result2 error) {
+	fake.baseImageMutex.Lock()
+	defer fake.baseImageMutex.Unlock()
+	fake.BaseImageStub = nil
+	fake.baseImageReturns = struct {
+		result1 v1.Image
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) BaseImageReturnsOnCall(i int, result1 v1.Image, result2 error) {
+	fake.baseImageMutex.Lock()
+	defer fake.baseImageMutex.Unlock()
+	fake.BaseImageStub = nil
+	if fake.baseImageReturnsOnCall == nil {
+		fake.baseImageReturnsOnCall = make(map[int]struct {
+			result1 v1.Image
+			result2 error
+		})
+	}
+	fake.baseImageReturnsOnCall[i] = struct {
+		result1 v1.Image
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) Clone(arg1 lager.Logger, arg2 string, arg3 groot.ImageSpec) (groot.ImageInfo, error) {
+	fake.cloneMutex.Lock()
+	ret, specificReturn := fake.cloneReturnsOnCall[len(fake.cloneArgsForCall)]
+	fake.cloneArgsForCall = append(fake.cloneArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 groot.ImageSpec
+	}{arg1, arg2, arg3})
+	stub := fake.CloneStub
+	fakeReturns := fake.cloneReturns
+	fake.recordInvocation("Clone", []interface{}{arg1, arg2, arg3})
+	fake.cloneMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeImageManager) CloneCallCount() int {
+	fake.cloneMutex.RLock()
+	defer fake.cloneMutex.RUnlock()
+	return len(fake.cloneArgsForCall)
+}
+
+func (fake *FakeImageManager) CloneCalls(stub func(lager.Logger, string, groot.ImageSpec) (groot.ImageInfo, error)) {
+	fake.cloneMutex.Lock()
+	defer fake.cloneMutex.Unlock()
+	fake.CloneStub = stub
+}
+
+func (fake *FakeImageManager) CloneArgsForCall(i int) (lager.Logger, string, groot.ImageSpec) {
+	fake.cloneMutex.RLock()
+	defer fake.cloneMutex.RUnlock()
+	argsForCall := fake.cloneArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeImageManager) CloneReturns(result1 groot.ImageInfo, result2 error) {
+	fake.cloneMutex.Lock()
+	defer fake.cloneMutex.Unlock()
+	fake.CloneStub = nil
+	fake.cloneReturns = struct {
+		result1 groot.ImageInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) CloneReturnsOnCall(i int, result1 groot.ImageInfo, result2 error) {
+	fake.cloneMutex.Lock()
+	defer fake.cloneMutex.Unlock()
+	fake.CloneStub = nil
+	if fake.cloneReturnsOnCall == nil {
+		fake.cloneReturnsOnCall = make(map[int]struct {
+			result1 groot.ImageInfo
+			result2 error
+		})
+	}
+	fake.cloneReturnsOnCall[i] = struct {
+		result1 groot.ImageInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) Commit(arg1 lager.Logger, arg2 string, arg3 string) (groot.LayerInfo, v1.Image, error) {
+	fake.commitMutex.Lock()
+	ret, specificReturn := fake.commitReturnsOnCall[len(fake.commitArgsForCall)]
+	fake.commitArgsForCall = append(fake.commitArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.CommitStub
+	fakeReturns := fake.commitReturns
+	fake.recordInvocation("Commit", []interface{}{arg1, arg2, arg3})
+	fake.commitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeImageManager) CommitCallCount() int {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	return len(fake.commitArgsForCall)
+}
+
+func (fake *FakeImageManager) CommitCalls(stub func(lager.Logger, string, string) (groot.LayerInfo, v1.Image, error)) {
+	fake.commitMutex.Lock()
+	defer fake.commitMutex.Unlock()
+	fake.CommitStub = stub
+}
+
+func (fake *FakeImageManager) CommitArgsForCall(i int) (lager.Logger, string, string) {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	argsForCall := fake.commitArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeImageManager) CommitReturns(result1 groot.LayerInfo, result2 v1.Image, result3 error) {
+	fake.commitMutex.Lock()
+	defer fake.commitMutex.Unlock()
+	fake.CommitStub = nil
+	fake.commitReturns = struct {
+		result1 groot.LayerInfo
+		result2 v1.Image
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeImageManager) CommitReturnsOnCall(i int, result1 groot.LayerInfo, result2 v1.Image, result3 error) {
+	fake.commitMutex.Lock()
+	defer fake.commitMutex.Unlock()
+	fake.CommitStub = nil
+	if fake.commitReturnsOnCall == nil {
+		fake.commitReturnsOnCall = make(map[int]struct {
+			result1 groot.LayerInfo
+			result2 v1.Image
+			result3 error
+		})
+	}
+	fake.commitReturnsOnCall[i] = struct {
+		result1 groot.LayerInfo
+		result2 v1.Image
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeImageManager) Create(arg1 lager.Logger, arg2 groot.ImageSpec) (groot.ImageInfo, error) {
 	fake.createMutex.Lock()
 	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
@@ -257,6 +575,335 @@ func (fake *FakeImageManager) ExistsReturnsOnCall(i int, result1 bool, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeImageManager) ExportDiff(arg1 lager.Logger, arg2 string, arg3 groot.IDMappings, arg4 io.Writer) error {
+	fake.exportDiffMutex.Lock()
+	ret, specificReturn := fake.exportDiffReturnsOnCall[len(fake.exportDiffArgsForCall)]
+	fake.exportDiffArgsForCall = append(fake.exportDiffArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 groot.IDMappings
+		arg4 io.Writer
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ExportDiffStub
+	fakeReturns := fake.exportDiffReturns
+	fake.recordInvocation("ExportDiff", []interface{}{arg1, arg2, arg3, arg4})
+	fake.exportDiffMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageManager) ExportDiffCallCount() int {
+	fake.exportDiffMutex.RLock()
+	defer fake.exportDiffMutex.RUnlock()
+	return len(fake.exportDiffArgsForCall)
+}
+
+func (fake *FakeImageManager) ExportDiffCalls(stub func(lager.Logger, string, groot.IDMappings, io.Writer) error) {
+	fake.exportDiffMutex.Lock()
+	defer fake.exportDiffMutex.Unlock()
+	fake.ExportDiffStub = stub
+}
+
+func (fake *FakeImageManager) ExportDiffArgsForCall(i int) (lager.Logger, string, groot.IDMappings, io.Writer) {
+	fake.exportDiffMutex.RLock()
+	defer fake.exportDiffMutex.RUnlock()
+	argsForCall := fake.exportDiffArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageManager) ExportDiffReturns(result1 error) {
+	fake.exportDiffMutex.Lock()
+	defer fake.exportDiffMutex.Unlock()
+	fake.ExportDiffStub = nil
+	fake.exportDiffReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageManager) ExportDiffReturnsOnCall(i int, result1 error) {
+	fake.exportDiffMutex.Lock()
+	defer fake.exportDiffMutex.Unlock()
+	fake.ExportDiffStub = nil
+	if fake.exportDiffReturnsOnCall == nil {
+		fake.exportDiffReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportDiffReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageManager) ExportVolume(arg1 lager.Logger, arg2 string, arg3 string, arg4 groot.IDMappings, arg5 io.Writer) error {
+	fake.exportVolumeMutex.Lock()
+	ret, specificReturn := fake.exportVolumeReturnsOnCall[len(fake.exportVolumeArgsForCall)]
+	fake.exportVolumeArgsForCall = append(fake.exportVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+		arg4 groot.IDMappings
+		arg5 io.Writer
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.ExportVolumeStub
+	fakeReturns := fake.exportVolumeReturns
+	fake.recordInvocation("ExportVolume", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.exportVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageManager) ExportVolumeCallCount() int {
+	fake.exportVolumeMutex.RLock()
+	defer fake.exportVolumeMutex.RUnlock()
+	return len(fake.exportVolumeArgsForCall)
+}
+
+func (fake *FakeImageManager) ExportVolumeCalls(stub func(lager.Logger, string, string, groot.IDMappings, io.Writer) error) {
+	fake.exportVolumeMutex.Lock()
+	defer fake.exportVolumeMutex.Unlock()
+	fake.ExportVolumeStub = stub
+}
+
+func (fake *FakeImageManager) ExportVolumeArgsForCall(i int) (lager.Logger, string, string, groot.IDMappings, io.Writer) {
+	fake.exportVolumeMutex.RLock()
+	defer fake.exportVolumeMutex.RUnlock()
+	argsForCall := fake.exportVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeImageManager) ExportVolumeReturns(result1 error) {
+	fake.exportVolumeMutex.Lock()
+	defer fake.exportVolumeMutex.Unlock()
+	fake.ExportVolumeStub = nil
+	fake.exportVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageManager) ExportVolumeReturnsOnCall(i int, result1 error) {
+	fake.exportVolumeMutex.Lock()
+	defer fake.exportVolumeMutex.Unlock()
+	fake.ExportVolumeStub = nil
+	if fake.exportVolumeReturnsOnCall == nil {
+		fake.exportVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageManager) Inspect(arg1 lager.Logger, arg2 string) (groot.ImageInspection, error) {
+	fake.inspectMutex.Lock()
+	ret, specificReturn := fake.inspectReturnsOnCall[len(fake.inspectArgsForCall)]
+	fake.inspectArgsForCall = append(fake.inspectArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.InspectStub
+	fakeReturns := fake.inspectReturns
+	fake.recordInvocation("Inspect", []interface{}{arg1, arg2})
+	fake.inspectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeImageManager) InspectCallCount() int {
+	fake.inspectMutex.RLock()
+	defer fake.inspectMutex.RUnlock()
+	return len(fake.inspectArgsForCall)
+}
+
+func (fake *FakeImageManager) InspectCalls(stub func(lager.Logger, string) (groot.ImageInspection, error)) {
+	fake.inspectMutex.Lock()
+	defer fake.inspectMutex.Unlock()
+	fake.InspectStub = stub
+}
+
+func (fake *FakeImageManager) InspectArgsForCall(i int) (lager.Logger, string) {
+	fake.inspectMutex.RLock()
+	defer fake.inspectMutex.RUnlock()
+	argsForCall := fake.inspectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeImageManager) InspectReturns(result1 groot.ImageInspection, result2 error) {
+	fake.inspectMutex.Lock()
+	defer fake.inspectMutex.Unlock()
+	fake.InspectStub = nil
+	fake.inspectReturns = struct {
+		result1 groot.ImageInspection
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) InspectReturnsOnCall(i int, result1 groot.ImageInspection, result2 error) {
+	fake.inspectMutex.Lock()
+	defer fake.inspectMutex.Unlock()
+	fake.InspectStub = nil
+	if fake.inspectReturnsOnCall == nil {
+		fake.inspectReturnsOnCall = make(map[int]struct {
+			result1 groot.ImageInspection
+			result2 error
+		})
+	}
+	fake.inspectReturnsOnCall[i] = struct {
+		result1 groot.ImageInspection
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) Remount(arg1 lager.Logger, arg2 string, arg3 []string) (bool, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.remountMutex.Lock()
+	ret, specificReturn := fake.remountReturnsOnCall[len(fake.remountArgsForCall)]
+	fake.remountArgsForCall = append(fake.remountArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 []string
+	}{arg1, arg2, arg3Copy})
+	stub := fake.RemountStub
+	fakeReturns := fake.remountReturns
+	fake.recordInvocation("Remount", []interface{}{arg1, arg2, arg3Copy})
+	fake.remountMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeImageManager) RemountCallCount() int {
+	fake.remountMutex.RLock()
+	defer fake.remountMutex.RUnlock()
+	return len(fake.remountArgsForCall)
+}
+
+func (fake *FakeImageManager) RemountCalls(stub func(lager.Logger, string, []string) (bool, error)) {
+	fake.remountMutex.Lock()
+	defer fake.remountMutex.Unlock()
+	fake.RemountStub = stub
+}
+
+func (fake *FakeImageManager) RemountArgsForCall(i int) (lager.Logger, string, []string) {
+	fake.remountMutex.RLock()
+	defer fake.remountMutex.RUnlock()
+	argsForCall := fake.remountArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeImageManager) RemountReturns(result1 bool, result2 error) {
+	fake.remountMutex.Lock()
+	defer fake.remountMutex.Unlock()
+	fake.RemountStub = nil
+	fake.remountReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) RemountReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.remountMutex.Lock()
+	defer fake.remountMutex.Unlock()
+	fake.RemountStub = nil
+	if fake.remountReturnsOnCall == nil {
+		fake.remountReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.remountReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageManager) Resize(arg1 lager.Logger, arg2 string, arg3 int64, arg4 bool) error {
+	fake.resizeMutex.Lock()
+	ret, specificReturn := fake.resizeReturnsOnCall[len(fake.resizeArgsForCall)]
+	fake.resizeArgsForCall = append(fake.resizeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 int64
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ResizeStub
+	fakeReturns := fake.resizeReturns
+	fake.recordInvocation("Resize", []interface{}{arg1, arg2, arg3, arg4})
+	fake.resizeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeImageManager) ResizeCallCount() int {
+	fake.resizeMutex.RLock()
+	defer fake.resizeMutex.RUnlock()
+	return len(fake.resizeArgsForCall)
+}
+
+func (fake *FakeImageManager) ResizeCalls(stub func(lager.Logger, string, int64, bool) error) {
+	fake.resizeMutex.Lock()
+	defer fake.resizeMutex.Unlock()
+	fake.ResizeStub = stub
+}
+
+func (fake *FakeImageManager) ResizeArgsForCall(i int) (lager.Logger, string, int64, bool) {
+	fake.resizeMutex.RLock()
+	defer fake.resizeMutex.RUnlock()
+	argsForCall := fake.resizeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeImageManager) ResizeReturns(result1 error) {
+	fake.resizeMutex.Lock()
+	defer fake.resizeMutex.Unlock()
+	fake.ResizeStub = nil
+	fake.resizeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImageManager) ResizeReturnsOnCall(i int, result1 error) {
+	fake.resizeMutex.Lock()
+	defer fake.resizeMutex.Unlock()
+	fake.ResizeStub = nil
+	if fake.resizeReturnsOnCall == nil {
+		fake.resizeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resizeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeImageManager) Stats(arg1 lager.Logger, arg2 string) (groot.VolumeStats, error) {
 	fake.statsMutex.Lock()
 	ret, specificReturn := fake.statsReturnsOnCall[len(fake.statsArgsForCall)]
@@ -325,12 +972,28 @@ func (fake *FakeImageManager) StatsReturnsOnCall(i int, result1 groot.VolumeStat
 func (fake *FakeImageManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.baseImageMutex.RLock()
+	defer fake.baseImageMutex.RUnlock()
+	fake.cloneMutex.RLock()
+	defer fake.cloneMutex.RUnlock()
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	fake.existsMutex.RLock()
 	defer fake.existsMutex.RUnlock()
+	fake.exportDiffMutex.RLock()
+	defer fake.exportDiffMutex.RUnlock()
+	fake.exportVolumeMutex.RLock()
+	defer fake.exportVolumeMutex.RUnlock()
+	fake.inspectMutex.RLock()
+	defer fake.inspectMutex.RUnlock()
+	fake.remountMutex.RLock()
+	defer fake.remountMutex.RUnlock()
+	fake.resizeMutex.RLock()
+	defer fake.resizeMutex.RUnlock()
 	fake.statsMutex.RLock()
 	defer fake.statsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
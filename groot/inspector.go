@@ -0,0 +1,105 @@
+package groot
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	errorspkg "github.com/pkg/errors"
+)
+
+// ImageInspection describes an image: its base image config and layers, how
+// it is mounted and limited, and where it comes from when that is known. The
+// disk limit is the quota applied to the image changes, which is less than an
+// inclusive limit by the base image size.
+type ImageInspection struct {
+	ID                 string              `json:"id"`
+	Path               string              `json:"path"`
+	CreatedAt          time.Time           `json:"created_at"`
+	OwnerUID           int                 `json:"owner_uid"`
+	OwnerGID           int                 `json:"owner_gid"`
+	Config             specsv1.ImageConfig `json:"config"`
+	Layers             []LayerInspection   `json:"layers"`
+	Mounted            bool                `json:"mounted"`
+	MountData          string              `json:"mount_data,omitempty"`
+	ProjectID          uint32              `json:"project_id,omitempty"`
+	ExclusiveDiskLimit int64               `json:"exclusive_disk_limit"`
+	SourceImage        string              `json:"source_image,omitempty"`
+	ManifestDigest     string              `json:"manifest_digest,omitempty"`
+	Labels             map[string]string   `json:"labels,omitempty"`
+}
+
+// LayerInspection is a base layer of an image, bottom first
+type LayerInspection struct {
+	ChainID string `json:"chain_id"`
+	Size    int64  `json:"size"`
+}
+
+// VolumeInspection is what the volume metadata tells about a volume
+type VolumeInspection struct {
+	Size           int64
+	SourceImage    string
+	ManifestDigest string
+}
+
+//go:generate counterfeiter . VolumeInspector
+
+type VolumeInspector interface {
+	InspectVolume(logger lager.Logger, id string) (VolumeInspection, error)
+}
+
+type Inspector struct {
+	imageManager      ImageManager
+	dependencyManager DependencyManager
+	volumeInspector   VolumeInspector
+}
+
+func IamInspector(imageManager ImageManager, dependencyManager DependencyManager, volumeInspector VolumeInspector) *Inspector {
+	return &Inspector{
+		imageManager:      imageManager,
+		dependencyManager: dependencyManager,
+		volumeInspector:   volumeInspector,
+	}
+}
+
+// Inspect describes an image. The base layers are the ones registered for the
+// image. The provenance of images created before it was recorded with them is
+// taken from the metadata of the topmost layer that has it.
+func (i *Inspector) Inspect(logger lager.Logger, id string) (ImageInspection, error) {
+	logger = logger.Session("groot-inspecting", lager.Data{"imageID": id})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	inspection, err := i.imageManager.Inspect(logger, id)
+	if err != nil {
+		return ImageInspection{}, err
+	}
+
+	chainIDs, err := i.dependencyManager.Dependencies(fmt.Sprintf(ImageReferenceFormat, id))
+	if err != nil {
+		return ImageInspection{}, errorspkg.Wrap(err, "reading image dependencies")
+	}
+
+	var layersSourceImage, layersManifestDigest string
+	inspection.Layers = []LayerInspection{}
+	for _, chainID := range chainIDs {
+		volume, err := i.volumeInspector.InspectVolume(logger, chainID)
+		if err != nil {
+			logger.Error("inspecting-volume-failed", err, lager.Data{"chainID": chainID})
+		}
+
+		inspection.Layers = append(inspection.Layers, LayerInspection{ChainID: chainID, Size: volume.Size})
+		if volume.SourceImage != "" {
+			layersSourceImage = volume.SourceImage
+			layersManifestDigest = volume.ManifestDigest
+		}
+	}
+
+	if inspection.SourceImage == "" {
+		inspection.SourceImage = layersSourceImage
+		inspection.ManifestDigest = layersManifestDigest
+	}
+
+	return inspection, nil
+}
@@ -0,0 +1,129 @@
+package groot_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inspector", func() {
+	var (
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeVolumeInspector   *grootfakes.FakeVolumeInspector
+		inspector             *groot.Inspector
+		logger                lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeImageManager.InspectReturns(groot.ImageInspection{
+			ID:      "some-id",
+			Config:  specsv1.ImageConfig{User: "alice"},
+			Mounted: true,
+		}, nil)
+
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeDependencyManager.DependenciesReturns([]string{"layer-1", "layer-2"}, nil)
+
+		fakeVolumeInspector = new(grootfakes.FakeVolumeInspector)
+		fakeVolumeInspector.InspectVolumeStub = func(_ lager.Logger, id string) (groot.VolumeInspection, error) {
+			if id == "layer-1" {
+				return groot.VolumeInspection{Size: 1024, SourceImage: "docker:///busybox", ManifestDigest: "sha256:busybox"}, nil
+			}
+			return groot.VolumeInspection{Size: 2048}, nil
+		}
+
+		inspector = groot.IamInspector(fakeImageManager, fakeDependencyManager, fakeVolumeInspector)
+		logger = lagertest.NewTestLogger("inspector")
+	})
+
+	Describe("Inspect", func() {
+		It("describes the image with the image manager", func() {
+			inspection, err := inspector.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeImageManager.InspectCallCount()).To(Equal(1))
+			_, id := fakeImageManager.InspectArgsForCall(0)
+			Expect(id).To(Equal("some-id"))
+			Expect(inspection.Config.User).To(Equal("alice"))
+			Expect(inspection.Mounted).To(BeTrue())
+		})
+
+		It("lists the image layers with their sizes", func() {
+			inspection, err := inspector.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDependencyManager.DependenciesArgsForCall(0)).To(Equal("image:some-id"))
+			Expect(inspection.Layers).To(Equal([]groot.LayerInspection{
+				{ChainID: "layer-1", Size: 1024},
+				{ChainID: "layer-2", Size: 2048},
+			}))
+		})
+
+		It("keeps the provenance recorded with the image", func() {
+			fakeImageManager.InspectReturns(groot.ImageInspection{
+				ID:             "some-id",
+				SourceImage:    "docker:///my-busybox",
+				ManifestDigest: "sha256:my-busybox",
+			}, nil)
+
+			inspection, err := inspector.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inspection.SourceImage).To(Equal("docker:///my-busybox"))
+			Expect(inspection.ManifestDigest).To(Equal("sha256:my-busybox"))
+		})
+
+		Context("when the image has no recorded provenance", func() {
+			It("takes it from the layers metadata", func() {
+				inspection, err := inspector.Inspect(logger, "some-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(inspection.SourceImage).To(Equal("docker:///busybox"))
+				Expect(inspection.ManifestDigest).To(Equal("sha256:busybox"))
+			})
+		})
+
+		Context("when a layer can't be inspected", func() {
+			BeforeEach(func() {
+				fakeVolumeInspector.InspectVolumeStub = nil
+				fakeVolumeInspector.InspectVolumeReturns(groot.VolumeInspection{}, errors.New("no metadata"))
+			})
+
+			It("still lists it", func() {
+				inspection, err := inspector.Inspect(logger, "some-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(inspection.Layers).To(HaveLen(2))
+				Expect(inspection.Layers[0].Size).To(BeZero())
+			})
+		})
+
+		Context("when the image manager fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.InspectReturns(groot.ImageInspection{}, errors.New("image not found: some-id"))
+			})
+
+			It("returns an error", func() {
+				_, err := inspector.Inspect(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("image not found")))
+			})
+		})
+
+		Context("when reading the dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.DependenciesReturns(nil, errors.New("corrupted"))
+			})
+
+			It("returns an error", func() {
+				_, err := inspector.Inspect(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("corrupted")))
+			})
+		})
+	})
+})
@@ -3,17 +3,70 @@ package groot
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/store"
 	"code.cloudfoundry.org/lager/v3"
 	errorspkg "github.com/pkg/errors"
 )
 
+// ImageListing is the summary of an image that list reports. Images that
+// can't be inspected are only described by their ID, path and error.
+type ImageListing struct {
+	ID                 string            `json:"id"`
+	Path               string            `json:"path"`
+	CreatedAt          time.Time         `json:"created_at"`
+	BaseImage          string            `json:"base_image,omitempty"`
+	ExclusiveDiskLimit int64             `json:"exclusive_disk_limit"`
+	DiskUsage          DiskUsage         `json:"disk_usage"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	Error              string            `json:"error,omitempty"`
+}
+
+// ImageFilter selects the images to list. Zero values match every image.
+// Ages are measured from the creation time recorded with the image.
+type ImageFilter struct {
+	IDPrefix  string
+	ChainID   string
+	OlderThan time.Duration
+	NewerThan time.Duration
+	// Labels match images having the labels with the given values, empty
+	// values included
+	Labels map[string]string
+	// LabelKeys match images having the labels, whatever their values
+	LabelKeys []string
+}
+
+// VolumeListing is the summary of a volume that list reports. The reference
+// count is the number of images, refs and pins depending on the volume.
+type VolumeListing struct {
+	ID                  string `json:"id"`
+	Size                int64  `json:"size"`
+	RefCount            int    `json:"ref_count"`
+	MarkedForCollection bool   `json:"marked_for_collection"`
+}
+
+//go:generate counterfeiter . VolumeLister
+
+type VolumeLister interface {
+	VolumeInspector
+	Volumes(logger lager.Logger) ([]string, error)
+}
+
 type Lister struct {
+	imageManager      ImageManager
+	dependencyManager DependencyManager
+	volumeLister      VolumeLister
 }
 
-func IamLister() *Lister {
-	return &Lister{}
+func IamLister(imageManager ImageManager, dependencyManager DependencyManager, volumeLister VolumeLister) *Lister {
+	return &Lister{
+		imageManager:      imageManager,
+		dependencyManager: dependencyManager,
+		volumeLister:      volumeLister,
+	}
 }
 
 func (l *Lister) List(logger lager.Logger, storePath string) ([]string, error) {
@@ -30,6 +83,155 @@ func (l *Lister) List(logger lager.Logger, storePath string) ([]string, error) {
 	return imagePaths, nil
 }
 
+// ListImages describes the images matching the filter, sorted by ID. Images
+// that go away while listing are skipped. Images that can't be inspected are
+// listed with the error, whatever the filter beyond their ID.
+func (l *Lister) ListImages(logger lager.Logger, storePath string, filter ImageFilter) ([]ImageListing, error) {
+	logger = logger.Session("groot-listing-images", lager.Data{"storePath": storePath, "filter": filter})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	imagePaths, err := l.listDirs(filepath.Join(storePath, store.ImageDirName))
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "failed to list store path")
+	}
+	sort.Strings(imagePaths)
+
+	inspector := IamInspector(l.imageManager, l.dependencyManager, l.volumeLister)
+	listings := []ImageListing{}
+	for _, imagePath := range imagePaths {
+		id := filepath.Base(imagePath)
+		if !strings.HasPrefix(id, filter.IDPrefix) {
+			continue
+		}
+
+		inspection, err := inspector.Inspect(logger, id)
+		if err != nil {
+			if exists, existsErr := l.imageManager.Exists(id); existsErr == nil && !exists {
+				logger.Debug("image-went-away", lager.Data{"imageID": id})
+				continue
+			}
+
+			logger.Error("inspecting-image-failed", err, lager.Data{"imageID": id})
+			listings = append(listings, ImageListing{ID: id, Path: imagePath, Error: err.Error()})
+			continue
+		}
+
+		if !filter.matches(inspection) {
+			continue
+		}
+
+		stats, err := l.imageManager.Stats(logger, id)
+		if err != nil {
+			logger.Error("fetching-image-stats-failed", err, lager.Data{"imageID": id})
+		}
+
+		listings = append(listings, ImageListing{
+			ID:                 id,
+			Path:               inspection.Path,
+			CreatedAt:          inspection.CreatedAt,
+			BaseImage:          inspection.SourceImage,
+			ExclusiveDiskLimit: inspection.ExclusiveDiskLimit,
+			DiskUsage:          stats.DiskUsage,
+			Labels:             inspection.Labels,
+		})
+	}
+
+	return listings, nil
+}
+
+func (f ImageFilter) matches(inspection ImageInspection) bool {
+	age := time.Since(inspection.CreatedAt)
+	if f.OlderThan > 0 && age < f.OlderThan {
+		return false
+	}
+	if f.NewerThan > 0 && age >= f.NewerThan {
+		return false
+	}
+
+	for key, value := range f.Labels {
+		imageValue, ok := inspection.Labels[key]
+		if !ok || imageValue != value {
+			return false
+		}
+	}
+	for _, key := range f.LabelKeys {
+		if _, ok := inspection.Labels[key]; !ok {
+			return false
+		}
+	}
+
+	if f.ChainID == "" {
+		return true
+	}
+	for _, layer := range inspection.Layers {
+		if strings.HasPrefix(layer.ChainID, f.ChainID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListVolumes describes the volumes in the store, sorted by ID. Squashed
+// volumes are referenced by whatever depends on the chain they flatten.
+func (l *Lister) ListVolumes(logger lager.Logger) ([]VolumeListing, error) {
+	logger = logger.Session("groot-listing-volumes")
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	refCounts, err := l.volumeRefCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	volumeIDs, err := l.volumeLister.Volumes(logger)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing volumes")
+	}
+	sort.Strings(volumeIDs)
+
+	listings := []VolumeListing{}
+	for _, volumeID := range volumeIDs {
+		var size int64
+		if volume, err := l.volumeLister.InspectVolume(logger, volumeID); err != nil {
+			logger.Debug("inspecting-volume-failed", lager.Data{"volumeID": volumeID, "error": err.Error()})
+		} else {
+			size = volume.Size
+		}
+
+		listings = append(listings, VolumeListing{
+			ID:                  volumeID,
+			Size:                size,
+			RefCount:            refCounts[volumeID],
+			MarkedForCollection: strings.HasPrefix(volumeID, "gc."),
+		})
+	}
+
+	return listings, nil
+}
+
+func (l *Lister) volumeRefCounts() (map[string]int, error) {
+	registeredIDs, err := l.dependencyManager.RegisteredIDs()
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing dependencies")
+	}
+
+	refCounts := map[string]int{}
+	for _, registeredID := range registeredIDs {
+		chainIDs, err := l.dependencyManager.Dependencies(registeredID)
+		if err != nil {
+			return nil, errorspkg.Wrapf(err, "reading dependencies of %s", registeredID)
+		}
+
+		for _, chainID := range chainIDs {
+			refCounts[chainID]++
+			refCounts[SquashedVolumeID(chainID)]++
+		}
+	}
+
+	return refCounts, nil
+}
+
 func (l *Lister) listDirs(path string) ([]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
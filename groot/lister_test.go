@@ -1,10 +1,14 @@
 package groot_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -12,9 +16,12 @@ import (
 
 var _ = Describe("Lister", func() {
 	var (
-		storePath string
-		logger    *lagertest.TestLogger
-		lister    *groot.Lister
+		storePath             string
+		logger                *lagertest.TestLogger
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeVolumeLister      *grootfakes.FakeVolumeLister
+		lister                *groot.Lister
 	)
 
 	BeforeEach(func() {
@@ -26,7 +33,45 @@ var _ = Describe("Lister", func() {
 		Expect(os.MkdirAll(filepath.Join(storePath, "images", "image-1", "too-far"), 0755)).To(Succeed())
 		logger = lagertest.NewTestLogger("iam-lister")
 
-		lister = groot.IamLister()
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeImageManager.InspectStub = func(_ lager.Logger, id string) (groot.ImageInspection, error) {
+			createdAt := time.Now().Add(-time.Minute)
+			if id == "image-0" {
+				createdAt = time.Now().Add(-48 * time.Hour)
+			}
+			labels := map[string]string{"app": "app-1", "tenant": "some-tenant"}
+			if id == "image-0" {
+				labels = map[string]string{"app": "app-0"}
+			}
+			return groot.ImageInspection{
+				ID:                 id,
+				Path:               filepath.Join(storePath, "images", id),
+				CreatedAt:          createdAt,
+				ExclusiveDiskLimit: 4096,
+				Labels:             labels,
+			}, nil
+		}
+		fakeImageManager.StatsReturns(groot.VolumeStats{DiskUsage: groot.DiskUsage{TotalBytesUsed: 2048}}, nil)
+
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeDependencyManager.RegisteredIDsReturns([]string{"image:image-0", "image:image-1", "ref:busybox"}, nil)
+		fakeDependencyManager.DependenciesStub = func(id string) ([]string, error) {
+			if id == "image:image-0" {
+				return []string{"layer-1", "layer-2"}, nil
+			}
+			return []string{"layer-1"}, nil
+		}
+
+		fakeVolumeLister = new(grootfakes.FakeVolumeLister)
+		fakeVolumeLister.VolumesReturns([]string{"layer-2", "layer-1", "gc.layer-0", "layer-2-squashed"}, nil)
+		fakeVolumeLister.InspectVolumeStub = func(_ lager.Logger, id string) (groot.VolumeInspection, error) {
+			if id == "layer-1" {
+				return groot.VolumeInspection{Size: 1024, SourceImage: "docker:///busybox"}, nil
+			}
+			return groot.VolumeInspection{Size: 512}, nil
+		}
+
+		lister = groot.IamLister(fakeImageManager, fakeDependencyManager, fakeVolumeLister)
 	})
 
 	AfterEach(func() {
@@ -51,4 +96,179 @@ var _ = Describe("Lister", func() {
 			})
 		})
 	})
+
+	Describe("ListImages", func() {
+		It("describes the images in the store sorted by id", func() {
+			listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(2))
+
+			Expect(listings[0].ID).To(Equal("image-0"))
+			Expect(listings[0].Path).To(Equal(filepath.Join(storePath, "images", "image-0")))
+			Expect(listings[0].BaseImage).To(Equal("docker:///busybox"))
+			Expect(listings[0].ExclusiveDiskLimit).To(Equal(int64(4096)))
+			Expect(listings[0].DiskUsage.TotalBytesUsed).To(Equal(int64(2048)))
+			Expect(listings[1].ID).To(Equal("image-1"))
+		})
+
+		It("filters by id prefix", func() {
+			listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{IDPrefix: "image-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-1"))
+		})
+
+		It("filters by base chain id", func() {
+			listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{ChainID: "layer-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-0"))
+		})
+
+		It("filters by labels", func() {
+			listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{Labels: map[string]string{"app": "app-0"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-0"))
+			Expect(listings[0].Labels).To(Equal(map[string]string{"app": "app-0"}))
+
+			listings, err = lister.ListImages(logger, storePath, groot.ImageFilter{LabelKeys: []string{"tenant"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-1"))
+
+			listings, err = lister.ListImages(logger, storePath, groot.ImageFilter{Labels: map[string]string{"app": "app-1", "tenant": "other-tenant"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(BeEmpty())
+		})
+
+		It("only matches empty label values with empty filter values", func() {
+			inspect := fakeImageManager.InspectStub
+			fakeImageManager.InspectStub = func(logger lager.Logger, id string) (groot.ImageInspection, error) {
+				inspection, err := inspect(logger, id)
+				if id == "image-0" {
+					inspection.Labels["tenant"] = ""
+				}
+				return inspection, err
+			}
+
+			listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{Labels: map[string]string{"tenant": ""}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-0"))
+
+			listings, err = lister.ListImages(logger, storePath, groot.ImageFilter{LabelKeys: []string{"tenant"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(2))
+		})
+
+		It("filters by age", func() {
+			// the creation time is the recorded one, not the directory one
+			oldTime := time.Now().Add(-72 * time.Hour)
+			Expect(os.Chtimes(filepath.Join(storePath, "images", "image-1"), oldTime, oldTime)).To(Succeed())
+
+			listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{OlderThan: 24 * time.Hour})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-0"))
+
+			listings, err = lister.ListImages(logger, storePath, groot.ImageFilter{NewerThan: time.Hour})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(HaveLen(1))
+			Expect(listings[0].ID).To(Equal("image-1"))
+		})
+
+		Context("when an image can't be inspected", func() {
+			BeforeEach(func() {
+				inspect := fakeImageManager.InspectStub
+				fakeImageManager.InspectStub = func(logger lager.Logger, id string) (groot.ImageInspection, error) {
+					if id == "image-0" {
+						return groot.ImageInspection{}, errors.New("image not found: image-0")
+					}
+					return inspect(logger, id)
+				}
+			})
+
+			Context("because it went away", func() {
+				BeforeEach(func() {
+					fakeImageManager.ExistsReturns(false, nil)
+				})
+
+				It("skips it", func() {
+					listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(listings).To(HaveLen(1))
+					Expect(listings[0].ID).To(Equal("image-1"))
+				})
+			})
+
+			Context("while it is still there", func() {
+				BeforeEach(func() {
+					fakeImageManager.ExistsReturns(true, nil)
+				})
+
+				It("reports it with the error", func() {
+					listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(listings).To(HaveLen(2))
+					Expect(listings[0].ID).To(Equal("image-0"))
+					Expect(listings[0].Path).To(Equal(filepath.Join(storePath, "images", "image-0")))
+					Expect(listings[0].Error).To(ContainSubstring("image not found: image-0"))
+					Expect(listings[1].Error).To(BeEmpty())
+				})
+
+				It("reports it whatever the filter beyond its id", func() {
+					listings, err := lister.ListImages(logger, storePath, groot.ImageFilter{Labels: map[string]string{"app": "app-1"}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(listings).To(HaveLen(2))
+
+					listings, err = lister.ListImages(logger, storePath, groot.ImageFilter{IDPrefix: "image-1"})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(listings).To(HaveLen(1))
+				})
+			})
+		})
+
+		Context("when fails to list store path", func() {
+			It("returns an error", func() {
+				_, err := lister.ListImages(logger, "invalid-store-path", groot.ImageFilter{})
+				Expect(err).To(MatchError(ContainSubstring("failed to list store path")))
+			})
+		})
+	})
+
+	Describe("ListVolumes", func() {
+		It("describes the volumes in the store sorted by id", func() {
+			listings, err := lister.ListVolumes(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listings).To(Equal([]groot.VolumeListing{
+				{ID: "gc.layer-0", Size: 512, RefCount: 0, MarkedForCollection: true},
+				{ID: "layer-1", Size: 1024, RefCount: 3},
+				{ID: "layer-2", Size: 512, RefCount: 1},
+				{ID: "layer-2-squashed", Size: 512, RefCount: 1},
+			}))
+		})
+
+		Context("when reading the dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.RegisteredIDsReturns(nil, errors.New("corrupted"))
+			})
+
+			It("returns an error", func() {
+				_, err := lister.ListVolumes(logger)
+				Expect(err).To(MatchError(ContainSubstring("corrupted")))
+			})
+		})
+
+		Context("when listing the volumes fails", func() {
+			BeforeEach(func() {
+				fakeVolumeLister.VolumesReturns(nil, errors.New("permission denied"))
+			})
+
+			It("returns an error", func() {
+				_, err := lister.ListVolumes(logger)
+				Expect(err).To(MatchError(ContainSubstring("permission denied")))
+			})
+		})
+	})
 })
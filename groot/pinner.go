@@ -0,0 +1,200 @@
+package groot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+const MetricImagePinTime = "ImagePinTime"
+
+type PinSpec struct {
+	BaseImageURL *url.URL
+	ExcludePaths []string
+	// UIDMappings and GIDMappings are the mappings of the images the pinned
+	// volumes are for, as given to create
+	UIDMappings []IDMappingSpec
+	GIDMappings []IDMappingSpec
+	// IDMappedMount pins the unmapped volumes the images mounted with idmapped
+	// mounts use
+	IDMappedMount bool
+	// StoreIDMappings are the store default mappings. The pinned volumes are
+	// the ones images created with the same mappings use.
+	StoreIDMappings IDMappings
+}
+
+// PinListing is a pinned base image, along with the options its volumes were
+// pinned for when they are not the store defaults
+type PinListing struct {
+	BaseImage string `json:"base_image"`
+	Options   string `json:"options,omitempty"`
+}
+
+type Pinner struct {
+	baseImagePuller   BaseImagePuller
+	locksmith         Locksmith
+	dependencyManager DependencyManager
+	metricsEmitter    MetricsEmitter
+}
+
+func IamPinner(
+	baseImagePuller BaseImagePuller, locksmith Locksmith,
+	dependencyManager DependencyManager, metricsEmitter MetricsEmitter) *Pinner {
+	return &Pinner{
+		baseImagePuller:   baseImagePuller,
+		locksmith:         locksmith,
+		dependencyManager: dependencyManager,
+		metricsEmitter:    metricsEmitter,
+	}
+}
+
+// Pin pulls the base image and registers its volumes under the image
+// reference and the options they are for, so that they are never collected
+// until it is unpinned. Pinning it again with the same options moves the pin
+// to the current volumes, while other options get a pin of their own.
+func (p *Pinner) Pin(logger lager.Logger, spec PinSpec) error {
+	defer p.metricsEmitter.TryEmitDurationFrom(logger, MetricImagePinTime, time.Now())
+
+	baseImageRef := spec.BaseImageURL.String()
+	logger = logger.Session("groot-pinning", lager.Data{"baseImage": baseImageRef})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	baseImageInfo, err := p.baseImagePuller.FetchBaseImageInfo(logger)
+	if err != nil {
+		return err
+	}
+	volumeMappings := IDMappings{UIDMappings: spec.UIDMappings, GIDMappings: spec.GIDMappings}
+	if spec.IDMappedMount {
+		volumeMappings = IDMappings{}
+	}
+	baseImageInfo.LayerInfos = withExclusions(baseImageInfo.LayerInfos, spec.ExcludePaths)
+	baseImageInfo.LayerInfos = withIDMappings(baseImageInfo.LayerInfos, volumeMappings, spec.StoreIDMappings)
+
+	ownerUID, ownerGID := parseOwner(volumeMappings.UIDMappings, volumeMappings.GIDMappings)
+	pinRefName := fmt.Sprintf(PinReferenceFormat, baseImageRef)
+	if options := pinOptions(spec.ExcludePaths, volumeMappings, spec.StoreIDMappings); options != "" {
+		pinRefName += " " + options
+	}
+	baseImageSpec := BaseImageSpec{
+		UIDMappings:        volumeMappings.UIDMappings,
+		GIDMappings:        volumeMappings.GIDMappings,
+		OwnerUID:           ownerUID,
+		OwnerGID:           ownerGID,
+		ExcludePaths:       spec.ExcludePaths,
+		BaseImageReference: baseImageRef,
+		ManifestDigest:     baseImageInfo.ManifestDigest,
+		ImageID:            pinRefName,
+	}
+
+	lockFile, err := p.locksmith.Lock(GlobalLockKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := p.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	if err := p.baseImagePuller.Pull(logger, baseImageInfo, baseImageSpec); err != nil {
+		return errorspkg.Wrap(err, "pulling the image")
+	}
+
+	if err := p.dependencyManager.Register(pinRefName, chainIDs(baseImageInfo.LayerInfos)); err != nil {
+		return errorspkg.Wrap(err, "registering pin dependencies")
+	}
+
+	return nil
+}
+
+// Unpin lets the volumes of a pinned base image be collected again, whatever
+// the options they were pinned for
+func (p *Pinner) Unpin(logger lager.Logger, baseImageURL *url.URL) error {
+	baseImageRef := baseImageURL.String()
+	logger = logger.Session("groot-unpinning", lager.Data{"baseImage": baseImageRef})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	registeredIDs, err := p.dependencyManager.RegisteredIDs()
+	if err != nil {
+		return err
+	}
+
+	pinRefName := fmt.Sprintf(PinReferenceFormat, baseImageRef)
+	unpinned := false
+	for _, registeredID := range registeredIDs {
+		if registeredID != pinRefName && !strings.HasPrefix(registeredID, pinRefName+" ") {
+			continue
+		}
+
+		if err := p.dependencyManager.Deregister(registeredID); err != nil {
+			return errorspkg.Wrap(err, "deregistering pin dependencies")
+		}
+		unpinned = true
+	}
+
+	if !unpinned {
+		return errorspkg.Errorf("base image `%s` is not pinned", baseImageRef)
+	}
+
+	return nil
+}
+
+// Pins lists the pinned base images, once for each set of options they were
+// pinned for
+func (p *Pinner) Pins(logger lager.Logger) ([]PinListing, error) {
+	registeredIDs, err := p.dependencyManager.RegisteredIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	pinPrefix := fmt.Sprintf(PinReferenceFormat, "")
+	pins := []PinListing{}
+	for _, registeredID := range registeredIDs {
+		if !strings.HasPrefix(registeredID, pinPrefix) {
+			continue
+		}
+
+		baseImage, options, _ := strings.Cut(strings.TrimPrefix(registeredID, pinPrefix), " ")
+		pins = append(pins, PinListing{BaseImage: baseImage, Options: options})
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		if pins[i].BaseImage != pins[j].BaseImage {
+			return pins[i].BaseImage < pins[j].BaseImage
+		}
+		return pins[i].Options < pins[j].Options
+	})
+
+	return pins, nil
+}
+
+// pinOptions describes the options that make the pinned volumes differ from
+// a plain unpack with the store mappings, the way their chain IDs do
+func pinOptions(excludePaths []string, volumeMappings, storeMappings IDMappings) string {
+	options := []string{}
+	if len(excludePaths) > 0 {
+		sortedPaths := append([]string{}, excludePaths...)
+		sort.Strings(sortedPaths)
+		options = append(options, "exclude="+strings.Join(sortedPaths, ":"))
+	}
+
+	volumeMappingsKey := idMappingsKey(volumeMappings)
+	if volumeMappingsKey != idMappingsKey(storeMappings) {
+		if volumeMappingsKey == "" {
+			options = append(options, "unmapped")
+		} else {
+			mappingsSha := sha256.Sum256([]byte(volumeMappingsKey))
+			options = append(options, "mappings="+hex.EncodeToString(mappingsSha[:])[:12])
+		}
+	}
+
+	return strings.Join(options, " ")
+}
@@ -0,0 +1,313 @@
+package groot_test
+
+import (
+	"errors"
+	"net/url"
+	"os"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pinner", func() {
+	var (
+		fakeBaseImagePuller   *grootfakes.FakeBaseImagePuller
+		fakeLocksmith         *grootfakes.FakeLocksmith
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		fakeMetricsEmitter    *grootfakes.FakeMetricsEmitter
+		lockFile              *os.File
+		pinner                *groot.Pinner
+		logger                lager.Logger
+		baseImageURL          *url.URL
+	)
+
+	BeforeEach(func() {
+		fakeBaseImagePuller = new(grootfakes.FakeBaseImagePuller)
+		fakeLocksmith = new(grootfakes.FakeLocksmith)
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+		fakeMetricsEmitter = new(grootfakes.FakeMetricsEmitter)
+
+		lockFile = &os.File{}
+		fakeLocksmith.LockReturns(lockFile, nil)
+		fakeBaseImagePuller.FetchBaseImageInfoReturns(groot.BaseImageInfo{
+			LayerInfos: []groot.LayerInfo{
+				{ChainID: "chain-1"},
+				{ChainID: "chain-2", ParentChainID: "chain-1"},
+			},
+			ManifestDigest: "sha256:manifest",
+		}, nil)
+
+		var err error
+		baseImageURL, err = url.Parse("docker:///cfgarden/empty")
+		Expect(err).NotTo(HaveOccurred())
+
+		pinner = groot.IamPinner(fakeBaseImagePuller, fakeLocksmith, fakeDependencyManager, fakeMetricsEmitter)
+		logger = lagertest.NewTestLogger("pinner")
+	})
+
+	Describe("Pin", func() {
+		It("pulls the base image", func() {
+			Expect(pinner.Pin(logger, groot.PinSpec{BaseImageURL: baseImageURL})).To(Succeed())
+
+			Expect(fakeBaseImagePuller.PullCallCount()).To(Equal(1))
+			_, baseImageInfo, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+			Expect(baseImageInfo.LayerInfos).To(HaveLen(2))
+			Expect(baseImageSpec.BaseImageReference).To(Equal("docker:///cfgarden/empty"))
+			Expect(baseImageSpec.ManifestDigest).To(Equal("sha256:manifest"))
+			Expect(baseImageSpec.ImageID).To(Equal("pin:docker:///cfgarden/empty"))
+		})
+
+		It("registers the pin dependencies", func() {
+			Expect(pinner.Pin(logger, groot.PinSpec{BaseImageURL: baseImageURL})).To(Succeed())
+
+			Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(1))
+			id, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(id).To(Equal("pin:docker:///cfgarden/empty"))
+			Expect(chainIDs).To(Equal([]string{"chain-1", "chain-2"}))
+		})
+
+		It("unpacks the volumes with the store mappings", func() {
+			storeIDMappings := groot.IDMappings{
+				UIDMappings: []groot.IDMappingSpec{{HostID: 100, NamespaceID: 0, Size: 1}},
+				GIDMappings: []groot.IDMappingSpec{{HostID: 200, NamespaceID: 0, Size: 1}},
+			}
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				UIDMappings:     storeIDMappings.UIDMappings,
+				GIDMappings:     storeIDMappings.GIDMappings,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+
+			_, _, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+			Expect(baseImageSpec.UIDMappings).To(Equal(storeIDMappings.UIDMappings))
+			Expect(baseImageSpec.OwnerUID).To(Equal(100))
+			Expect(baseImageSpec.OwnerGID).To(Equal(200))
+
+			_, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(chainIDs).To(Equal([]string{"chain-1", "chain-2"}))
+		})
+
+		It("pins the volumes unpacked with other mappings under their own chain IDs", func() {
+			storeIDMappings := groot.IDMappings{
+				UIDMappings: []groot.IDMappingSpec{{HostID: 100, NamespaceID: 0, Size: 1}},
+				GIDMappings: []groot.IDMappingSpec{{HostID: 200, NamespaceID: 0, Size: 1}},
+			}
+			imageUIDMappings := []groot.IDMappingSpec{{HostID: 300, NamespaceID: 0, Size: 1}}
+			imageGIDMappings := []groot.IDMappingSpec{{HostID: 400, NamespaceID: 0, Size: 1}}
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				UIDMappings:     imageUIDMappings,
+				GIDMappings:     imageGIDMappings,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+
+			_, baseImageInfo, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+			Expect(baseImageSpec.UIDMappings).To(Equal(imageUIDMappings))
+			Expect(baseImageSpec.OwnerUID).To(Equal(300))
+			Expect(baseImageSpec.OwnerGID).To(Equal(400))
+
+			_, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(chainIDs).To(HaveLen(2))
+			Expect(chainIDs).NotTo(ContainElement("chain-1"))
+			Expect(chainIDs[0]).To(Equal(baseImageInfo.LayerInfos[0].ChainID))
+		})
+
+		It("pins the unmapped volumes of images mounted with idmapped mounts", func() {
+			storeIDMappings := groot.IDMappings{
+				UIDMappings: []groot.IDMappingSpec{{HostID: 100, NamespaceID: 0, Size: 1}},
+				GIDMappings: []groot.IDMappingSpec{{HostID: 200, NamespaceID: 0, Size: 1}},
+			}
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				UIDMappings:     []groot.IDMappingSpec{{HostID: 300, NamespaceID: 0, Size: 1}},
+				GIDMappings:     []groot.IDMappingSpec{{HostID: 400, NamespaceID: 0, Size: 1}},
+				IDMappedMount:   true,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+
+			_, _, baseImageSpec := fakeBaseImagePuller.PullArgsForCall(0)
+			Expect(baseImageSpec.UIDMappings).To(BeEmpty())
+			Expect(baseImageSpec.GIDMappings).To(BeEmpty())
+			Expect(baseImageSpec.OwnerUID).To(Equal(os.Getuid()))
+
+			_, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(chainIDs).NotTo(ContainElement("chain-1"))
+		})
+
+		It("pins the volumes with exclusions under their own chain IDs", func() {
+			Expect(pinner.Pin(logger, groot.PinSpec{BaseImageURL: baseImageURL, ExcludePaths: []string{"/usr/share/doc"}})).To(Succeed())
+
+			_, chainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(chainIDs).To(HaveLen(2))
+			Expect(chainIDs).NotTo(ContainElement("chain-1"))
+		})
+
+		It("keeps the pins for other options apart", func() {
+			storeIDMappings := groot.IDMappings{
+				UIDMappings: []groot.IDMappingSpec{{HostID: 100, NamespaceID: 0, Size: 1}},
+				GIDMappings: []groot.IDMappingSpec{{HostID: 200, NamespaceID: 0, Size: 1}},
+			}
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				UIDMappings:     storeIDMappings.UIDMappings,
+				GIDMappings:     storeIDMappings.GIDMappings,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				ExcludePaths:    []string{"/usr/share/doc", "/tmp"},
+				UIDMappings:     storeIDMappings.UIDMappings,
+				GIDMappings:     storeIDMappings.GIDMappings,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				UIDMappings:     []groot.IDMappingSpec{{HostID: 300, NamespaceID: 0, Size: 1}},
+				GIDMappings:     []groot.IDMappingSpec{{HostID: 400, NamespaceID: 0, Size: 1}},
+				IDMappedMount:   true,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				UIDMappings:     []groot.IDMappingSpec{{HostID: 300, NamespaceID: 0, Size: 1}},
+				GIDMappings:     []groot.IDMappingSpec{{HostID: 400, NamespaceID: 0, Size: 1}},
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+
+			Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(4))
+			id, _ := fakeDependencyManager.RegisterArgsForCall(0)
+			Expect(id).To(Equal("pin:docker:///cfgarden/empty"))
+			id, _ = fakeDependencyManager.RegisterArgsForCall(1)
+			Expect(id).To(Equal("pin:docker:///cfgarden/empty exclude=/tmp:/usr/share/doc"))
+			id, _ = fakeDependencyManager.RegisterArgsForCall(2)
+			Expect(id).To(Equal("pin:docker:///cfgarden/empty unmapped"))
+			id, _ = fakeDependencyManager.RegisterArgsForCall(3)
+			Expect(id).To(MatchRegexp(`^pin:docker:///cfgarden/empty mappings=[0-9a-f]{12}$`))
+		})
+
+		It("pins the volumes create uses for the same options", func() {
+			storeIDMappings := groot.IDMappings{
+				UIDMappings: []groot.IDMappingSpec{{HostID: 100, NamespaceID: 0, Size: 1}},
+				GIDMappings: []groot.IDMappingSpec{{HostID: 200, NamespaceID: 0, Size: 1}},
+			}
+			uidMappings := []groot.IDMappingSpec{{HostID: 300, NamespaceID: 0, Size: 1}}
+			gidMappings := []groot.IDMappingSpec{{HostID: 400, NamespaceID: 0, Size: 1}}
+			excludePaths := []string{"/usr/share/doc"}
+
+			Expect(pinner.Pin(logger, groot.PinSpec{
+				BaseImageURL:    baseImageURL,
+				ExcludePaths:    excludePaths,
+				UIDMappings:     uidMappings,
+				GIDMappings:     gidMappings,
+				StoreIDMappings: storeIDMappings,
+			})).To(Succeed())
+
+			creator := groot.IamCreator(new(grootfakes.FakeImageManager), fakeBaseImagePuller,
+				fakeLocksmith, fakeDependencyManager, fakeMetricsEmitter, new(grootfakes.FakeCleaner))
+			_, err := creator.Create(logger, groot.CreateSpec{
+				ID:              "my-image",
+				BaseImageURL:    baseImageURL,
+				ExcludePaths:    excludePaths,
+				UIDMappings:     uidMappings,
+				GIDMappings:     gidMappings,
+				StoreIDMappings: storeIDMappings,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(2))
+			_, pinnedChainIDs := fakeDependencyManager.RegisterArgsForCall(0)
+			_, imageChainIDs := fakeDependencyManager.RegisterArgsForCall(1)
+			Expect(pinnedChainIDs).To(Equal(imageChainIDs))
+		})
+
+		It("holds the global lock while pulling", func() {
+			fakeBaseImagePuller.PullStub = func(_ lager.Logger, _ groot.BaseImageInfo, _ groot.BaseImageSpec) error {
+				Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(0))
+				return nil
+			}
+
+			Expect(pinner.Pin(logger, groot.PinSpec{BaseImageURL: baseImageURL})).To(Succeed())
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when pulling fails", func() {
+			BeforeEach(func() {
+				fakeBaseImagePuller.PullReturns(errors.New("pull failed"))
+			})
+
+			It("does not register the pin", func() {
+				Expect(pinner.Pin(logger, groot.PinSpec{BaseImageURL: baseImageURL})).To(MatchError(ContainSubstring("pull failed")))
+				Expect(fakeDependencyManager.RegisterCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when fetching the base image info fails", func() {
+			BeforeEach(func() {
+				fakeBaseImagePuller.FetchBaseImageInfoReturns(groot.BaseImageInfo{}, errors.New("no manifest"))
+			})
+
+			It("returns the error", func() {
+				Expect(pinner.Pin(logger, groot.PinSpec{BaseImageURL: baseImageURL})).To(MatchError(ContainSubstring("no manifest")))
+				Expect(fakeBaseImagePuller.PullCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Unpin", func() {
+		BeforeEach(func() {
+			fakeDependencyManager.RegisteredIDsReturns([]string{
+				"image:my-image",
+				"pin:docker:///cfgarden/empty",
+				"pin:docker:///cfgarden/empty exclude=/usr/share/doc",
+				"pin:docker:///cfgarden/empty-too",
+			}, nil)
+		})
+
+		It("deregisters the pin dependencies for every set of options", func() {
+			Expect(pinner.Unpin(logger, baseImageURL)).To(Succeed())
+
+			Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(2))
+			Expect(fakeDependencyManager.DeregisterArgsForCall(0)).To(Equal("pin:docker:///cfgarden/empty"))
+			Expect(fakeDependencyManager.DeregisterArgsForCall(1)).To(Equal("pin:docker:///cfgarden/empty exclude=/usr/share/doc"))
+		})
+
+		Context("when the image is not pinned", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.RegisteredIDsReturns([]string{"pin:docker:///cfgarden/empty-too"}, nil)
+			})
+
+			It("returns an error", func() {
+				Expect(pinner.Unpin(logger, baseImageURL)).To(MatchError("base image `docker:///cfgarden/empty` is not pinned"))
+				Expect(fakeDependencyManager.DeregisterCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Pins", func() {
+		BeforeEach(func() {
+			fakeDependencyManager.RegisteredIDsReturns([]string{
+				"image:my-image",
+				"pin:docker:///ubuntu",
+				"ref:my-ref",
+				"pin:docker:///cfgarden/empty",
+				"pin:docker:///ubuntu exclude=/usr/share/doc",
+			}, nil)
+		})
+
+		It("lists the pinned images with their options", func() {
+			pins, err := pinner.Pins(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pins).To(Equal([]groot.PinListing{
+				{BaseImage: "docker:///cfgarden/empty"},
+				{BaseImage: "docker:///ubuntu"},
+				{BaseImage: "docker:///ubuntu", Options: "exclude=/usr/share/doc"},
+			}))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package groot
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+type Remounter struct {
+	imageManager      ImageManager
+	locksmith         Locksmith
+	dependencyManager DependencyManager
+}
+
+func IamRemounter(imageManager ImageManager, locksmith Locksmith, dependencyManager DependencyManager) *Remounter {
+	return &Remounter{
+		imageManager:      imageManager,
+		locksmith:         locksmith,
+		dependencyManager: dependencyManager,
+	}
+}
+
+// Remount mounts the rootfs of an image again on the base volumes registered
+// for it, typically after a reboot. It returns false when there was nothing
+// to do, so it is safe to run on every image.
+func (r *Remounter) Remount(logger lager.Logger, id string) (bool, error) {
+	logger = logger.Session("groot-remounting", lager.Data{"imageID": id})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	// squashed volumes may have to be built again, as when creating images
+	lockFile, err := r.locksmith.Lock(GlobalLockKey)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := r.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	baseVolumeIDs, err := r.dependencyManager.Dependencies(fmt.Sprintf(ImageReferenceFormat, id))
+	if err != nil {
+		return false, errorspkg.Wrap(err, "reading image dependencies")
+	}
+
+	return r.imageManager.Remount(logger, id, baseVolumeIDs)
+}
@@ -0,0 +1,115 @@
+package groot_test
+
+import (
+	"errors"
+	"os"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Remounter", func() {
+	var (
+		fakeImageManager      *grootfakes.FakeImageManager
+		fakeLocksmith         *grootfakes.FakeLocksmith
+		fakeDependencyManager *grootfakes.FakeDependencyManager
+		lockFile              *os.File
+		remounter             *groot.Remounter
+		logger                lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeLocksmith = new(grootfakes.FakeLocksmith)
+		fakeDependencyManager = new(grootfakes.FakeDependencyManager)
+
+		lockFile = &os.File{}
+		fakeLocksmith.LockReturns(lockFile, nil)
+		fakeDependencyManager.DependenciesReturns([]string{"chain-1", "chain-2"}, nil)
+		fakeImageManager.RemountReturns(true, nil)
+
+		remounter = groot.IamRemounter(fakeImageManager, fakeLocksmith, fakeDependencyManager)
+		logger = lagertest.NewTestLogger("remounter")
+	})
+
+	Describe("Remount", func() {
+		It("remounts the image on its base volumes", func() {
+			remounted, err := remounter.Remount(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remounted).To(BeTrue())
+
+			Expect(fakeDependencyManager.DependenciesArgsForCall(0)).To(Equal("image:some-id"))
+
+			Expect(fakeImageManager.RemountCallCount()).To(Equal(1))
+			_, id, baseVolumeIDs := fakeImageManager.RemountArgsForCall(0)
+			Expect(id).To(Equal("some-id"))
+			Expect(baseVolumeIDs).To(Equal([]string{"chain-1", "chain-2"}))
+		})
+
+		It("holds the global lock while remounting", func() {
+			fakeImageManager.RemountStub = func(_ lager.Logger, _ string, _ []string) (bool, error) {
+				Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(0))
+				return true, nil
+			}
+
+			_, err := remounter.Remount(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLocksmith.LockArgsForCall(0)).To(Equal(groot.GlobalLockKey))
+			Expect(fakeLocksmith.UnlockArgsForCall(0)).To(Equal(lockFile))
+		})
+
+		Context("when there is nothing to remount", func() {
+			BeforeEach(func() {
+				fakeImageManager.RemountReturns(false, nil)
+			})
+
+			It("says so", func() {
+				remounted, err := remounter.Remount(logger, "some-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeFalse())
+			})
+		})
+
+		Context("when reading the dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.DependenciesReturns(nil, errors.New("corrupted"))
+			})
+
+			It("returns an error", func() {
+				_, err := remounter.Remount(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("corrupted")))
+				Expect(fakeImageManager.RemountCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the image manager fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.RemountReturns(false, errors.New("mounting overlay"))
+			})
+
+			It("returns an error", func() {
+				_, err := remounter.Remount(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("mounting overlay")))
+			})
+		})
+
+		Context("when locking fails", func() {
+			BeforeEach(func() {
+				fakeLocksmith.LockReturns(nil, errors.New("lock is busy"))
+			})
+
+			It("returns an error", func() {
+				_, err := remounter.Remount(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("lock is busy")))
+				Expect(fakeImageManager.RemountCallCount()).To(BeZero())
+			})
+		})
+	})
+})
@@ -0,0 +1,54 @@
+package groot
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const MetricImageResizeTime = "ImageResizeTime"
+
+type ResizeSpec struct {
+	ID                        string
+	DiskLimit                 int64
+	ExcludeBaseImageFromQuota bool
+}
+
+type Resizer struct {
+	imageManager   ImageManager
+	locksmith      Locksmith
+	metricsEmitter MetricsEmitter
+}
+
+func IamResizer(imageManager ImageManager, locksmith Locksmith, metricsEmitter MetricsEmitter) *Resizer {
+	return &Resizer{
+		imageManager:   imageManager,
+		locksmith:      locksmith,
+		metricsEmitter: metricsEmitter,
+	}
+}
+
+// Resize changes the disk limit of an image while holding the image lock, so
+// that resizes of the same image don't check the usage against a limit that
+// is being changed
+
+func (r *Resizer) Resize(logger lager.Logger, spec ResizeSpec) error {
+	defer r.metricsEmitter.TryEmitDurationFrom(logger, MetricImageResizeTime, time.Now())
+
+	logger = logger.Session("groot-resizing", lager.Data{"spec": spec})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	lockFile, err := r.locksmith.Lock(fmt.Sprintf(ImageLockKeyFormat, spec.ID))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.locksmith.Unlock(lockFile); err != nil {
+			logger.Error("failed-to-unlock", err)
+		}
+	}()
+
+	return r.imageManager.Resize(logger, spec.ID, spec.DiskLimit, spec.ExcludeBaseImageFromQuota)
+}
@@ -0,0 +1,92 @@
+package groot_test
+
+import (
+	"errors"
+	"os"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/groot/grootfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resizer", func() {
+	var (
+		fakeImageManager   *grootfakes.FakeImageManager
+		fakeLocksmith      *grootfakes.FakeLocksmith
+		fakeMetricsEmitter *grootfakes.FakeMetricsEmitter
+		lockFile           *os.File
+		resizer            *groot.Resizer
+		logger             lager.Logger
+		spec               groot.ResizeSpec
+	)
+
+	BeforeEach(func() {
+		fakeImageManager = new(grootfakes.FakeImageManager)
+		fakeLocksmith = new(grootfakes.FakeLocksmith)
+		fakeMetricsEmitter = new(grootfakes.FakeMetricsEmitter)
+		lockFile = &os.File{}
+		fakeLocksmith.LockReturns(lockFile, nil)
+		resizer = groot.IamResizer(fakeImageManager, fakeLocksmith, fakeMetricsEmitter)
+		logger = lagertest.NewTestLogger("resizer")
+		spec = groot.ResizeSpec{ID: "some-id", DiskLimit: 2048, ExcludeBaseImageFromQuota: true}
+	})
+
+	Describe("Resize", func() {
+		It("resizes the image with the image manager", func() {
+			Expect(resizer.Resize(logger, spec)).To(Succeed())
+
+			Expect(fakeImageManager.ResizeCallCount()).To(Equal(1))
+			_, id, diskLimit, exclusiveDiskLimit := fakeImageManager.ResizeArgsForCall(0)
+			Expect(id).To(Equal("some-id"))
+			Expect(diskLimit).To(BeEquivalentTo(2048))
+			Expect(exclusiveDiskLimit).To(BeTrue())
+		})
+
+		It("holds the image lock while resizing", func() {
+			fakeImageManager.ResizeStub = func(lager.Logger, string, int64, bool) error {
+				Expect(fakeLocksmith.UnlockCallCount()).To(Equal(0))
+				return nil
+			}
+			Expect(resizer.Resize(logger, spec)).To(Succeed())
+
+			Expect(fakeLocksmith.LockCallCount()).To(Equal(1))
+			Expect(fakeLocksmith.LockArgsForCall(0)).To(Equal("image-some-id-lock"))
+			Expect(fakeLocksmith.UnlockCallCount()).To(Equal(1))
+			Expect(fakeLocksmith.UnlockArgsForCall(0)).To(Equal(lockFile))
+		})
+
+		Context("when the lock can't be taken", func() {
+			BeforeEach(func() {
+				fakeLocksmith.LockReturns(nil, errors.New("no locks for you"))
+			})
+
+			It("returns an error without resizing", func() {
+				Expect(resizer.Resize(logger, spec)).To(MatchError("no locks for you"))
+				Expect(fakeImageManager.ResizeCallCount()).To(Equal(0))
+			})
+		})
+
+		It("emits metrics for resizing", func() {
+			Expect(resizer.Resize(logger, spec)).To(Succeed())
+
+			Expect(fakeMetricsEmitter.TryEmitDurationFromCallCount()).To(Equal(1))
+			_, name, start := fakeMetricsEmitter.TryEmitDurationFromArgsForCall(0)
+			Expect(name).To(Equal(groot.MetricImageResizeTime))
+			Expect(start).NotTo(BeZero())
+		})
+
+		Context("when the image manager fails", func() {
+			BeforeEach(func() {
+				fakeImageManager.ResizeReturns(errors.New("disk limit is smaller than the 100 bytes already used by the image"))
+			})
+
+			It("returns an error", func() {
+				Expect(resizer.Resize(logger, spec)).To(MatchError(ContainSubstring("already used by the image")))
+			})
+		})
+	})
+})
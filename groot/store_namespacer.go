@@ -76,8 +76,8 @@ func (n *StoreNamespacer) write(uidMappings, gidMappings []IDMappingSpec) error
 	defer namespaceStore.Close()
 
 	namespace := mappings{
-		UIDMappings: n.normalizeMappings(uidMappings),
-		GIDMappings: n.normalizeMappings(gidMappings),
+		UIDMappings: normalizeIDMappings(uidMappings),
+		GIDMappings: normalizeIDMappings(gidMappings),
 	}
 
 	if err := os.Chmod(namespaceStore.Name(), 0755); err != nil {
@@ -97,11 +97,11 @@ func (n *StoreNamespacer) validateNamespace(namespaceFilePath string, uidMapping
 		return errorspkg.Wrapf(err, "reading namespace file %s", namespaceStore.Name())
 	}
 
-	if !reflect.DeepEqual(namespace.UIDMappings, n.normalizeMappings(uidMappings)) {
+	if !reflect.DeepEqual(namespace.UIDMappings, normalizeIDMappings(uidMappings)) {
 		return errorspkg.New("provided UID mappings do not match those already configured in the store")
 	}
 
-	if !reflect.DeepEqual(namespace.GIDMappings, n.normalizeMappings(gidMappings)) {
+	if !reflect.DeepEqual(namespace.GIDMappings, normalizeIDMappings(gidMappings)) {
 		return errorspkg.New("provided GID mappings do not match those already configured in the store")
 	}
 
@@ -112,7 +112,7 @@ func (n *StoreNamespacer) namespaceFilePath() string {
 	return filepath.Join(n.storePath, store.MetaDirName, NamespaceFilename)
 }
 
-func (n *StoreNamespacer) normalizeMappings(mappings []IDMappingSpec) []string {
+func normalizeIDMappings(mappings []IDMappingSpec) []string {
 	stringMappings := []string{}
 	for _, mapping := range mappings {
 		stringMappings = append(stringMappings, fmt.Sprintf("%d:%d:%d", mapping.NamespaceID, mapping.HostID, mapping.Size))
@@ -1,7 +1,12 @@
 package integration_test
 
 import (
+	"encoding/json"
+	"path/filepath"
+	"syscall"
+
 	"code.cloudfoundry.org/grootfs/commands/config"
+	"code.cloudfoundry.org/grootfs/store"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -16,11 +21,30 @@ var _ = Describe("Capacity", func() {
 	})
 
 	Describe("Capacity", func() {
-		It("emits init store size", func() {
+		It("emits the store filesystem capacity", func() {
 			output, err := Runner.RunSubcommand("capacity")
-
 			Expect(err).NotTo(HaveOccurred())
-			Expect(output).To(MatchJSON("{\"capacity\": 190092}"))
+
+			var capacity store.StoreCapacity
+			Expect(json.Unmarshal([]byte(output), &capacity)).To(Succeed())
+
+			stats := syscall.Statfs_t{}
+			Expect(syscall.Statfs(StorePath, &stats)).To(Succeed())
+			Expect(capacity.Capacity).To(BeEquivalentTo(stats.Blocks * uint64(stats.Bsize)))
+			Expect(capacity.Used).To(BeNumerically(">", 0))
+			Expect(capacity.Available).To(BeNumerically(">", 0))
+			Expect(capacity.UsedVolumesSize + capacity.UnusedVolumesSize).To(Equal(capacity.TotalVolumesSize))
+		})
+
+		Context("when the store does not exist yet", func() {
+			It("emits init store size", func() {
+				output, err := Runner.WithStore(filepath.Join(StorePath, "not-here")).RunSubcommand("capacity")
+				Expect(err).NotTo(HaveOccurred())
+
+				var capacity store.StoreCapacity
+				Expect(json.Unmarshal([]byte(output), &capacity)).To(Succeed())
+				Expect(capacity.Capacity).To(BeEquivalentTo(190092))
+			})
 		})
 	})
 })
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -445,6 +446,37 @@ var _ = Describe("Create", func() {
 		})
 	})
 
+	Context("when labels are given", func() {
+		It("keeps them with the image", func() {
+			_, err := Runner.Create(groot.CreateSpec{
+				BaseImageURL: integration.String2URL(baseImagePath),
+				ID:           randomImageID,
+				Mount:        mountByDefault(),
+				Labels:       map[string]string{"app": "my-app", "tenant": ""},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			output, err := Runner.RunSubcommand("inspect", randomImageID)
+			Expect(err).NotTo(HaveOccurred())
+
+			var inspection groot.ImageInspection
+			Expect(json.Unmarshal([]byte(output), &inspection)).To(Succeed())
+			Expect(inspection.Labels).To(Equal(map[string]string{"app": "my-app", "tenant": ""}))
+		})
+
+		Context("when a label has no key", func() {
+			It("fails", func() {
+				_, err := Runner.Create(groot.CreateSpec{
+					BaseImageURL: integration.String2URL(baseImagePath),
+					ID:           randomImageID,
+					Mount:        mountByDefault(),
+					Labels:       map[string]string{"": "my-app"},
+				})
+				Expect(err).To(MatchError(ContainSubstring(`invalid label "=my-app": expected <key>=<value>`)))
+			})
+		})
+	})
+
 	Describe("--config global flag", func() {
 		var (
 			cfg  config.Config
@@ -179,4 +179,85 @@ var _ = Describe("Delete", func() {
 			Eventually(outBuffer).Should(gbytes.Say("id was not specified"))
 		})
 	})
+
+	Describe("--label", func() {
+		var labelledImageID, otherImageID string
+
+		JustBeforeEach(func() {
+			labelledImageID = testhelpers.NewRandomID()
+			_, err := Runner.Create(groot.CreateSpec{
+				BaseImageURL: integration.String2URL(baseImagePath),
+				ID:           labelledImageID,
+				Mount:        mountByDefault(),
+				Labels:       map[string]string{"app": "my-app", "space": "my-space"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			otherImageID = testhelpers.NewRandomID()
+			_, err = Runner.Create(groot.CreateSpec{
+				BaseImageURL: integration.String2URL(baseImagePath),
+				ID:           otherImageID,
+				Mount:        mountByDefault(),
+				Labels:       map[string]string{"app": "other-app"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("deletes only the images with all the labels", func() {
+			output, err := Runner.RunSubcommand("delete", "--label", "app=my-app", "--label", "space")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(fmt.Sprintf("Image %s deleted", labelledImageID)))
+
+			Expect(filepath.Join(StorePath, store.ImageDirName, labelledImageID)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(StorePath, store.ImageDirName, otherImageID)).To(BeADirectory())
+			Expect(filepath.Join(StorePath, store.ImageDirName, randomImageID)).To(BeADirectory())
+		})
+
+		It("deletes every image with the label for a bare key", func() {
+			_, err := Runner.RunSubcommand("delete", "--label", "app")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(StorePath, store.ImageDirName, labelledImageID)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(StorePath, store.ImageDirName, otherImageID)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(StorePath, store.ImageDirName, randomImageID)).To(BeADirectory())
+		})
+
+		Context("when an image can't be inspected", func() {
+			var halfBuiltImagePath string
+
+			JustBeforeEach(func() {
+				halfBuiltImagePath = filepath.Join(StorePath, store.ImageDirName, "half-built")
+				Expect(os.Mkdir(halfBuiltImagePath, 0755)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(halfBuiltImagePath)).To(Succeed())
+			})
+
+			It("skips it and deletes the others", func() {
+				output, err := Runner.RunSubcommand("delete", "--label", "app")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(ContainSubstring("Image half-built skipped"))
+
+				Expect(halfBuiltImagePath).To(BeADirectory())
+				Expect(filepath.Join(StorePath, store.ImageDirName, labelledImageID)).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(StorePath, store.ImageDirName, otherImageID)).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when a label has no key", func() {
+			It("fails", func() {
+				_, err := Runner.RunSubcommand("delete", "--label", "=my-app")
+				Expect(err).To(MatchError(ContainSubstring(`invalid label "=my-app": expected <key>[=<value>]`)))
+			})
+		})
+
+		Context("when an id is given too", func() {
+			It("fails and deletes nothing", func() {
+				_, err := Runner.RunSubcommand("delete", "--label", "app", randomImageID)
+				Expect(err).To(MatchError(ContainSubstring("either an id or labels must be specified, not both")))
+				Expect(filepath.Join(StorePath, store.ImageDirName, randomImageID)).To(BeADirectory())
+			})
+		})
+	})
 })
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"encoding/json"
 	"os"
 	"path"
 	"path/filepath"
@@ -18,7 +19,10 @@ import (
 )
 
 var _ = Describe("List", func() {
-	var containerSpec specs.Spec
+	var (
+		containerSpec specs.Spec
+		baseImagePath string
+	)
 
 	BeforeEach(func() {
 		sourceImagePath, err := os.MkdirTemp("", "")
@@ -26,8 +30,9 @@ var _ = Describe("List", func() {
 
 		Expect(os.WriteFile(path.Join(sourceImagePath, "foo"), []byte("hello-world"), 0644)).To(Succeed())
 		baseImageFile := integration.CreateBaseImageTar(sourceImagePath)
+		baseImagePath = baseImageFile.Name()
 		containerSpec, err = Runner.Create(groot.CreateSpec{
-			BaseImageURL: integration.String2URL(baseImageFile.Name()),
+			BaseImageURL: integration.String2URL(baseImagePath),
 			ID:           "root-image",
 			Mount:        mountByDefault(),
 		})
@@ -41,6 +46,164 @@ var _ = Describe("List", func() {
 		Expect(images[0].Path).To(Equal(filepath.Dir(containerSpec.Root.Path)))
 	})
 
+	Describe("--json", func() {
+		It("describes each image", func() {
+			output, err := Runner.RunSubcommand("list", "--json")
+			Expect(err).NotTo(HaveOccurred())
+
+			var images []groot.ImageListing
+			Expect(json.Unmarshal([]byte(output), &images)).To(Succeed())
+			Expect(images).To(HaveLen(1))
+			Expect(images[0].ID).To(Equal("root-image"))
+			Expect(images[0].Path).To(Equal(filepath.Dir(containerSpec.Root.Path)))
+			Expect(images[0].CreatedAt).NotTo(BeZero())
+			Expect(images[0].Error).To(BeEmpty())
+		})
+	})
+
+	Describe("--filter", func() {
+		BeforeEach(func() {
+			_, err := Runner.Create(groot.CreateSpec{
+				BaseImageURL: integration.String2URL(baseImagePath),
+				ID:           "other-image",
+				Mount:        mountByDefault(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("only lists the images matching the id prefix", func() {
+			output, err := Runner.RunSubcommand("list", "--filter", "id=root")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(filepath.Dir(containerSpec.Root.Path)))
+		})
+
+		It("lists nothing when no image is old enough", func() {
+			output, err := Runner.RunSubcommand("list", "--filter", "older-than=1h")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(BeEmpty())
+		})
+
+		It("lists every image created recently enough", func() {
+			output, err := Runner.RunSubcommand("list", "--json", "--filter", "newer-than=1h")
+			Expect(err).NotTo(HaveOccurred())
+
+			var images []groot.ImageListing
+			Expect(json.Unmarshal([]byte(output), &images)).To(Succeed())
+			Expect(images).To(HaveLen(2))
+			Expect(images[0].ID).To(Equal("other-image"))
+			Expect(images[1].ID).To(Equal("root-image"))
+		})
+
+		Context("when filtering by label", func() {
+			BeforeEach(func() {
+				_, err := Runner.Create(groot.CreateSpec{
+					BaseImageURL: integration.String2URL(baseImagePath),
+					ID:           "labelled-image",
+					Mount:        mountByDefault(),
+					Labels:       map[string]string{"app": "my-app"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = Runner.Create(groot.CreateSpec{
+					BaseImageURL: integration.String2URL(baseImagePath),
+					ID:           "empty-labelled-image",
+					Mount:        mountByDefault(),
+					Labels:       map[string]string{"app": ""},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			listIDs := func(filter string) []string {
+				output, err := Runner.RunSubcommand("list", "--json", "--filter", filter)
+				Expect(err).NotTo(HaveOccurred())
+
+				var images []groot.ImageListing
+				Expect(json.Unmarshal([]byte(output), &images)).To(Succeed())
+				ids := []string{}
+				for _, image := range images {
+					ids = append(ids, image.ID)
+				}
+				return ids
+			}
+
+			It("lists the images with the label, whatever its value, for a bare key", func() {
+				Expect(listIDs("label=app")).To(Equal([]string{"empty-labelled-image", "labelled-image"}))
+			})
+
+			It("lists the images with the exact value", func() {
+				Expect(listIDs("label=app=my-app")).To(Equal([]string{"labelled-image"}))
+			})
+
+			It("lists the images with an empty value for <key>=", func() {
+				Expect(listIDs("label=app=")).To(Equal([]string{"empty-labelled-image"}))
+			})
+
+			Context("when the label has no key", func() {
+				It("fails", func() {
+					_, err := Runner.RunSubcommand("list", "--filter", "label==my-app")
+					Expect(err).To(MatchError(ContainSubstring(`invalid filter "label==my-app": expected label=<key>[=<value>]`)))
+				})
+			})
+		})
+
+		Context("when the filter is unknown", func() {
+			It("fails", func() {
+				_, err := Runner.RunSubcommand("list", "--filter", "colour=red")
+				Expect(err).To(MatchError(ContainSubstring(`unknown filter "colour"`)))
+			})
+		})
+
+		Context("when the filter has no value", func() {
+			It("fails", func() {
+				_, err := Runner.RunSubcommand("list", "--filter", "id")
+				Expect(err).To(MatchError(ContainSubstring(`invalid filter "id": expected key=value`)))
+			})
+		})
+	})
+
+	Describe("--volumes", func() {
+		It("lists the volumes in a table", func() {
+			output, err := Runner.RunSubcommand("list", "--volumes")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(MatchRegexp(`^ID\s+SIZE\s+REF COUNT\s+MARKED FOR COLLECTION\n[0-9a-f]+\s+\d+\s+1\s+false$`))
+		})
+
+		It("describes each volume with --json", func() {
+			output, err := Runner.RunSubcommand("list", "--volumes", "--json")
+			Expect(err).NotTo(HaveOccurred())
+
+			var volumes []groot.VolumeListing
+			Expect(json.Unmarshal([]byte(output), &volumes)).To(Succeed())
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].ID).NotTo(BeEmpty())
+			Expect(volumes[0].Size).To(BeNumerically(">", 0))
+			Expect(volumes[0].RefCount).To(Equal(1))
+			Expect(volumes[0].MarkedForCollection).To(BeFalse())
+		})
+	})
+
+	Describe("--pins", func() {
+		BeforeEach(func() {
+			_, err := Runner.RunSubcommand("pin", baseImagePath)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lists the pinned base images", func() {
+			output, err := Runner.RunSubcommand("list", "--pins")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(baseImagePath))
+		})
+
+		It("describes each pin with --json", func() {
+			output, err := Runner.RunSubcommand("list", "--pins", "--json")
+			Expect(err).NotTo(HaveOccurred())
+
+			var pins []groot.PinListing
+			Expect(json.Unmarshal([]byte(output), &pins)).To(Succeed())
+			Expect(pins).To(ConsistOf(groot.PinListing{BaseImage: baseImagePath}))
+		})
+	})
+
 	Describe("--config global flag", func() {
 		var (
 			configDir      string
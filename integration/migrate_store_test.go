@@ -0,0 +1,159 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"code.cloudfoundry.org/grootfs/base_image_puller"
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/integration"
+	grootfsRunner "code.cloudfoundry.org/grootfs/integration/runner"
+	"code.cloudfoundry.org/grootfs/store"
+	imagemanager "code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/grootfs/store/migrator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrate Store", func() {
+	var baseImagePath string
+
+	BeforeEach(func() {
+		Runner = Runner.WithStore(StorePath)
+
+		workDir, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		baseImagePath = fmt.Sprintf("oci:///%s/assets/oci-test-image/grootfs-busybox:latest", workDir)
+
+		_, err = Runner.Create(groot.CreateSpec{
+			ID:           "image",
+			BaseImageURL: integration.String2URL(baseImagePath),
+			Mount:        mountByDefault(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(Runner.DeleteStore()).To(Succeed())
+	})
+
+	It("records the current version", func() {
+		Expect(migrator.ReadVersion(StorePath)).To(Equal(migrator.CurrentVersion))
+	})
+
+	Context("when the store is at an older version", func() {
+		BeforeEach(func() {
+			Expect(os.Remove(filepath.Join(StorePath, store.MetaDirName, migrator.VersionFilename))).To(Succeed())
+
+			// volume metadata written before timestamps were recorded
+			volumeMetaPaths, err := filepath.Glob(filepath.Join(StorePath, store.MetaDirName, "volume-*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeMetaPaths).NotTo(BeEmpty())
+			for _, volumeMetaPath := range volumeMetaPaths {
+				volumeMeta := readVolumeMeta(volumeMetaPath)
+				Expect(os.WriteFile(volumeMetaPath, []byte(fmt.Sprintf(`{"Size":%d}`, volumeMeta.Size)), 0644)).To(Succeed())
+			}
+		})
+
+		It("keeps creating, listing and deleting images", func() {
+			_, err := Runner.Create(groot.CreateSpec{
+				ID:           "another-image",
+				BaseImageURL: integration.String2URL(baseImagePath),
+				Mount:        mountByDefault(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			images, err := Runner.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(images).To(HaveLen(2))
+
+			Expect(Runner.Delete("another-image")).To(Succeed())
+			Expect(Runner.Delete("image")).To(Succeed())
+
+			Expect(migrator.ReadVersion(StorePath)).To(Equal(0))
+		})
+
+		It("is migrated by migrate-store", func() {
+			output, err := Runner.RunSubcommand("migrate-store")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("store migrated from version 0 to " + strconv.Itoa(migrator.CurrentVersion)))
+			Expect(migrator.ReadVersion(StorePath)).To(Equal(migrator.CurrentVersion))
+
+			volumeMetaPaths, err := filepath.Glob(filepath.Join(StorePath, store.MetaDirName, "volume-*"))
+			Expect(err).NotTo(HaveOccurred())
+			for _, volumeMetaPath := range volumeMetaPaths {
+				Expect(readVolumeMeta(volumeMetaPath).CreatedAt.IsZero()).To(BeFalse())
+			}
+
+			output, err = Runner.RunSubcommand("migrate-store")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("store is up to date"))
+		})
+
+		It("is migrated by init-store", func() {
+			Expect(Runner.InitStore(grootfsRunner.InitSpec{})).To(Succeed())
+			Expect(migrator.ReadVersion(StorePath)).To(Equal(migrator.CurrentVersion))
+		})
+
+		Context("when an image was created without mounting it before grootfs recorded it", func() {
+			var imagePath string
+
+			BeforeEach(func() {
+				_, err := Runner.Create(groot.CreateSpec{
+					ID:           "unmounted-image",
+					BaseImageURL: integration.String2URL(baseImagePath),
+					Mount:        false,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				imagePath = filepath.Join(StorePath, store.ImageDirName, "unmounted-image")
+				Expect(os.Remove(filepath.Join(imagePath, "without_mount"))).To(Succeed())
+				Expect(os.Remove(filepath.Join(imagePath, imagemanager.ImageMetaName))).To(Succeed())
+			})
+
+			It("marks it, so that it is not remounted", func() {
+				_, err := Runner.RunSubcommand("migrate-store")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(filepath.Join(imagePath, "without_mount")).To(BeAnExistingFile())
+
+				output, err := Runner.RunSubcommand("remount", "unmounted-image")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(ContainSubstring("Image unmounted-image skipped"))
+			})
+		})
+	})
+
+	Context("when the store is at a newer version", func() {
+		BeforeEach(func() {
+			Expect(migrator.WriteVersion(StorePath, migrator.CurrentVersion+1)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(migrator.WriteVersion(StorePath, migrator.CurrentVersion)).To(Succeed())
+		})
+
+		It("refuses to use it", func() {
+			_, err := Runner.Create(groot.CreateSpec{
+				ID:           "another-image",
+				BaseImageURL: integration.String2URL(baseImagePath),
+				Mount:        mountByDefault(),
+			})
+			Expect(err).To(MatchError(ContainSubstring("is newer than the supported version")))
+
+			_, err = Runner.RunSubcommand("migrate-store")
+			Expect(err).To(MatchError(ContainSubstring("is newer than the supported version")))
+		})
+	})
+})
+
+func readVolumeMeta(volumeMetaPath string) base_image_puller.VolumeMeta {
+	contents, err := os.ReadFile(volumeMetaPath)
+	Expect(err).NotTo(HaveOccurred())
+
+	var volumeMeta base_image_puller.VolumeMeta
+	Expect(json.Unmarshal(contents, &volumeMeta)).To(Succeed())
+	return volumeMeta
+}
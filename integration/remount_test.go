@@ -0,0 +1,132 @@
+package integration_test
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/integration"
+	"code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Remount", func() {
+	var (
+		randomImageID   string
+		sourceImagePath string
+		baseImagePath   string
+		containerSpec   specs.Spec
+	)
+
+	BeforeEach(func() {
+		var err error
+		sourceImagePath, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(path.Join(sourceImagePath, "foo"), []byte("hello-world"), 0644)).To(Succeed())
+
+		baseImageFile := integration.CreateBaseImageTar(sourceImagePath)
+		baseImagePath = baseImageFile.Name()
+
+		randomImageID = testhelpers.NewRandomID()
+		containerSpec, err = Runner.Create(groot.CreateSpec{
+			BaseImageURL: integration.String2URL(baseImagePath),
+			ID:           randomImageID,
+			Mount:        mountByDefault(),
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sourceImagePath)).To(Succeed())
+		Expect(os.RemoveAll(baseImagePath)).To(Succeed())
+	})
+
+	It("skips an image that is already mounted or not mounted by grootfs", func() {
+		output, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", randomImageID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal(fmt.Sprintf("Image %s skipped: already mounted or not mounted by grootfs", randomImageID)))
+	})
+
+	Context("when the rootfs is no longer mounted", func() {
+		BeforeEach(func() {
+			if !mountByDefault() {
+				Skip("images are only mounted by grootfs when created by root")
+			}
+			Expect(unix.Unmount(containerSpec.Root.Path, 0)).To(Succeed())
+		})
+
+		It("mounts it again", func() {
+			output, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", randomImageID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(fmt.Sprintf("Image %s remounted", randomImageID)))
+			Expect(filepath.Join(containerSpec.Root.Path, "foo")).To(BeAnExistingFile())
+		})
+
+		It("mounts it again with --all", func() {
+			output, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", "--all")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(fmt.Sprintf("Image %s remounted", randomImageID)))
+			Expect(filepath.Join(containerSpec.Root.Path, "foo")).To(BeAnExistingFile())
+		})
+	})
+
+	Context("when an image fails to remount", func() {
+		var brokenImagePath string
+
+		BeforeEach(func() {
+			brokenImagePath = filepath.Join(StorePath, store.ImageDirName, "broken")
+			Expect(os.Mkdir(brokenImagePath, 0755)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(brokenImagePath)).To(Succeed())
+		})
+
+		It("still goes through the others with --all, and reports the failure", func() {
+			_, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", "--all")
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("Image %s skipped", randomImageID))))
+			Expect(err).To(MatchError(ContainSubstring("failed to remount 1 image(s):\nbroken: ")))
+		})
+	})
+
+	Context("when the image does not exist", func() {
+		It("fails", func() {
+			_, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", "non-existing-id")
+			Expect(err).To(MatchError(ContainSubstring("Image `non-existing-id` not found")))
+		})
+	})
+
+	Context("when neither an id nor --all is given", func() {
+		It("fails", func() {
+			_, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount")
+			Expect(err).To(MatchError(ContainSubstring("invalid arguments - usage: remount [--all | <id|image path>]")))
+		})
+	})
+
+	Context("when both an id and --all are given", func() {
+		It("fails", func() {
+			_, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", "--all", randomImageID)
+			Expect(err).To(MatchError(ContainSubstring("invalid arguments - usage: remount [--all | <id|image path>]")))
+		})
+	})
+
+	Context("when more than one id is given", func() {
+		It("fails", func() {
+			_, err := Runner.RunningAsUser(0, 0).RunSubcommand("remount", randomImageID, "other-id")
+			Expect(err).To(MatchError(ContainSubstring("invalid arguments - usage: remount [--all | <id|image path>]")))
+		})
+	})
+
+	Context("when not run as root", func() {
+		It("fails", func() {
+			_, err := Runner.RunningAsUser(uint32(GrootUID), uint32(GrootGID)).RunSubcommand("remount", randomImageID)
+			Expect(err).To(MatchError(ContainSubstring("can only be remounted by Root user")))
+		})
+	})
+})
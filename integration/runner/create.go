@@ -3,6 +3,7 @@ package runner
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
 	"syscall"
 
@@ -142,6 +143,15 @@ func (r Runner) makeCreateArgs(spec groot.CreateSpec) []string {
 		}
 	}
 
+	labelKeys := make([]string, 0, len(spec.Labels))
+	for key := range spec.Labels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	for _, key := range labelKeys {
+		args = append(args, "--label", key+"="+spec.Labels[key])
+	}
+
 	if spec.BaseImageURL != nil {
 		args = append(args, spec.BaseImageURL.String())
 	}
@@ -9,6 +9,7 @@ import (
 	"code.cloudfoundry.org/grootfs/commands"
 	"code.cloudfoundry.org/grootfs/commands/config"
 	"code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/migrator"
 	"code.cloudfoundry.org/lager/v3"
 
 	"github.com/containers/storage/pkg/reexec"
@@ -105,13 +106,26 @@ func main() {
 	grootfs.Commands = []*cli.Command{
 		&commands.InitStoreCommand,
 		&commands.DeleteStoreCommand,
+		&commands.ResizeStoreCommand,
 		&commands.GenerateVolumeSizeMetadata,
 		&commands.CreateCommand,
+		&commands.CloneCommand,
 		&commands.DeleteCommand,
+		&commands.CommitCommand,
+		&commands.DeleteRefCommand,
+		&commands.ExportCommand,
+		&commands.ExportDiffCommand,
+		&commands.ResizeCommand,
 		&commands.StatsCommand,
+		&commands.InspectCommand,
+		&commands.RemountCommand,
 		&commands.CleanCommand,
 		&commands.ListCommand,
+		&commands.PinCommand,
+		&commands.UnpinCommand,
 		&commands.CapacityCommand,
+		&commands.FsckCommand,
+		&commands.MigrateStoreCommand,
 	}
 
 	grootfs.Before = func(ctx *cli.Context) error {
@@ -149,6 +163,13 @@ func main() {
 			return cli.Exit(err.Error(), 1)
 		}
 
+		// commands refuse stores laid out by a newer grootfs. Stores the user
+		// cannot read are left to fail in the command itself.
+		if _, err := migrator.CheckVersion(cfg.StorePath); err != nil && !os.IsPermission(errors.Cause(err)) {
+			logger.Error("checking-store-version", err)
+			return cli.Exit(err.Error(), 1)
+		}
+
 		return nil
 	}
 
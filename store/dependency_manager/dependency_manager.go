@@ -19,8 +19,15 @@ func NewDependencyManager(dependenciesPath string) *DependencyManager {
 	}
 }
 
+// dependencies is what is kept for each registered id. The id is kept along
+// with the chain IDs as escaping it into the file name is not reversible.
+type dependencies struct {
+	ID       string   `json:"id"`
+	ChainIDs []string `json:"chain_ids"`
+}
+
 func (d *DependencyManager) Register(id string, chainIDs []string) error {
-	data, err := json.Marshal(chainIDs)
+	data, err := json.Marshal(dependencies{ID: id, ChainIDs: chainIDs})
 	if err != nil {
 		return err
 	}
@@ -33,7 +40,7 @@ func (d *DependencyManager) Deregister(id string) error {
 }
 
 func (d *DependencyManager) Dependencies(id string) ([]string, error) {
-	f, err := os.Open(d.filePath(id))
+	deps, err := d.readDependencies(d.filePath(id))
 	if err != nil && os.IsNotExist(err) {
 		return nil, errorspkg.Errorf("image `%s` not found", id)
 	}
@@ -41,12 +48,62 @@ func (d *DependencyManager) Dependencies(id string) ([]string, error) {
 		return nil, err
 	}
 
-	var chainIDs []string
-	if err := json.NewDecoder(f).Decode(&chainIDs); err != nil {
-		return nil, err
+	return deps.ChainIDs, nil
+}
+
+// RegisteredIDs lists the ids that have dependencies registered
+func (d *DependencyManager) RegisteredIDs() ([]string, error) {
+	entries, err := os.ReadDir(d.dependenciesPath)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing dependencies")
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		deps, err := d.readDependencies(filepath.Join(d.dependenciesPath, entry.Name()))
+		if err != nil {
+			return nil, errorspkg.Wrapf(err, "reading dependencies %s", entry.Name())
+		}
+		if deps.ID == "" {
+			deps.ID = legacyID(strings.TrimSuffix(entry.Name(), ".json"))
+		}
+		ids = append(ids, deps.ID)
+	}
+
+	return ids, nil
+}
+
+// readDependencies also reads the files written before the ids were kept in
+// them, which only hold the chain IDs
+func (d *DependencyManager) readDependencies(path string) (dependencies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return dependencies{}, err
+	}
+
+	var deps dependencies
+	if err := json.Unmarshal(data, &deps); err == nil {
+		return deps, nil
+	}
+
+	if err := json.Unmarshal(data, &deps.ChainIDs); err != nil {
+		return dependencies{}, err
+	}
+	return deps, nil
+}
+
+// legacyID reverses the escaping of the file name of dependencies without an
+// id. Image and ref ids can't contain slashes, so theirs are left untouched.
+func legacyID(escapedID string) string {
+	if strings.HasPrefix(escapedID, "image:") || strings.HasPrefix(escapedID, "ref:") {
+		return escapedID
 	}
 
-	return chainIDs, nil
+	return strings.Replace(escapedID, "__", "/", -1)
 }
 
 func (d *DependencyManager) filePath(id string) string {
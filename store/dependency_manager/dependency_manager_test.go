@@ -29,6 +29,43 @@ var _ = Describe("DependencyManager", func() {
 		Expect(os.RemoveAll(depsPath)).To(Succeed())
 	})
 
+	Describe("RegisteredIDs", func() {
+		It("lists the ids with registered dependencies", func() {
+			Expect(manager.Register("image:my-image", []string{"vol-1"})).To(Succeed())
+			Expect(manager.Register("ref:my/ref", []string{"vol-1", "vol-2"})).To(Succeed())
+
+			ids, err := manager.RegisteredIDs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(ConsistOf("image:my-image", "ref:my/ref"))
+		})
+
+		It("lists ids containing the escaping sequence as they were registered", func() {
+			Expect(manager.Register("image:foo__bar", []string{"vol-1"})).To(Succeed())
+			Expect(manager.Register("pin:docker:///foo__bar", []string{"vol-1"})).To(Succeed())
+
+			ids, err := manager.RegisteredIDs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(ConsistOf("image:foo__bar", "pin:docker:///foo__bar"))
+		})
+
+		Context("when the dependencies were registered without their id", func() {
+			BeforeEach(func() {
+				Expect(os.WriteFile(path.Join(depsPath, "image:foo__bar.json"), []byte(`["vol-1"]`), 0644)).To(Succeed())
+				Expect(os.WriteFile(path.Join(depsPath, "baseimage:docker:____ubuntu.json"), []byte(`["vol-1"]`), 0644)).To(Succeed())
+			})
+
+			It("keeps image ids as they are and unescapes the others", func() {
+				ids, err := manager.RegisteredIDs()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ids).To(ConsistOf("image:foo__bar", "baseimage:docker://ubuntu"))
+			})
+
+			It("still reads their dependencies", func() {
+				Expect(manager.Dependencies("image:foo__bar")).To(Equal([]string{"vol-1"}))
+			})
+		})
+	})
+
 	Describe("Register", func() {
 		It("register the dependencies for given image id", func() {
 			imageID := "my-image"
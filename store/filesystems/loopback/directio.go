@@ -5,6 +5,7 @@ type LoSetup interface {
 	FindAssociatedLoopDevice(filePath string) (string, error)
 	EnableDirectIO(loopdevPath string) error
 	DisableDirectIO(loopdevPath string) error
+	SetCapacity(loopdevPath string) error
 }
 
 type DirectIOEnabler struct {
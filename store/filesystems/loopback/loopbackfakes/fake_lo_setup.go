@@ -43,6 +43,17 @@ type FakeLoSetup struct {
 		result1 string
 		result2 error
 	}
+	SetCapacityStub        func(string) error
+	setCapacityMutex       sync.RWMutex
+	setCapacityArgsForCall []struct {
+		arg1 string
+	}
+	setCapacityReturns struct {
+		result1 error
+	}
+	setCapacityReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -233,6 +244,67 @@ func (fake *FakeLoSetup) FindAssociatedLoopDeviceReturnsOnCall(i int, result1 st
 	}{result1, result2}
 }
 
+func (fake *FakeLoSetup) SetCapacity(arg1 string) error {
+	fake.setCapacityMutex.Lock()
+	ret, specificReturn := fake.setCapacityReturnsOnCall[len(fake.setCapacityArgsForCall)]
+	fake.setCapacityArgsForCall = append(fake.setCapacityArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.SetCapacityStub
+	fakeReturns := fake.setCapacityReturns
+	fake.recordInvocation("SetCapacity", []interface{}{arg1})
+	fake.setCapacityMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLoSetup) SetCapacityCallCount() int {
+	fake.setCapacityMutex.RLock()
+	defer fake.setCapacityMutex.RUnlock()
+	return len(fake.setCapacityArgsForCall)
+}
+
+func (fake *FakeLoSetup) SetCapacityCalls(stub func(string) error) {
+	fake.setCapacityMutex.Lock()
+	defer fake.setCapacityMutex.Unlock()
+	fake.SetCapacityStub = stub
+}
+
+func (fake *FakeLoSetup) SetCapacityArgsForCall(i int) string {
+	fake.setCapacityMutex.RLock()
+	defer fake.setCapacityMutex.RUnlock()
+	argsForCall := fake.setCapacityArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLoSetup) SetCapacityReturns(result1 error) {
+	fake.setCapacityMutex.Lock()
+	defer fake.setCapacityMutex.Unlock()
+	fake.SetCapacityStub = nil
+	fake.setCapacityReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLoSetup) SetCapacityReturnsOnCall(i int, result1 error) {
+	fake.setCapacityMutex.Lock()
+	defer fake.setCapacityMutex.Unlock()
+	fake.SetCapacityStub = nil
+	if fake.setCapacityReturnsOnCall == nil {
+		fake.setCapacityReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setCapacityReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeLoSetup) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -242,6 +314,8 @@ func (fake *FakeLoSetup) Invocations() map[string][][]interface{} {
 	defer fake.enableDirectIOMutex.RUnlock()
 	fake.findAssociatedLoopDeviceMutex.RLock()
 	defer fake.findAssociatedLoopDeviceMutex.RUnlock()
+	fake.setCapacityMutex.RLock()
+	defer fake.setCapacityMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -52,6 +52,23 @@ func (l LoSetupWrapper) DisableDirectIO(loopdevPath string) error {
 	return l.setDirectIO(loopdevPath, 0)
 }
 
+// SetCapacity makes the loop device pick up the size of its backing file
+func (l LoSetupWrapper) SetCapacity(loopdevPath string) error {
+	fd, err := os.Open(loopdevPath)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	const LOOP_SET_CAPACITY = uintptr(0x4C07)
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd.Fd()), LOOP_SET_CAPACITY, 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to set capacity on loop device: errno %d, dev %q", errno, loopdevPath)
+	}
+
+	return nil
+}
+
 func (l LoSetupWrapper) setDirectIO(loopdevPath string, enable uint) error {
 	fd, err := os.Open(loopdevPath)
 	if err != nil {
@@ -26,6 +26,8 @@ type internalDriver interface {
 	VolumePath(logger lager.Logger, id string) (string, error)
 	Volumes(logger lager.Logger) ([]string, error)
 	WriteVolumeMeta(logger lager.Logger, id string, data base_image_puller.VolumeMeta) error
+	ReadVolumeMeta(logger lager.Logger, id string) (base_image_puller.VolumeMeta, error)
+	TouchVolume(logger lager.Logger, id, imageID string) error
 	MarkVolumeArtifacts(logger lager.Logger, id string) error
 
 	CreateImage(logger lager.Logger, spec image_manager.ImageDriverSpec) (groot.MountInfo, error)
@@ -7,7 +7,7 @@ import (
 	"code.cloudfoundry.org/grootfs/base_image_puller"
 	"code.cloudfoundry.org/grootfs/groot"
 	"code.cloudfoundry.org/grootfs/store/image_manager"
-	"code.cloudfoundry.org/lager/v3"
+	lager "code.cloudfoundry.org/lager/v3"
 )
 
 type FakeInternalDriver struct {
@@ -129,6 +129,33 @@ type FakeInternalDriver struct {
 	moveVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ReadVolumeMetaStub        func(lager.Logger, string) (base_image_puller.VolumeMeta, error)
+	readVolumeMetaMutex       sync.RWMutex
+	readVolumeMetaArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	readVolumeMetaReturns struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}
+	readVolumeMetaReturnsOnCall map[int]struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}
+	TouchVolumeStub        func(lager.Logger, string, string) error
+	touchVolumeMutex       sync.RWMutex
+	touchVolumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}
+	touchVolumeReturns struct {
+		result1 error
+	}
+	touchVolumeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	VolumePathStub        func(lager.Logger, string) (string, error)
 	volumePathMutex       sync.RWMutex
 	volumePathArgsForCall []struct {
@@ -750,6 +777,134 @@ func (fake *FakeInternalDriver) MoveVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeInternalDriver) ReadVolumeMeta(arg1 lager.Logger, arg2 string) (base_image_puller.VolumeMeta, error) {
+	fake.readVolumeMetaMutex.Lock()
+	ret, specificReturn := fake.readVolumeMetaReturnsOnCall[len(fake.readVolumeMetaArgsForCall)]
+	fake.readVolumeMetaArgsForCall = append(fake.readVolumeMetaArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ReadVolumeMetaStub
+	fakeReturns := fake.readVolumeMetaReturns
+	fake.recordInvocation("ReadVolumeMeta", []interface{}{arg1, arg2})
+	fake.readVolumeMetaMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInternalDriver) ReadVolumeMetaCallCount() int {
+	fake.readVolumeMetaMutex.RLock()
+	defer fake.readVolumeMetaMutex.RUnlock()
+	return len(fake.readVolumeMetaArgsForCall)
+}
+
+func (fake *FakeInternalDriver) ReadVolumeMetaCalls(stub func(lager.Logger, string) (base_image_puller.VolumeMeta, error)) {
+	fake.readVolumeMetaMutex.Lock()
+	defer fake.readVolumeMetaMutex.Unlock()
+	fake.ReadVolumeMetaStub = stub
+}
+
+func (fake *FakeInternalDriver) ReadVolumeMetaArgsForCall(i int) (lager.Logger, string) {
+	fake.readVolumeMetaMutex.RLock()
+	defer fake.readVolumeMetaMutex.RUnlock()
+	argsForCall := fake.readVolumeMetaArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeInternalDriver) ReadVolumeMetaReturns(result1 base_image_puller.VolumeMeta, result2 error) {
+	fake.readVolumeMetaMutex.Lock()
+	defer fake.readVolumeMetaMutex.Unlock()
+	fake.ReadVolumeMetaStub = nil
+	fake.readVolumeMetaReturns = struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInternalDriver) ReadVolumeMetaReturnsOnCall(i int, result1 base_image_puller.VolumeMeta, result2 error) {
+	fake.readVolumeMetaMutex.Lock()
+	defer fake.readVolumeMetaMutex.Unlock()
+	fake.ReadVolumeMetaStub = nil
+	if fake.readVolumeMetaReturnsOnCall == nil {
+		fake.readVolumeMetaReturnsOnCall = make(map[int]struct {
+			result1 base_image_puller.VolumeMeta
+			result2 error
+		})
+	}
+	fake.readVolumeMetaReturnsOnCall[i] = struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInternalDriver) TouchVolume(arg1 lager.Logger, arg2 string, arg3 string) error {
+	fake.touchVolumeMutex.Lock()
+	ret, specificReturn := fake.touchVolumeReturnsOnCall[len(fake.touchVolumeArgsForCall)]
+	fake.touchVolumeArgsForCall = append(fake.touchVolumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.TouchVolumeStub
+	fakeReturns := fake.touchVolumeReturns
+	fake.recordInvocation("TouchVolume", []interface{}{arg1, arg2, arg3})
+	fake.touchVolumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeInternalDriver) TouchVolumeCallCount() int {
+	fake.touchVolumeMutex.RLock()
+	defer fake.touchVolumeMutex.RUnlock()
+	return len(fake.touchVolumeArgsForCall)
+}
+
+func (fake *FakeInternalDriver) TouchVolumeCalls(stub func(lager.Logger, string, string) error) {
+	fake.touchVolumeMutex.Lock()
+	defer fake.touchVolumeMutex.Unlock()
+	fake.TouchVolumeStub = stub
+}
+
+func (fake *FakeInternalDriver) TouchVolumeArgsForCall(i int) (lager.Logger, string, string) {
+	fake.touchVolumeMutex.RLock()
+	defer fake.touchVolumeMutex.RUnlock()
+	argsForCall := fake.touchVolumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInternalDriver) TouchVolumeReturns(result1 error) {
+	fake.touchVolumeMutex.Lock()
+	defer fake.touchVolumeMutex.Unlock()
+	fake.TouchVolumeStub = nil
+	fake.touchVolumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInternalDriver) TouchVolumeReturnsOnCall(i int, result1 error) {
+	fake.touchVolumeMutex.Lock()
+	defer fake.touchVolumeMutex.Unlock()
+	fake.TouchVolumeStub = nil
+	if fake.touchVolumeReturnsOnCall == nil {
+		fake.touchVolumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.touchVolumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeInternalDriver) VolumePath(arg1 lager.Logger, arg2 string) (string, error) {
 	fake.volumePathMutex.Lock()
 	ret, specificReturn := fake.volumePathReturnsOnCall[len(fake.volumePathArgsForCall)]
@@ -963,6 +1118,10 @@ func (fake *FakeInternalDriver) Invocations() map[string][][]interface{} {
 	defer fake.marshalMutex.RUnlock()
 	fake.moveVolumeMutex.RLock()
 	defer fake.moveVolumeMutex.RUnlock()
+	fake.readVolumeMetaMutex.RLock()
+	defer fake.readVolumeMetaMutex.RUnlock()
+	fake.touchVolumeMutex.RLock()
+	defer fake.touchVolumeMutex.RUnlock()
 	fake.volumePathMutex.RLock()
 	defer fake.volumePathMutex.RUnlock()
 	fake.volumesMutex.RLock()
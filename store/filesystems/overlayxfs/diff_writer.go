@@ -0,0 +1,214 @@
+package overlayxfs
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	errorspkg "github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	whiteoutPrefix       = ".wh."
+	opaqueWhiteoutName   = ".wh..wh..opq"
+	overlayOpaqueXattr   = "trusted.overlay.opaque"
+	overlayOpaqueXattrOn = "y"
+	overflowID           = 65534
+)
+
+type hardlinkKey struct {
+	dev uint64
+	ino uint64
+}
+
+// writeDiff writes the contents of an overlay upper directory as a layer
+// tarball, turning the overlay whiteout devices and opaque directories into
+// their `.wh.` tar entries. Entries are written in lexical order so that the
+// same directory contents always produce the same tarball. Owners are mapped
+// back from the host ids to the given namespace ids.
+func writeDiff(dir string, w io.Writer, uidMappings, gidMappings []groot.IDMappingSpec) error {
+	tarWriter := tar.NewWriter(w)
+	hardlinks := map[hardlinkKey]string{}
+	owner := func(stat *syscall.Stat_t) (int, int) {
+		return namespaceID(int(stat.Uid), uidMappings), namespaceID(int(stat.Gid), gidMappings)
+	}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		stat := info.Sys().(*syscall.Stat_t)
+
+		if info.Mode()&os.ModeCharDevice != 0 && stat.Rdev == 0 {
+			uid, gid := owner(stat)
+			return writeWhiteout(tarWriter, filepath.Join(filepath.Dir(relativePath), whiteoutPrefix+filepath.Base(relativePath)), info, uid, gid)
+		}
+		if info.Mode()&(os.ModeDevice|os.ModeNamedPipe|os.ModeSocket) != 0 {
+			// devices are ignored when unpacking, so there's no point in keeping them
+			return nil
+		}
+
+		linkTarget := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if linkTarget, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, linkTarget)
+		if err != nil {
+			return errorspkg.Wrapf(err, "creating header for %s", relativePath)
+		}
+		header.Name = filepath.ToSlash(relativePath)
+		header.Uid, header.Gid = owner(stat)
+		header.Uname = ""
+		header.Gname = ""
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+
+		if info.Mode().IsRegular() && stat.Nlink > 1 {
+			key := hardlinkKey{dev: uint64(stat.Dev), ino: stat.Ino}
+			if firstPath, ok := hardlinks[key]; ok {
+				header.Typeflag = tar.TypeLink
+				header.Linkname = firstPath
+				header.Size = 0
+			} else {
+				hardlinks[key] = header.Name
+			}
+		}
+
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errorspkg.Wrapf(err, "writing header for %s", relativePath)
+		}
+
+		if info.IsDir() {
+			opaque, err := isOpaqueDir(path)
+			if err != nil {
+				return err
+			}
+			if opaque {
+				return writeWhiteout(tarWriter, filepath.Join(relativePath, opaqueWhiteoutName), info, header.Uid, header.Gid)
+			}
+			return nil
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			return copyFileContents(tarWriter, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errorspkg.Wrapf(err, "writing diff of %s", dir)
+	}
+
+	return tarWriter.Close()
+}
+
+func writeWhiteout(tarWriter *tar.Writer, path string, info os.FileInfo, uid, gid int) error {
+	return tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     filepath.ToSlash(path),
+		Mode:     0,
+		Uid:      uid,
+		Gid:      gid,
+		ModTime:  info.ModTime(),
+	})
+}
+
+// namespaceID maps a host id back to its namespace id. Ids outside of the
+// mappings are shown as the overflow id, as the kernel does.
+func namespaceID(hostID int, mappings []groot.IDMappingSpec) int {
+	if len(mappings) == 0 {
+		return hostID
+	}
+
+	for _, mapping := range mappings {
+		if hostID >= mapping.HostID && hostID < mapping.HostID+mapping.Size {
+			return mapping.NamespaceID + hostID - mapping.HostID
+		}
+	}
+
+	return overflowID
+}
+
+// unmapOwners maps the owners of everything under dir back to their
+// namespace ids, the way volumes idmapped at mount time are stored
+func unmapOwners(dir string, uidMappings, gidMappings []groot.IDMappingSpec) error {
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		stat := info.Sys().(*syscall.Stat_t)
+
+		if err := os.Lchown(path, namespaceID(int(stat.Uid), uidMappings), namespaceID(int(stat.Gid), gidMappings)); err != nil {
+			return errorspkg.Wrapf(err, "chowning %s", path)
+		}
+
+		// chown drops the setuid and setgid bits of files
+		if info.Mode().IsRegular() && info.Mode()&(os.ModeSetuid|os.ModeSetgid) != 0 {
+			return os.Chmod(path, info.Mode())
+		}
+		return nil
+	})
+	if err != nil {
+		return errorspkg.Wrapf(err, "unmapping owners of %s", dir)
+	}
+
+	return nil
+}
+
+func isOpaqueDir(path string) (bool, error) {
+	value := make([]byte, 16)
+	size, err := unix.Lgetxattr(path, overlayOpaqueXattr, value)
+	if err == unix.ENODATA || err == unix.ENOTSUP || err == unix.ERANGE {
+		return false, nil
+	}
+	if err != nil {
+		return false, errorspkg.Wrapf(err, "reading opaque xattr of %s", path)
+	}
+
+	return string(value[:size]) == overlayOpaqueXattrOn, nil
+}
+
+func copyFileContents(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
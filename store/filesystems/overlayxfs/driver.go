@@ -2,6 +2,8 @@ package overlayxfs
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,12 +13,15 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/base_image_puller"
 	"code.cloudfoundry.org/grootfs/groot"
 	"code.cloudfoundry.org/grootfs/relogger"
 	"code.cloudfoundry.org/grootfs/store"
 	"code.cloudfoundry.org/grootfs/store/filesystems"
+	"code.cloudfoundry.org/grootfs/store/filesystems/loopback"
 	quotapkg "code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs/quota"
 	"code.cloudfoundry.org/grootfs/store/filesystems/spec"
 	"code.cloudfoundry.org/grootfs/store/image_manager"
@@ -33,7 +38,11 @@ const (
 	RootfsDir      = "rootfs"
 	imageInfoName  = "image_info"
 	imageQuotaName = "image_quota"
-	WhiteoutDevice = "whiteout_dev"
+	// withoutMountName marks the images whose rootfs is mounted by the caller
+	withoutMountName = "without_mount"
+	WhiteoutDevice   = "whiteout_dev"
+	// maxVolumeUsers is the number of images kept in the volume metadata
+	maxVolumeUsers = 10
 	LinksDirName   = "l"
 	MinQuota       = 1024 * 256
 )
@@ -62,6 +71,9 @@ type Driver struct {
 	tardisBinPath string
 	unmounter     Unmounter
 	directIO      DirectIO
+
+	idMappedMountsOnce      sync.Once
+	idMappedMountsSupported bool
 }
 
 func (d *Driver) InitFilesystem(logger lager.Logger, filesystemPath, storePath string) error {
@@ -90,6 +102,31 @@ func (d *Driver) MountFilesystem(logger lager.Logger, filesystemPath, storePath
 	return nil
 }
 
+// GrowFilesystem makes the mounted filesystem use all of its backing store
+// file, once the file has been extended
+func (d *Driver) GrowFilesystem(logger lager.Logger, filesystemPath, storePath string) error {
+	logger = logger.Session("overlayxfs-grow-filesystem", lager.Data{"filesystemPath": filesystemPath, "storePath": storePath})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	loSetup := loopback.NewLoSetup()
+	loopDevice, err := loSetup.FindAssociatedLoopDevice(filesystemPath)
+	if err != nil {
+		return errorspkg.Wrap(err, "finding the store loop device")
+	}
+
+	if err := loSetup.SetCapacity(loopDevice); err != nil {
+		return errorspkg.Wrap(err, "refreshing the loop device capacity")
+	}
+
+	if output, err := exec.Command("xfs_growfs", storePath).CombinedOutput(); err != nil {
+		logger.Error("growing-filesystem-failed", err, lager.Data{"output": string(output)})
+		return errorspkg.Errorf("Growing XFS filesystem: %s: %s", err, string(output))
+	}
+
+	return nil
+}
+
 func (d *Driver) DeInitFilesystem(logger lager.Logger, storePath string) error {
 	if err := d.unmounter.Unmount(logger, storePath); err != nil {
 		logger.Error("unmounting-store-path-failed", err, lager.Data{"storePath": storePath})
@@ -280,6 +317,18 @@ func (d *Driver) CreateImage(logger lager.Logger, spec image_manager.ImageDriver
 		return groot.MountInfo{}, errorspkg.Wrap(err, "applying disk limits")
 	}
 
+	mountableVolumeIDs, err := d.mountableVolumeIDs(logger, spec)
+	if err != nil {
+		logger.Error("flattening-volumes-failed", err)
+		return groot.MountInfo{}, errorspkg.Wrap(err, "flattening volumes")
+	}
+	if len(mountableVolumeIDs) != len(spec.BaseVolumeIDs) {
+		if baseVolumePaths, _, err = d.getLowerDirs(logger, mountableVolumeIDs); err != nil {
+			logger.Error("generating-lowerdir-paths-failed", err)
+			return groot.MountInfo{}, errorspkg.Wrap(err, "generating lowerdir paths failed")
+		}
+	}
+
 	upperDir := filepath.Join(spec.ImagePath, UpperDir)
 	workDir := filepath.Join(spec.ImagePath, WorkDir)
 	rootfsDir := filepath.Join(spec.ImagePath, RootfsDir)
@@ -298,6 +347,13 @@ func (d *Driver) CreateImage(logger lager.Logger, spec image_manager.ImageDriver
 		return groot.MountInfo{}, errorspkg.Wrap(err, "failed to change directory to the store path")
 	}
 
+	if spec.Mount && hasIDMappings(spec.UIDMappings, spec.GIDMappings) {
+		baseVolumePaths, err = d.mountIDMappedLowerDirs(logger, spec.ImagePath, baseVolumePaths, spec.UIDMappings, spec.GIDMappings)
+		if err != nil {
+			return groot.MountInfo{}, errorspkg.Wrap(err, "mounting idmapped lowerdirs")
+		}
+	}
+
 	if spec.Mount {
 		mountData := d.formatMountData(baseVolumePaths, workDir, upperDir, false)
 		if err := d.mountImage(logger, rootfsDir, mountData); err != nil {
@@ -310,6 +366,12 @@ func (d *Driver) CreateImage(logger lager.Logger, spec image_manager.ImageDriver
 		return groot.MountInfo{}, errorspkg.Wrapf(err, "writing image info %s", imageInfoFileName)
 	}
 
+	if !spec.Mount {
+		if err := d.MarkImageWithoutMount(logger, spec.ImagePath); err != nil {
+			return groot.MountInfo{}, err
+		}
+	}
+
 	return groot.MountInfo{
 		Destination: "/",
 		Source:      "overlay",
@@ -318,6 +380,282 @@ func (d *Driver) CreateImage(logger lager.Logger, spec image_manager.ImageDriver
 	}, nil
 }
 
+// ResizeImage changes the disk limit of an image created with one. A limit
+// including the base image has to be greater than the base volumes size
+// recorded when the image was created, and no limit can be lower than what
+// the image is already using.
+func (d *Driver) ResizeImage(logger lager.Logger, imagePath string, diskLimit int64, exclusiveDiskLimit bool) error {
+	logger = logger.Session("overlayxfs-resizing-image", lager.Data{"imagePath": imagePath, "diskLimit": diskLimit, "exclusiveDiskLimit": exclusiveDiskLimit})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if diskLimit <= 0 {
+		return errorspkg.New("disk limit must be greater than 0")
+	}
+
+	if _, err := os.Stat(filepath.Join(imagePath, imageQuotaName)); os.IsNotExist(err) {
+		return errorspkg.New("the image was created without a disk limit")
+	}
+
+	baseVolumeSize, err := readImageInfo(imagePath)
+	if err != nil {
+		logger.Error("reading-image-info-failed", err)
+		return err
+	}
+
+	stats, err := d.FetchStats(logger, imagePath)
+	if err != nil {
+		return err
+	}
+
+	exclusiveLimit := diskLimit
+	if !exclusiveDiskLimit {
+		if diskLimit <= baseVolumeSize {
+			err := errorspkg.Errorf("disk limit must be greater than the %d bytes of the base image", baseVolumeSize)
+			logger.Error("resizing-below-base-image-size", err)
+			return err
+		}
+		exclusiveLimit -= baseVolumeSize
+	}
+	if exclusiveLimit < stats.DiskUsage.ExclusiveBytesUsed {
+		err := errorspkg.Errorf("disk limit is smaller than the %d bytes already used by the image", stats.DiskUsage.ExclusiveBytesUsed)
+		logger.Error("resizing-below-usage", err)
+		return err
+	}
+
+	return d.applyDiskLimit(logger, image_manager.ImageDriverSpec{
+		ImagePath:          imagePath,
+		DiskLimit:          diskLimit,
+		ExclusiveDiskLimit: exclusiveDiskLimit,
+	}, baseVolumeSize)
+}
+
+// CloneImage creates an image on the given base volumes with a copy of the
+// upper directory of the source image. The copy uses reflinks when the
+// filesystem supports them. Without a disk limit, the clone gets the same
+// exclusive quota as the source image. The clone of an image with idmapped
+// layers needs the id mappings of the source image.
+func (d *Driver) CloneImage(logger lager.Logger, srcImagePath string, spec image_manager.ImageDriverSpec) (groot.MountInfo, error) {
+	logger = logger.Session("overlayxfs-cloning-image", lager.Data{"srcImagePath": srcImagePath, "spec": spec})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if _, err := os.Stat(filepath.Join(srcImagePath, IDMappedLowerDirsName)); err == nil && !hasIDMappings(spec.UIDMappings, spec.GIDMappings) {
+		return groot.MountInfo{}, errorspkg.New("cloning images with idmapped layers requires their id mappings")
+	}
+
+	if spec.DiskLimit == 0 {
+		quota, err := os.ReadFile(filepath.Join(srcImagePath, imageQuotaName))
+		if err == nil {
+			if spec.DiskLimit, err = strconv.ParseInt(string(quota), 10, 64); err != nil {
+				return groot.MountInfo{}, errorspkg.Wrap(err, "parsing source image quota")
+			}
+			spec.ExclusiveDiskLimit = true
+		} else if !os.IsNotExist(err) {
+			return groot.MountInfo{}, errorspkg.Wrap(err, "reading source image quota")
+		}
+	}
+
+	mount := spec.Mount
+	spec.Mount = false
+	mountInfo, err := d.CreateImage(logger, spec)
+	if err != nil {
+		return groot.MountInfo{}, err
+	}
+
+	upperDir := filepath.Join(spec.ImagePath, UpperDir)
+	srcUpperDir := filepath.Join(srcImagePath, UpperDir)
+	if output, err := exec.Command("cp", "-a", "--reflink=auto", srcUpperDir+"/.", upperDir).CombinedOutput(); err != nil {
+		logger.Error("copying-upper-dir-failed", err, lager.Data{"output": string(output)})
+		return groot.MountInfo{}, errorspkg.Errorf("copying upper dir: %s: %s", err, string(output))
+	}
+
+	if mount {
+		mountableVolumeIDs, err := d.mountableVolumeIDs(logger, spec)
+		if err != nil {
+			return groot.MountInfo{}, errorspkg.Wrap(err, "flattening volumes")
+		}
+
+		baseVolumePaths, _, err := d.getLowerDirs(logger, mountableVolumeIDs)
+		if err != nil {
+			return groot.MountInfo{}, errorspkg.Wrap(err, "generating lowerdir paths failed")
+		}
+
+		if hasIDMappings(spec.UIDMappings, spec.GIDMappings) {
+			baseVolumePaths, err = d.mountIDMappedLowerDirs(logger, spec.ImagePath, baseVolumePaths, spec.UIDMappings, spec.GIDMappings)
+			if err != nil {
+				return groot.MountInfo{}, errorspkg.Wrap(err, "mounting idmapped lowerdirs")
+			}
+		}
+
+		mountData := d.formatMountData(baseVolumePaths, filepath.Join(spec.ImagePath, WorkDir), upperDir, false)
+		if err := d.mountImage(logger, filepath.Join(spec.ImagePath, RootfsDir), mountData); err != nil {
+			return groot.MountInfo{}, err
+		}
+
+		if err := os.Remove(filepath.Join(spec.ImagePath, withoutMountName)); err != nil {
+			return groot.MountInfo{}, errorspkg.Wrap(err, "marking image as mounted")
+		}
+	}
+
+	return mountInfo, nil
+}
+
+// CommitImage turns the upper directory of an image into a new volume on top
+// of the parent volume. The volume is named after the chain ID derived from
+// the parent chain ID and the diff ID of the upper directory contents. The
+// upper directory of an image with idmapped layers is mapped back through the
+// given id mappings, as its base volumes are stored unmapped.
+func (d *Driver) CommitImage(logger lager.Logger, imagePath, parentChainID string, uidMappings, gidMappings []groot.IDMappingSpec) (groot.LayerInfo, error) {
+	logger = logger.Session("overlayxfs-committing-image", lager.Data{"imagePath": imagePath, "parentChainID": parentChainID})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	idMapped := hasIDMappings(uidMappings, gidMappings)
+	if _, err := os.Stat(filepath.Join(imagePath, IDMappedLowerDirsName)); err == nil && !idMapped {
+		return groot.LayerInfo{}, errorspkg.New("committing images with idmapped layers requires their id mappings")
+	}
+
+	upperDir := filepath.Join(imagePath, UpperDir)
+	digester := sha256.New()
+	if err := writeDiff(upperDir, digester, uidMappings, gidMappings); err != nil {
+		logger.Error("computing-diff-id-failed", err)
+		return groot.LayerInfo{}, errorspkg.Wrap(err, "computing diff id")
+	}
+	diffID := hex.EncodeToString(digester.Sum(nil))
+
+	chainID := diffID
+	if parentChainID != "" {
+		chainIDSha := sha256.Sum256([]byte(fmt.Sprintf("%s %s", parentChainID, diffID)))
+		chainID = hex.EncodeToString(chainIDSha[:])
+	}
+
+	layerInfo := groot.LayerInfo{
+		ChainID:       chainID,
+		DiffID:        diffID,
+		ParentChainID: parentChainID,
+	}
+
+	// the metadata is written before the volume is moved in place, so only the
+	// volume tells whether it was committed already
+	if _, err := os.Stat(filepath.Join(d.storePath, store.VolumesDirName, chainID)); err == nil {
+		if volumeSize, err := d.VolumeSize(logger, chainID); err == nil {
+			logger.Debug("volume-already-committed", lager.Data{"chainID": chainID})
+			layerInfo.Size = volumeSize
+			return layerInfo, nil
+		}
+	}
+
+	tempVolumeID := fmt.Sprintf("%s-incomplete-%d", chainID, time.Now().UnixNano())
+	tempVolumePath, err := d.CreateVolume(logger, parentChainID, tempVolumeID)
+	if err != nil {
+		return groot.LayerInfo{}, err
+	}
+
+	if output, err := exec.Command("cp", "-a", "--reflink=auto", upperDir+"/.", tempVolumePath).CombinedOutput(); err != nil {
+		logger.Error("copying-upper-dir-failed", err, lager.Data{"output": string(output)})
+		d.destroyTempVolume(logger, tempVolumeID)
+		return groot.LayerInfo{}, errorspkg.Errorf("copying upper dir: %s: %s", err, string(output))
+	}
+
+	if idMapped {
+		if err := unmapOwners(tempVolumePath, uidMappings, gidMappings); err != nil {
+			logger.Error("unmapping-owners-failed", err)
+			d.destroyTempVolume(logger, tempVolumeID)
+			return groot.LayerInfo{}, err
+		}
+	}
+
+	volumeSize, err := d.finalizeVolume(logger, tempVolumeID, chainID)
+	if err != nil {
+		return groot.LayerInfo{}, errorspkg.Wrap(err, "finalizing committed volume")
+	}
+	layerInfo.Size = volumeSize
+
+	return layerInfo, nil
+}
+
+// finalizeVolume records the metadata of a volume built under a temporary id
+// and moves it to its final id. The temporary volume is destroyed when this
+// fails, or when a concurrent build moved its own volume in place first.
+func (d *Driver) finalizeVolume(logger lager.Logger, tempVolumeID, volumeID string) (int64, error) {
+	tempVolumePath := filepath.Join(d.storePath, store.VolumesDirName, tempVolumeID)
+
+	volumeSize, err := calculatePathSize(logger, tempVolumePath)
+	if err != nil {
+		d.destroyTempVolume(logger, tempVolumeID)
+		return 0, errorspkg.Wrap(err, "calculating volume size")
+	}
+
+	now := time.Now()
+	if err := d.WriteVolumeMeta(logger, volumeID, base_image_puller.VolumeMeta{Size: volumeSize, CreatedAt: now, LastUsed: now}); err != nil {
+		d.destroyTempVolume(logger, tempVolumeID)
+		return 0, errorspkg.Wrap(err, "writing volume meta")
+	}
+
+	finalVolumePath := filepath.Join(d.storePath, store.VolumesDirName, volumeID)
+	if err := d.MoveVolume(logger, tempVolumePath, finalVolumePath); err != nil {
+		d.destroyTempVolume(logger, tempVolumeID)
+		return 0, errorspkg.Wrap(err, "moving volume")
+	}
+
+	// MoveVolume leaves the temporary volume behind when it lost the race
+	if _, err := os.Stat(tempVolumePath); err == nil {
+		logger.Debug("volume-finalized-concurrently", lager.Data{"volumeID": volumeID})
+		d.destroyTempVolume(logger, tempVolumeID)
+	}
+
+	return volumeSize, nil
+}
+
+// destroyTempVolume removes a volume that was being built, only logging a
+// failure, as the error that stopped the build is the one worth returning
+func (d *Driver) destroyTempVolume(logger lager.Logger, tempVolumeID string) {
+	if err := d.DestroyVolume(logger, tempVolumeID); err != nil {
+		logger.Error("destroying-temp-volume-failed", err, lager.Data{"volumeID": tempVolumeID})
+	}
+}
+
+// ExportImageDiff writes the changes made to an image as an uncompressed
+// layer tarball, with owners mapped back through the given mappings.
+func (d *Driver) ExportImageDiff(logger lager.Logger, imagePath string, w io.Writer, uidMappings, gidMappings []groot.IDMappingSpec) error {
+	logger = logger.Session("overlayxfs-exporting-image-diff", lager.Data{"imagePath": imagePath})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if err := writeDiff(filepath.Join(imagePath, UpperDir), w, uidMappings, gidMappings); err != nil {
+		logger.Error("writing-diff-failed", err)
+		return err
+	}
+
+	return nil
+}
+
+// ExportImageVolume writes one of the base volumes of an image as an
+// uncompressed layer tarball. Volumes of images with idmapped layers are kept
+// unmapped in the store, so their owners are written as they are.
+func (d *Driver) ExportImageVolume(logger lager.Logger, imagePath, volumeID string, w io.Writer, uidMappings, gidMappings []groot.IDMappingSpec) error {
+	logger = logger.Session("overlayxfs-exporting-image-volume", lager.Data{"imagePath": imagePath, "volumeID": volumeID})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	volumePath, err := d.VolumePath(logger, volumeID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(imagePath, IDMappedLowerDirsName)); err == nil {
+		uidMappings, gidMappings = nil, nil
+	}
+
+	if err := writeDiff(volumePath, w, uidMappings, gidMappings); err != nil {
+		logger.Error("writing-diff-failed", err)
+		return err
+	}
+
+	return nil
+}
+
 func (d *Driver) MoveVolume(logger lager.Logger, from, to string) error {
 	logger = logger.Session("overlayxfs-moving-volume", lager.Data{"from": from, "to": to})
 	logger.Debug("starting")
@@ -390,18 +728,103 @@ func (d *Driver) WriteVolumeMeta(logger lager.Logger, id string, metadata base_i
 	logger = logger.Session("overlayxfs-writing-volume-metadata", lager.Data{"volumeID": id})
 	logger.Debug("starting")
 	defer logger.Debug("ending")
-	metaFile, err := os.Create(d.volumeMetaFilePath(id))
+
+	// the metadata is replaced atomically, as it is read and updated by
+	// concurrent creations
+	metaFilePath := d.volumeMetaFilePath(id)
+	metaFile, err := os.CreateTemp(filepath.Dir(metaFilePath), filepath.Base(metaFilePath)+".tmp-")
 	if err != nil {
 		return errorspkg.Wrap(err, "creating metadata file")
 	}
+	defer os.Remove(metaFile.Name())
+	defer metaFile.Close()
 
 	if err = json.NewEncoder(metaFile).Encode(metadata); err != nil {
 		return errorspkg.Wrap(err, "writing metadata file")
 	}
 
+	if err := metaFile.Chmod(0644); err != nil {
+		return errorspkg.Wrap(err, "changing metadata file permissions")
+	}
+
+	if err := os.Rename(metaFile.Name(), metaFilePath); err != nil {
+		return errorspkg.Wrap(err, "moving metadata file")
+	}
+
 	return nil
 }
 
+// ReadVolumeMeta returns the metadata of a volume
+func (d *Driver) ReadVolumeMeta(logger lager.Logger, id string) (base_image_puller.VolumeMeta, error) {
+	logger = logger.Session("overlayxfs-reading-volume-metadata", lager.Data{"volumeID": id})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	metaFile, err := os.Open(d.volumeMetaFilePath(id))
+	if err != nil {
+		return base_image_puller.VolumeMeta{}, err
+	}
+	defer metaFile.Close()
+
+	var metadata base_image_puller.VolumeMeta
+	if err := json.NewDecoder(metaFile).Decode(&metadata); err != nil {
+		return base_image_puller.VolumeMeta{}, err
+	}
+
+	return metadata, nil
+}
+
+// TouchVolume records that the volume has just been used by the image. The
+// volume directory is locked while its metadata is updated, so that
+// concurrent creations sharing the volume do not lose each other's updates.
+func (d *Driver) TouchVolume(logger lager.Logger, id, imageID string) error {
+	logger = logger.Session("overlayxfs-touching-volume", lager.Data{"volumeID": id, "imageID": imageID})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	volumePath, err := d.VolumePath(logger, id)
+	if err != nil {
+		return err
+	}
+
+	volumeDir, err := os.Open(volumePath)
+	if err != nil {
+		return errorspkg.Wrapf(err, "opening volume `%s`", id)
+	}
+	defer volumeDir.Close()
+
+	if err := unix.Flock(int(volumeDir.Fd()), unix.LOCK_EX); err != nil {
+		return errorspkg.Wrapf(err, "locking volume `%s`", id)
+	}
+	defer func() {
+		_ = unix.Flock(int(volumeDir.Fd()), unix.LOCK_UN)
+	}()
+
+	metadata, err := d.ReadVolumeMeta(logger, id)
+	if err != nil {
+		return errorspkg.Wrapf(err, "reading volume `%s` metadata", id)
+	}
+
+	metadata.LastUsed = time.Now()
+	metadata.UsedBy = addVolumeUser(metadata.UsedBy, imageID)
+	return d.WriteVolumeMeta(logger, id, metadata)
+}
+
+// addVolumeUser puts the image first in the volume users, keeping the most
+// recent ones only, as base volumes can be used by any number of images
+func addVolumeUser(usedBy []string, imageID string) []string {
+	users := []string{imageID}
+	for _, user := range usedBy {
+		if len(users) == maxVolumeUsers {
+			break
+		}
+		if user != imageID {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
 func (d *Driver) MarkVolumeArtifacts(logger lager.Logger, id string) error {
 	volumePath, err := d.VolumePath(logger, id)
 	if err != nil {
@@ -588,13 +1011,7 @@ func (d *Driver) VolumeSize(logger lager.Logger, id string) (int64, error) {
 	logger.Debug("starting")
 	defer logger.Debug("ending")
 
-	metaFile, err := os.Open(d.volumeMetaFilePath(id))
-	if err != nil {
-		return 0, err
-	}
-
-	var metadata base_image_puller.VolumeMeta
-	err = json.NewDecoder(metaFile).Decode(&metadata)
+	metadata, err := d.ReadVolumeMeta(logger, id)
 	if err != nil {
 		return 0, err
 	}
@@ -617,7 +1034,8 @@ func (d *Driver) GenerateVolumeMeta(logger lager.Logger, id string) error {
 		return err
 	}
 
-	return d.WriteVolumeMeta(logger, id, base_image_puller.VolumeMeta{Size: size})
+	now := time.Now()
+	return d.WriteVolumeMeta(logger, id, base_image_puller.VolumeMeta{Size: size, CreatedAt: now, LastUsed: now})
 }
 
 func (d *Driver) volumePath(logger lager.Logger, id string) (string, error) {
@@ -630,6 +1048,15 @@ func (d *Driver) volumePath(logger lager.Logger, id string) (string, error) {
 	return "", errorspkg.Wrapf(err, "volume does not exist `%s`", id)
 }
 
+func readImageInfo(imagePath string) (int64, error) {
+	contents, err := os.ReadFile(filepath.Join(imagePath, imageInfoName))
+	if err != nil {
+		return 0, errorspkg.Wrapf(err, "reading image info %s", imagePath)
+	}
+
+	return strconv.ParseInt(string(contents), 10, 64)
+}
+
 func calculatePathSize(logger lager.Logger, path string) (int64, error) {
 	cmd := exec.Command("du", "-bs", path)
 	stdout := new(bytes.Buffer)
@@ -793,5 +1220,8 @@ func (d *Driver) ensureImageDestroyed(logger lager.Logger, imagePath string) err
 	if err := d.unmounter.Unmount(logger, filepath.Join(imagePath, RootfsDir)); err != nil {
 		return errorspkg.Wrapf(err, "unmount rootfs path %q failed", filepath.Join(imagePath, RootfsDir))
 	}
+	if err := d.unmountIDMappedLowerDirs(logger, imagePath); err != nil {
+		return err
+	}
 	return os.RemoveAll(imagePath)
 }
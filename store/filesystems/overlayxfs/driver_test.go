@@ -1,19 +1,25 @@
 package overlayxfs_test
 
 import (
+	"archive/tar"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/grootfs/base_image_puller"
+	"code.cloudfoundry.org/grootfs/groot"
 	"code.cloudfoundry.org/grootfs/store"
 	"code.cloudfoundry.org/grootfs/store/filesystems"
 	"code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs"
@@ -331,6 +337,179 @@ var _ = Describe("Driver", func() {
 			)))
 		})
 
+		Context("when the image has more layers than overlay can stack", func() {
+			var volumeIDs []string
+
+			BeforeEach(func() {
+				volumeIDs = []string{}
+				for i := 0; i < overlayxfs.MaxLowerDirs+20; i++ {
+					volumeID := fmt.Sprintf("%s-%d", randVolumeID(), i)
+					volumePath := createVolume(storePath, driver, "parent-id", volumeID, 10)
+					Expect(os.WriteFile(filepath.Join(volumePath, fmt.Sprintf("file-%d", i%10)), []byte(volumeID), 0644)).To(Succeed())
+					volumeIDs = append(volumeIDs, volumeID)
+				}
+				Expect(os.WriteFile(filepath.Join(storePath, store.VolumesDirName, volumeIDs[0], "file-gone"), []byte{}, 0644)).To(Succeed())
+				Expect(unix.Mknod(filepath.Join(storePath, store.VolumesDirName, volumeIDs[1], "file-gone"), unix.S_IFCHR, 0)).To(Succeed())
+
+				spec.BaseVolumeIDs = volumeIDs
+			})
+
+			It("mounts a squashed volume in place of the bottom layers", func() {
+				mountJson, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				lowerDirs := strings.Split(strings.Split(mountJson.Options[0], ",")[0], ":")
+				Expect(len(lowerDirs)).To(BeNumerically("<=", overlayxfs.MaxLowerDirs))
+				Expect(len(mountJson.Options[0])).To(BeNumerically("<", unix.Getpagesize()))
+
+				rootfsPath := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+				for i := 0; i < 10; i++ {
+					topVolumeID := volumeIDs[len(volumeIDs)-10+i]
+					Expect(os.ReadFile(filepath.Join(rootfsPath, fmt.Sprintf("file-%d", i)))).To(BeEquivalentTo(topVolumeID))
+				}
+			})
+
+			It("applies the whiteouts of the squashed layers", func() {
+				_, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				volumes, err := driver.Volumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				var squashedVolumeID string
+				for _, volume := range volumes {
+					if strings.HasSuffix(volume, "-squashed") {
+						squashedVolumeID = volume
+					}
+				}
+				Expect(squashedVolumeID).NotTo(BeEmpty())
+
+				squashedVolumePath := filepath.Join(storePath, store.VolumesDirName, squashedVolumeID)
+				Expect(filepath.Join(squashedVolumePath, "file-1")).To(BeAnExistingFile())
+				Expect(filepath.Join(squashedVolumePath, "file-gone")).NotTo(BeAnExistingFile())
+			})
+
+			It("reuses the squashed volume for other images", func() {
+				_, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+				volumes, err := driver.Volumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				// an image path of the same length needs the same squashing
+				anotherImagePath := spec.ImagePath[:len(spec.ImagePath)-1] + "x"
+				Expect(os.Mkdir(anotherImagePath, 0755)).To(Succeed())
+				spec.ImagePath = anotherImagePath
+				_, err = driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(driver.Volumes(logger)).To(HaveLen(len(volumes)))
+			})
+		})
+
+		Context("when squashing is requested", func() {
+			BeforeEach(func() {
+				spec.BaseVolumeIDs = []string{layer1ID, layer2ID}
+				spec.Squash = true
+			})
+
+			It("mounts a single volume flattening all the layers", func() {
+				mountJson, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mountJson.Options[0]).NotTo(ContainSubstring(":"))
+
+				rootfsPath := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+				Expect(os.ReadFile(filepath.Join(rootfsPath, "file-hello"))).To(BeEquivalentTo("hello-1"))
+				Expect(os.ReadFile(filepath.Join(rootfsPath, "file-bye"))).To(BeEquivalentTo("bye-2"))
+				Expect(os.ReadFile(filepath.Join(rootfsPath, "a-folder", "folder-file"))).To(BeEquivalentTo("in-a-folder-2"))
+			})
+
+			It("keeps the squashed volume under the top chain id", func() {
+				_, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				squashedVolumePath := filepath.Join(storePath, store.VolumesDirName, groot.SquashedVolumeID(layer2ID))
+				Expect(squashedVolumePath).To(BeADirectory())
+				Expect(driver.VolumeSize(logger, groot.SquashedVolumeID(layer2ID))).To(BeNumerically(">", 0))
+			})
+
+			It("doesn't leave incomplete volumes behind when images squash concurrently", func() {
+				anotherSpec := spec
+				anotherSpec.ImagePath = spec.ImagePath + "-another"
+				Expect(os.Mkdir(anotherSpec.ImagePath, 0755)).To(Succeed())
+
+				var wg sync.WaitGroup
+				for _, imageSpec := range []image_manager.ImageDriverSpec{spec, anotherSpec} {
+					wg.Add(1)
+					go func(imageSpec image_manager.ImageDriverSpec) {
+						defer GinkgoRecover()
+						defer wg.Done()
+						_, err := driver.CreateImage(logger, imageSpec)
+						Expect(err).ToNot(HaveOccurred())
+					}(imageSpec)
+				}
+				wg.Wait()
+
+				volumes, err := driver.Volumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+				for _, volume := range volumes {
+					Expect(volume).NotTo(ContainSubstring("incomplete"))
+				}
+			})
+		})
+
+		Context("when id mappings are given", func() {
+			BeforeEach(func() {
+				if !driver.SupportsIDMappedMounts(logger) {
+					Skip("idmapped mounts are not supported")
+				}
+
+				Expect(os.Chown(filepath.Join(layer1Path, "file-hello"), 0, 0)).To(Succeed())
+				Expect(os.Chown(filepath.Join(layer1Path, "file-bye"), 1, 1)).To(Succeed())
+
+				spec.UIDMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 100000, Size: 65536}}
+				spec.GIDMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 200000, Size: 65536}}
+			})
+
+			AfterEach(func() {
+				Expect(unix.Unmount(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir), 0)).To(Succeed())
+				Expect(unix.Unmount(filepath.Join(spec.ImagePath, overlayxfs.IDMappedLowerDirsName, "0"), 0)).To(Succeed())
+			})
+
+			It("exposes the volume files with mapped owners", func() {
+				_, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				stat, err := os.Stat(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir, "file-hello"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stat.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(100000))
+				Expect(stat.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(200000))
+
+				stat, err = os.Stat(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir, "file-bye"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stat.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(100001))
+				Expect(stat.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(200001))
+			})
+
+			It("leaves the volume files unmapped", func() {
+				_, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				stat, err := os.Stat(filepath.Join(layer1Path, "file-hello"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stat.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(0))
+				Expect(stat.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(0))
+			})
+
+			It("uses the idmapped mounts as lowerdirs", func() {
+				mountJson, err := driver.CreateImage(logger, spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mountJson.Options[0]).To(HavePrefix(fmt.Sprintf("lowerdir=%s,",
+					filepath.Join(spec.ImagePath, overlayxfs.IDMappedLowerDirsName, "0"),
+				)))
+			})
+		})
+
 		Context("when a volume metadata file is missing", func() {
 			BeforeEach(func() {
 				metaFilePath := filepath.Join(storePath, store.MetaDirName, "volume-"+layer1ID)
@@ -706,59 +885,531 @@ var _ = Describe("Driver", func() {
 
 			Expect(driver.DestroyImage(logger, spec.ImagePath)).To(Succeed())
 
-			Expect(filepath.Join(spec.ImagePath, overlayxfs.UpperDir)).ToNot(BeAnExistingFile())
-			Expect(filepath.Join(spec.ImagePath, overlayxfs.WorkDir)).ToNot(BeAnExistingFile())
-			Expect(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(spec.ImagePath, overlayxfs.UpperDir)).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(spec.ImagePath, overlayxfs.WorkDir)).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)).ToNot(BeAnExistingFile())
+		})
+
+		Context("projectids", func() {
+			BeforeEach(func() {
+				spec.DiskLimit = 1000000000
+			})
+
+			It("removes the projectid directory for that image", func() {
+				projectidsDir := filepath.Join(storePath, overlayxfs.IDDir)
+				ids, err := os.ReadDir(projectidsDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ids).To(HaveLen(1))
+
+				Expect(driver.DestroyImage(logger, spec.ImagePath)).To(Succeed())
+
+				ids, err = os.ReadDir(projectidsDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ids).To(BeEmpty())
+			})
+		})
+
+		Context("when it fails to unmount the rootfs", func() {
+			JustBeforeEach(func() {
+				unmounter.UnmountReturns(errors.New("unmount-failed"))
+			})
+
+			It("returns an error", func() {
+				err := driver.DestroyImage(logger, spec.ImagePath)
+				Expect(err).To(MatchError(ContainSubstring("deleting image path")))
+			})
+
+			It("does not delete the image directory", func() {
+				err := driver.DestroyImage(logger, spec.ImagePath)
+				Expect(err).To(HaveOccurred())
+
+				Expect(filepath.Join(spec.ImagePath, overlayxfs.UpperDir)).To(BeADirectory())
+
+				rootfsPath := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+				rootfsContent, err := os.ReadDir(rootfsPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rootfsContent).NotTo(BeEmpty())
+
+			})
+		})
+
+		Context("when there is a very long file path in the rootfs", func() {
+			It("successfully removes the rootfs", func() {
+				Expect(createVeryLongFilePath(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir))).To(Succeed())
+				Expect(driver.DestroyImage(logger, spec.ImagePath)).To(Succeed())
+				Expect(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)).NotTo(BeADirectory())
+			})
+		})
+	})
+
+	Describe("CommitImage", func() {
+		var (
+			volumeID string
+			rootfs   string
+		)
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			volumePath := createVolume(storePath, driver, "parent-id", volumeID, 3000)
+			Expect(os.WriteFile(filepath.Join(volumePath, "file-bye"), []byte("bye"), 0755)).To(Succeed())
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			rootfs = filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+			Expect(os.WriteFile(filepath.Join(rootfs, "file-hello"), []byte("hello"), 0755)).To(Succeed())
+			Expect(os.Remove(filepath.Join(rootfs, "file-bye"))).To(Succeed())
+		})
+
+		It("creates a volume with the image changes on top of the parent volume", func() {
+			layerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layerInfo.ParentChainID).To(Equal(volumeID))
+			Expect(layerInfo.DiffID).To(MatchRegexp("^[0-9a-f]{64}$"))
+			Expect(layerInfo.ChainID).To(MatchRegexp("^[0-9a-f]{64}$"))
+
+			committedVolumePath := filepath.Join(storePath, store.VolumesDirName, layerInfo.ChainID)
+			Expect(os.ReadFile(filepath.Join(committedVolumePath, "file-hello"))).To(BeEquivalentTo("hello"))
+
+			whiteoutInfo, err := os.Stat(filepath.Join(committedVolumePath, "file-bye"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(whiteoutInfo.Mode() & os.ModeCharDevice).NotTo(BeZero())
+		})
+
+		It("writes the volume metadata", func() {
+			layerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			size, err := driver.VolumeSize(logger, layerInfo.ChainID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(layerInfo.Size))
+		})
+
+		It("doesn't leave incomplete volumes behind", func() {
+			_, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			volumes, err := driver.Volumes(logger)
+			Expect(err).NotTo(HaveOccurred())
+			for _, volume := range volumes {
+				Expect(volume).NotTo(ContainSubstring("incomplete"))
+			}
+		})
+
+		Context("when the same changes were committed before", func() {
+			It("reuses the existing volume", func() {
+				firstLayerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				secondLayerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secondLayerInfo).To(Equal(firstLayerInfo))
+			})
+		})
+
+		Context("when a previous commit only left the volume metadata behind", func() {
+			It("commits the volume again", func() {
+				layerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				committedVolumePath := filepath.Join(storePath, store.VolumesDirName, layerInfo.ChainID)
+				Expect(os.RemoveAll(committedVolumePath)).To(Succeed())
+
+				_, err = driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.ReadFile(filepath.Join(committedVolumePath, "file-hello"))).To(BeEquivalentTo("hello"))
+			})
+		})
+
+		Context("when the committed volume is in place but its metadata is not", func() {
+			It("destroys the copy it built", func() {
+				layerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.Remove(filepath.Join(storePath, store.MetaDirName, "volume-"+layerInfo.ChainID))).To(Succeed())
+
+				_, err = driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				volumes, err := driver.Volumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+				for _, volume := range volumes {
+					Expect(volume).NotTo(ContainSubstring("incomplete"))
+				}
+			})
+		})
+
+		Context("when id mappings are given", func() {
+			var uidMappings, gidMappings []groot.IDMappingSpec
+
+			BeforeEach(func() {
+				uidMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 100000, Size: 65536}}
+				gidMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 200000, Size: 65536}}
+
+				Expect(os.Lchown(filepath.Join(rootfs, "file-hello"), 100005, 200006)).To(Succeed())
+			})
+
+			It("maps the owners of the image changes back to the namespace ids", func() {
+				layerInfo, err := driver.CommitImage(logger, spec.ImagePath, volumeID, uidMappings, gidMappings)
+				Expect(err).NotTo(HaveOccurred())
+
+				committedVolumePath := filepath.Join(storePath, store.VolumesDirName, layerInfo.ChainID)
+				stat, err := os.Stat(filepath.Join(committedVolumePath, "file-hello"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stat.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(5))
+				Expect(stat.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(6))
+			})
+
+			It("keeps the image changes as they are", func() {
+				_, err := driver.CommitImage(logger, spec.ImagePath, volumeID, uidMappings, gidMappings)
+				Expect(err).NotTo(HaveOccurred())
+
+				stat, err := os.Stat(filepath.Join(rootfs, "file-hello"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stat.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(100005))
+			})
+		})
+
+		Context("when the image has idmapped layers but no id mappings are given", func() {
+			BeforeEach(func() {
+				Expect(os.Mkdir(filepath.Join(spec.ImagePath, overlayxfs.IDMappedLowerDirsName), 0700)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				_, err := driver.CommitImage(logger, spec.ImagePath, volumeID, nil, nil)
+				Expect(err).To(MatchError(ContainSubstring("requires their id mappings")))
+			})
+		})
+	})
+
+	Describe("ExportImageDiff", func() {
+		var (
+			upperDir string
+			headers  map[string]*tar.Header
+		)
+
+		readHeaders := func(r io.Reader) map[string]*tar.Header {
+			headers := map[string]*tar.Header{}
+			tarReader := tar.NewReader(r)
+			for {
+				header, err := tarReader.Next()
+				if err == io.EOF {
+					return headers
+				}
+				Expect(err).NotTo(HaveOccurred())
+				headers[header.Name] = header
+			}
+		}
+
+		BeforeEach(func() {
+			volumeID := randVolumeID()
+			volumePath := createVolume(storePath, driver, "parent-id", volumeID, 3000)
+			Expect(os.WriteFile(filepath.Join(volumePath, "file-bye"), []byte("bye"), 0755)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(volumePath, "a-folder"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(volumePath, "a-folder", "old-file"), []byte("old"), 0755)).To(Succeed())
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			rootfs := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+			Expect(os.WriteFile(filepath.Join(rootfs, "file-hello"), []byte("hello"), 0755)).To(Succeed())
+			Expect(os.Chown(filepath.Join(rootfs, "file-hello"), 100001, 200002)).To(Succeed())
+			Expect(os.Remove(filepath.Join(rootfs, "file-bye"))).To(Succeed())
+			Expect(os.RemoveAll(filepath.Join(rootfs, "a-folder"))).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(rootfs, "a-folder"), 0755)).To(Succeed())
+
+			upperDir = filepath.Join(spec.ImagePath, overlayxfs.UpperDir)
+		})
+
+		JustBeforeEach(func() {
+			output := new(bytes.Buffer)
+			Expect(driver.ExportImageDiff(logger, spec.ImagePath, output,
+				[]groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+				[]groot.IDMappingSpec{{HostID: 200000, NamespaceID: 0, Size: 65536}},
+			)).To(Succeed())
+			headers = readHeaders(output)
+		})
+
+		It("writes the new files", func() {
+			Expect(headers).To(HaveKey("file-hello"))
+			Expect(headers["file-hello"].Size).To(BeEquivalentTo(len("hello")))
+		})
+
+		It("turns the whiteout devices into whiteout files", func() {
+			Expect(filepath.Join(upperDir, "file-bye")).To(BeAnExistingFile())
+			Expect(headers).To(HaveKey(".wh.file-bye"))
+			Expect(headers).NotTo(HaveKey("file-bye"))
+		})
+
+		It("turns the opaque directories into opaque whiteouts", func() {
+			Expect(headers).To(HaveKey("a-folder/"))
+			Expect(headers).To(HaveKey("a-folder/.wh..wh..opq"))
+		})
+
+		It("maps the owners back to the namespace ids", func() {
+			Expect(headers["file-hello"].Uid).To(Equal(1))
+			Expect(headers["file-hello"].Gid).To(Equal(2))
+		})
+	})
+
+	Describe("ExportImageVolume", func() {
+		var volumeID string
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			volumePath := createVolume(storePath, driver, "parent-id", volumeID, 3000)
+			Expect(os.WriteFile(filepath.Join(volumePath, "file-hello"), []byte("hello"), 0755)).To(Succeed())
+			Expect(os.Chown(filepath.Join(volumePath, "file-hello"), 100001, 100001)).To(Succeed())
+			Expect(unix.Mknod(filepath.Join(volumePath, "file-bye"), unix.S_IFCHR, 0)).To(Succeed())
+		})
+
+		It("writes the volume as a layer, with owners mapped back", func() {
+			output := new(bytes.Buffer)
+			mappings := []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}}
+			Expect(driver.ExportImageVolume(logger, spec.ImagePath, volumeID, output, mappings, mappings)).To(Succeed())
+
+			headers := map[string]*tar.Header{}
+			tarReader := tar.NewReader(output)
+			for {
+				header, err := tarReader.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				headers[header.Name] = header
+			}
+
+			Expect(headers).To(HaveKey("file-hello"))
+			Expect(headers["file-hello"].Uid).To(Equal(1))
+			Expect(headers).To(HaveKey(".wh.file-bye"))
+		})
+
+		Context("when the volume does not exist", func() {
+			It("returns an error", func() {
+				err := driver.ExportImageVolume(logger, spec.ImagePath, "not-a-volume", new(bytes.Buffer), nil, nil)
+				Expect(err).To(MatchError(ContainSubstring("volume does not exist")))
+			})
+		})
+	})
+
+	Describe("CloneImage", func() {
+		var (
+			volumeID  string
+			cloneSpec image_manager.ImageDriverSpec
+		)
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			volumePath := createVolume(storePath, driver, "parent-id", volumeID, 3000)
+			Expect(os.WriteFile(filepath.Join(volumePath, "file-bye"), []byte("bye"), 0755)).To(Succeed())
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			spec.DiskLimit = 10 * mb
+			spec.ExclusiveDiskLimit = true
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			rootfs := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+			Expect(os.WriteFile(filepath.Join(rootfs, "file-hello"), []byte("hello"), 0755)).To(Succeed())
+			Expect(os.Remove(filepath.Join(rootfs, "file-bye"))).To(Succeed())
+
+			cloneImagePath := filepath.Join(storePath, store.ImageDirName, testhelpers.NewRandomID())
+			Expect(os.Mkdir(cloneImagePath, 0755)).To(Succeed())
+			cloneSpec = image_manager.ImageDriverSpec{
+				ImagePath:     cloneImagePath,
+				BaseVolumeIDs: []string{volumeID},
+				Mount:         true,
+				OwnerUID:      123,
+				OwnerGID:      456,
+			}
+		})
+
+		It("creates an image with the source image changes", func() {
+			_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+			Expect(err).NotTo(HaveOccurred())
+
+			cloneRootfs := filepath.Join(cloneSpec.ImagePath, overlayxfs.RootfsDir)
+			Expect(os.ReadFile(filepath.Join(cloneRootfs, "file-hello"))).To(BeEquivalentTo("hello"))
+			Expect(filepath.Join(cloneRootfs, "file-bye")).NotTo(BeAnExistingFile())
+		})
+
+		It("keeps the clone independent from the source image", func() {
+			_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+			Expect(err).NotTo(HaveOccurred())
+
+			cloneRootfs := filepath.Join(cloneSpec.ImagePath, overlayxfs.RootfsDir)
+			Expect(os.WriteFile(filepath.Join(cloneRootfs, "file-hello"), []byte("bye"), 0755)).To(Succeed())
+
+			rootfs := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+			Expect(os.ReadFile(filepath.Join(rootfs, "file-hello"))).To(BeEquivalentTo("hello"))
+		})
+
+		It("gives the clone the source image quota", func() {
+			_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+			Expect(err).NotTo(HaveOccurred())
+
+			stats, err := driver.FetchStats(logger, cloneSpec.ImagePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.DiskUsage.QuotaSizeBytes).To(Equal(10 * mb))
+			ensureQuotaMatches(filepath.Join(cloneSpec.ImagePath, "image_quota"), 10*mb)
+		})
+
+		Context("when a disk limit is given", func() {
+			BeforeEach(func() {
+				cloneSpec.DiskLimit = 20 * mb
+				cloneSpec.ExclusiveDiskLimit = true
+			})
+
+			It("applies it to the clone", func() {
+				_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				ensureQuotaMatches(filepath.Join(cloneSpec.ImagePath, "image_quota"), 20*mb)
+			})
+		})
+
+		Context("when mounting is skipped", func() {
+			BeforeEach(func() {
+				cloneSpec.Mount = false
+			})
+
+			It("returns the mount information", func() {
+				mountInfo, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mountInfo.Type).To(Equal("overlay"))
+
+				Expect(os.ReadFile(filepath.Join(cloneSpec.ImagePath, overlayxfs.UpperDir, "file-hello"))).To(BeEquivalentTo("hello"))
+				Expect(filepath.Join(cloneSpec.ImagePath, overlayxfs.RootfsDir, "file-hello")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		It("can be remounted", func() {
+			_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+			Expect(err).NotTo(HaveOccurred())
+
+			cloneRootfs := filepath.Join(cloneSpec.ImagePath, overlayxfs.RootfsDir)
+			Expect(syscall.Unmount(cloneRootfs, 0)).To(Succeed())
+
+			remounted, err := driver.RemountImage(logger, cloneSpec.ImagePath, cloneSpec.BaseVolumeIDs, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remounted).To(BeTrue())
+			Expect(os.ReadFile(filepath.Join(cloneRootfs, "file-hello"))).To(BeEquivalentTo("hello"))
+		})
+
+		Context("when the source image has idmapped layers", func() {
+			BeforeEach(func() {
+				Expect(os.Mkdir(filepath.Join(spec.ImagePath, overlayxfs.IDMappedLowerDirsName), 0700)).To(Succeed())
+			})
+
+			It("returns an error without the id mappings", func() {
+				_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+				Expect(err).To(MatchError(ContainSubstring("requires their id mappings")))
+			})
+
+			Context("and the id mappings are given", func() {
+				BeforeEach(func() {
+					if !driver.SupportsIDMappedMounts(logger) {
+						Skip("idmapped mounts are not supported")
+					}
+
+					cloneSpec.UIDMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 100000, Size: 65536}}
+					cloneSpec.GIDMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 200000, Size: 65536}}
+				})
+
+				AfterEach(func() {
+					Expect(unix.Unmount(filepath.Join(cloneSpec.ImagePath, overlayxfs.RootfsDir), 0)).To(Succeed())
+					Expect(unix.Unmount(filepath.Join(cloneSpec.ImagePath, overlayxfs.IDMappedLowerDirsName, "0"), 0)).To(Succeed())
+				})
+
+				It("mounts the clone on idmapped layers", func() {
+					_, err := driver.CloneImage(logger, spec.ImagePath, cloneSpec)
+					Expect(err).NotTo(HaveOccurred())
+
+					stat, err := os.Stat(filepath.Join(cloneSpec.ImagePath, overlayxfs.RootfsDir, "file-hello"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stat.Sys().(*syscall.Stat_t).Uid).NotTo(BeEquivalentTo(0))
+					Expect(filepath.Join(cloneSpec.ImagePath, overlayxfs.IDMappedLowerDirsName, "0")).To(BeADirectory())
+				})
+			})
+		})
+	})
+
+	Describe("ResizeImage", func() {
+		writeToRootfs := func(name string, megabytes int) *gexec.Session {
+			dd := exec.Command("dd", "if=/dev/zero", fmt.Sprintf("of=%s/rootfs/%s", spec.ImagePath, name), fmt.Sprintf("count=%d", megabytes), "bs=1M")
+			sess, err := gexec.Start(dd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			return sess
+		}
+
+		BeforeEach(func() {
+			volumeID := randVolumeID()
+			createVolume(storePath, driver, "parent-id", volumeID, 3000000)
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			spec.DiskLimit = 10 * mb
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(writeToRootfs("file-1", 4)).Should(gexec.Exit(0))
 		})
 
-		Context("projectids", func() {
-			BeforeEach(func() {
-				spec.DiskLimit = 1000000000
-			})
+		It("changes the image quota, keeping the existing files accounted for", func() {
+			Expect(driver.ResizeImage(logger, spec.ImagePath, 20*mb, false)).To(Succeed())
 
-			It("removes the projectid directory for that image", func() {
-				projectidsDir := filepath.Join(storePath, overlayxfs.IDDir)
-				ids, err := os.ReadDir(projectidsDir)
-				Expect(err).NotTo(HaveOccurred())
-				Expect(ids).To(HaveLen(1))
+			stats, err := driver.FetchStats(logger, spec.ImagePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.DiskUsage.QuotaSizeBytes).To(Equal(20*mb - 3000000))
+			Expect(stats.DiskUsage.ExclusiveBytesUsed).To(Equal(int64(4202496)))
+			ensureQuotaMatches(filepath.Join(spec.ImagePath, "image_quota"), 20*mb-3000000)
 
-				Expect(driver.DestroyImage(logger, spec.ImagePath)).To(Succeed())
+			Eventually(writeToRootfs("file-2", 12)).Should(gexec.Exit(0))
+			Eventually(writeToRootfs("file-3", 2)).Should(gexec.Exit(1))
+		})
 
-				ids, err = os.ReadDir(projectidsDir)
+		Context("when the new limit excludes the base volumes", func() {
+			It("applies the whole limit to the image", func() {
+				Expect(driver.ResizeImage(logger, spec.ImagePath, 20*mb, true)).To(Succeed())
+
+				stats, err := driver.FetchStats(logger, spec.ImagePath)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(ids).To(BeEmpty())
+				Expect(stats.DiskUsage.QuotaSizeBytes).To(Equal(20 * mb))
 			})
 		})
 
-		Context("when it fails to unmount the rootfs", func() {
-			JustBeforeEach(func() {
-				unmounter.UnmountReturns(errors.New("unmount-failed"))
-			})
+		Context("when the new limit is smaller than what the image uses", func() {
+			It("returns an error and keeps the previous quota", func() {
+				err := driver.ResizeImage(logger, spec.ImagePath, 6*mb, false)
+				Expect(err).To(MatchError(ContainSubstring("already used by the image")))
 
-			It("returns an error", func() {
-				err := driver.DestroyImage(logger, spec.ImagePath)
-				Expect(err).To(MatchError(ContainSubstring("deleting image path")))
+				ensureQuotaMatches(filepath.Join(spec.ImagePath, "image_quota"), 10*mb-3000000)
 			})
+		})
 
-			It("does not delete the image directory", func() {
-				err := driver.DestroyImage(logger, spec.ImagePath)
-				Expect(err).To(HaveOccurred())
-
-				Expect(filepath.Join(spec.ImagePath, overlayxfs.UpperDir)).To(BeADirectory())
+		Context("when the new limit is smaller than the base volumes", func() {
+			It("returns an error and keeps the previous quota", func() {
+				err := driver.ResizeImage(logger, spec.ImagePath, 2*mb, false)
+				Expect(err).To(MatchError("disk limit must be greater than the 3000000 bytes of the base image"))
 
-				rootfsPath := filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
-				rootfsContent, err := os.ReadDir(rootfsPath)
-				Expect(err).NotTo(HaveOccurred())
-				Expect(rootfsContent).NotTo(BeEmpty())
+				ensureQuotaMatches(filepath.Join(spec.ImagePath, "image_quota"), 10*mb-3000000)
+			})
+		})
 
+		Context("when the new limit is the size of the base volumes", func() {
+			It("returns an error", func() {
+				err := driver.ResizeImage(logger, spec.ImagePath, 3000000, false)
+				Expect(err).To(MatchError(ContainSubstring("must be greater than the 3000000 bytes of the base image")))
 			})
 		})
 
-		Context("when there is a very long file path in the rootfs", func() {
-			It("successfully removes the rootfs", func() {
-				Expect(createVeryLongFilePath(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir))).To(Succeed())
-				Expect(driver.DestroyImage(logger, spec.ImagePath)).To(Succeed())
-				Expect(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)).NotTo(BeADirectory())
+		Context("when the image was created without a disk limit", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(filepath.Join(spec.ImagePath, "image_quota"))).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				err := driver.ResizeImage(logger, spec.ImagePath, 20*mb, false)
+				Expect(err).To(MatchError("the image was created without a disk limit"))
 			})
 		})
 	})
@@ -837,6 +1488,296 @@ var _ = Describe("Driver", func() {
 		})
 	})
 
+	Describe("InspectImage", func() {
+		BeforeEach(func() {
+			volumeID := randVolumeID()
+			createVolume(storePath, driver, "parent-id", volumeID, 3000)
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			spec.DiskLimit = 10 * mb
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("describes the image mount and limits", func() {
+			inspection, err := driver.InspectImage(logger, spec.ImagePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(inspection.Mounted).To(BeTrue())
+			Expect(inspection.MountData).To(ContainSubstring("lowerdir="))
+			Expect(inspection.MountData).To(ContainSubstring("upperdir="))
+			Expect(inspection.ExclusiveDiskLimit).To(BeEquivalentTo(10 * mb))
+			Expect(inspection.ProjectID).NotTo(BeZero())
+		})
+
+		Context("when the image rootfs is not mounted", func() {
+			BeforeEach(func() {
+				Expect(syscall.Unmount(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir), 0)).To(Succeed())
+			})
+
+			It("reports it", func() {
+				inspection, err := driver.InspectImage(logger, spec.ImagePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(inspection.Mounted).To(BeFalse())
+				Expect(inspection.MountData).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("InspectVolume", func() {
+		var volumeID string
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			createVolume(storePath, driver, "parent-id", volumeID, 3000)
+			Expect(driver.WriteVolumeMeta(logger, volumeID, base_image_puller.VolumeMeta{
+				Size:           3000,
+				SourceImage:    "docker:///cfgarden/empty",
+				ManifestDigest: "sha256:manifest",
+			})).To(Succeed())
+		})
+
+		It("describes the volume from its metadata", func() {
+			inspection, err := driver.InspectVolume(logger, volumeID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inspection.Size).To(BeEquivalentTo(3000))
+			Expect(inspection.SourceImage).To(Equal("docker:///cfgarden/empty"))
+			Expect(inspection.ManifestDigest).To(Equal("sha256:manifest"))
+		})
+	})
+
+	Describe("RemountImage", func() {
+		var (
+			volumeID  string
+			rootfsDir string
+		)
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			volumePath := createVolume(storePath, driver, "parent-id", volumeID, 3000)
+			Expect(os.WriteFile(filepath.Join(volumePath, "file-bye"), []byte("bye"), 0755)).To(Succeed())
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			rootfsDir = filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)
+		})
+
+		JustBeforeEach(func() {
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the image rootfs is no longer mounted", func() {
+			JustBeforeEach(func() {
+				Expect(os.WriteFile(filepath.Join(rootfsDir, "file-hello"), []byte("hello"), 0755)).To(Succeed())
+				Expect(syscall.Unmount(rootfsDir, 0)).To(Succeed())
+			})
+
+			It("mounts it again with the image changes", func() {
+				remounted, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeTrue())
+
+				Expect(os.ReadFile(filepath.Join(rootfsDir, "file-bye"))).To(BeEquivalentTo("bye"))
+				Expect(os.ReadFile(filepath.Join(rootfsDir, "file-hello"))).To(BeEquivalentTo("hello"))
+			})
+
+			It("does nothing the second time", func() {
+				_, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				remounted, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeFalse())
+			})
+		})
+
+		Context("when the image rootfs is still mounted", func() {
+			It("reports the image as mounted", func() {
+				Expect(driver.ImageMounted(logger, spec.ImagePath)).To(BeTrue())
+			})
+
+			It("does nothing", func() {
+				remounted, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeFalse())
+			})
+		})
+
+		Context("when the image was created without mounting it", func() {
+			BeforeEach(func() {
+				spec.Mount = false
+			})
+
+			It("does nothing", func() {
+				remounted, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeFalse())
+				Expect(os.ReadDir(rootfsDir)).To(BeEmpty())
+			})
+		})
+
+		Context("when the image was created without mounting it before grootfs recorded it", func() {
+			BeforeEach(func() {
+				spec.Mount = false
+			})
+
+			JustBeforeEach(func() {
+				Expect(os.Remove(filepath.Join(spec.ImagePath, "without_mount"))).To(Succeed())
+			})
+
+			It("does nothing once the image is marked", func() {
+				Expect(driver.ImageMounted(logger, spec.ImagePath)).To(BeFalse())
+				Expect(driver.MarkImageWithoutMount(logger, spec.ImagePath)).To(Succeed())
+
+				remounted, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeFalse())
+				Expect(os.ReadDir(rootfsDir)).To(BeEmpty())
+			})
+		})
+
+		Context("when the image has idmapped layers", func() {
+			BeforeEach(func() {
+				if !driver.SupportsIDMappedMounts(logger) {
+					Skip("idmapped mounts are not supported")
+				}
+
+				spec.UIDMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 100000, Size: 65536}}
+				spec.GIDMappings = []groot.IDMappingSpec{{NamespaceID: 0, HostID: 200000, Size: 65536}}
+			})
+
+			JustBeforeEach(func() {
+				Expect(syscall.Unmount(rootfsDir, 0)).To(Succeed())
+				Expect(syscall.Unmount(filepath.Join(spec.ImagePath, overlayxfs.IDMappedLowerDirsName, "0"), 0)).To(Succeed())
+			})
+
+			It("idmaps the layers again with the given id mappings", func() {
+				remounted, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, spec.UIDMappings, spec.GIDMappings)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remounted).To(BeTrue())
+
+				stat, err := os.Stat(filepath.Join(rootfsDir, "file-bye"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stat.Sys().(*syscall.Stat_t).Uid).To(BeNumerically(">=", 100000))
+
+				Expect(syscall.Unmount(rootfsDir, 0)).To(Succeed())
+				Expect(syscall.Unmount(filepath.Join(spec.ImagePath, overlayxfs.IDMappedLowerDirsName, "0"), 0)).To(Succeed())
+			})
+
+			It("returns an error without the id mappings", func() {
+				_, err := driver.RemountImage(logger, spec.ImagePath, spec.BaseVolumeIDs, nil, nil)
+				Expect(err).To(MatchError(ContainSubstring("requires their id mappings")))
+			})
+		})
+	})
+
+	Describe("SupportsIDMappedMounts", func() {
+		Context("when init-store recorded the support", func() {
+			BeforeEach(func() {
+				Expect(driver.RecordIDMappedMountsSupport(logger)).To(Succeed())
+			})
+
+			It("uses the recorded answer", func() {
+				recordPath := filepath.Join(storePath, store.MetaDirName, overlayxfs.IDMappedMountsSupportFilename)
+				recorded, err := os.ReadFile(recordPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(recorded)).To(Or(Equal("true"), Equal("false")))
+
+				Expect(os.WriteFile(recordPath, []byte("false"), 0644)).To(Succeed())
+				Expect(overlayxfs.NewDriver(storePath, tardisBinPath, unmounter, directIO).SupportsIDMappedMounts(logger)).To(BeFalse())
+			})
+		})
+
+		It("probes the store only once", func() {
+			supported := driver.SupportsIDMappedMounts(logger)
+
+			recordPath := filepath.Join(storePath, store.MetaDirName, overlayxfs.IDMappedMountsSupportFilename)
+			Expect(os.WriteFile(recordPath, []byte(strconv.FormatBool(!supported)), 0644)).To(Succeed())
+			Expect(driver.SupportsIDMappedMounts(logger)).To(Equal(supported))
+		})
+	})
+
+	Describe("CheckStore", func() {
+		var (
+			volumeID       string
+			brokenImageID  string
+			imageProjectID string
+		)
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			createVolume(storePath, driver, "parent-id", volumeID, 3000)
+
+			spec.BaseVolumeIDs = []string{volumeID}
+			spec.DiskLimit = 10 * mb
+			_, err := driver.CreateImage(logger, spec)
+			Expect(err).ToNot(HaveOccurred())
+			projectIDs, err := os.ReadDir(filepath.Join(storePath, overlayxfs.IDDir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(projectIDs).To(HaveLen(1))
+			imageProjectID = projectIDs[0].Name()
+
+			_, err = driver.CreateVolume(logger, "", volumeID+"-incomplete-123")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.Symlink(filepath.Join(storePath, store.VolumesDirName, "gone"), filepath.Join(storePath, overlayxfs.LinksDirName, "GoneShortID"))).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(storePath, overlayxfs.LinksDirName, "gone"), []byte("GoneShortID"), 0644)).To(Succeed())
+			Expect(os.WriteFile(volumeMetaPath(storePath, "gone"), []byte(`{"Size": 10}`), 0644)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(storePath, overlayxfs.IDDir, "9999"), 0755)).To(Succeed())
+
+			brokenImageID = testhelpers.NewRandomID()
+			Expect(os.MkdirAll(filepath.Join(storePath, store.ImageDirName, brokenImageID, overlayxfs.RootfsDir), 0755)).To(Succeed())
+		})
+
+		It("reports the inconsistencies", func() {
+			report, err := driver.CheckStore(logger, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.ImagesWithoutInfo).To(ConsistOf(brokenImageID))
+			Expect(report.IncompleteVolumes).To(ConsistOf(volumeID + "-incomplete-123"))
+			Expect(report.DanglingLinks).To(ConsistOf("GoneShortID", "gone"))
+			Expect(report.OrphanedVolumesMeta).To(ConsistOf("volume-gone"))
+			Expect(report.OrphanedProjectIDs).To(ConsistOf("9999"))
+		})
+
+		It("does not change the store", func() {
+			_, err := driver.CheckStore(logger, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(storePath, store.ImageDirName, brokenImageID)).To(BeADirectory())
+			Expect(filepath.Join(storePath, store.VolumesDirName, volumeID+"-incomplete-123")).To(BeADirectory())
+			Expect(volumeMetaPath(storePath, "gone")).To(BeAnExistingFile())
+		})
+
+		Context("when repairing", func() {
+			It("removes the inconsistencies", func() {
+				_, err := driver.CheckStore(logger, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(storePath, store.ImageDirName, brokenImageID)).NotTo(BeADirectory())
+				Expect(filepath.Join(storePath, store.VolumesDirName, volumeID+"-incomplete-123")).NotTo(BeADirectory())
+				Expect(filepath.Join(storePath, overlayxfs.LinksDirName, "gone")).NotTo(BeAnExistingFile())
+				Expect(volumeMetaPath(storePath, "gone")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(storePath, overlayxfs.IDDir, "9999")).NotTo(BeADirectory())
+
+				report, err := driver.CheckStore(logger, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Inconsistencies()).To(BeZero())
+			})
+
+			It("keeps what is in use", func() {
+				_, err := driver.CheckStore(logger, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(spec.ImagePath, overlayxfs.RootfsDir)).To(BeADirectory())
+				Expect(filepath.Join(storePath, store.VolumesDirName, volumeID)).To(BeADirectory())
+				Expect(filepath.Join(storePath, overlayxfs.LinksDirName, volumeID)).To(BeAnExistingFile())
+				Expect(volumeMetaPath(storePath, volumeID)).To(BeAnExistingFile())
+				Expect(filepath.Join(storePath, overlayxfs.IDDir, imageProjectID)).To(BeADirectory())
+			})
+		})
+	})
+
 	Describe("VolumePath", func() {
 		BeforeEach(func() {
 			Expect(os.MkdirAll(filepath.Join(storePath, store.VolumesDirName, randomID), 0755)).To(Succeed())
@@ -1372,6 +2313,124 @@ var _ = Describe("Driver", func() {
 		})
 	})
 
+	Describe("ReadVolumeMeta", func() {
+		var volumeID string
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			createVolume(storePath, driver, "parent-id", volumeID, 3000)
+		})
+
+		It("returns the volume metadata", func() {
+			createdAt := time.Now().Add(-time.Hour).Round(0)
+			Expect(driver.WriteVolumeMeta(logger, volumeID, base_image_puller.VolumeMeta{
+				Size:           3000,
+				CreatedAt:      createdAt,
+				LastUsed:       createdAt,
+				SourceImage:    "docker:///cfgarden/empty",
+				ManifestDigest: "sha256:manifest",
+			})).To(Succeed())
+
+			meta, err := driver.ReadVolumeMeta(logger, volumeID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.Size).To(BeEquivalentTo(3000))
+			Expect(meta.CreatedAt.Equal(createdAt)).To(BeTrue())
+			Expect(meta.SourceImage).To(Equal("docker:///cfgarden/empty"))
+			Expect(meta.ManifestDigest).To(Equal("sha256:manifest"))
+		})
+
+		Context("when the metadata has no timestamps", func() {
+			It("returns it as recorded, leaving the upgrade to the store migration", func() {
+				before, err := os.ReadFile(volumeMetaPath(storePath, volumeID))
+				Expect(err).NotTo(HaveOccurred())
+
+				meta, err := driver.ReadVolumeMeta(logger, volumeID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(meta.Size).To(BeEquivalentTo(3000))
+				Expect(meta.CreatedAt.IsZero()).To(BeTrue())
+
+				after, err := os.ReadFile(volumeMetaPath(storePath, volumeID))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(after).To(Equal(before))
+			})
+		})
+	})
+
+	Describe("TouchVolume", func() {
+		var volumeID string
+
+		BeforeEach(func() {
+			volumeID = randVolumeID()
+			createVolume(storePath, driver, "parent-id", volumeID, 3000)
+		})
+
+		It("updates the last use only", func() {
+			createdAt := time.Now().Add(-time.Hour)
+			Expect(driver.WriteVolumeMeta(logger, volumeID, base_image_puller.VolumeMeta{Size: 3000, CreatedAt: createdAt, LastUsed: createdAt})).To(Succeed())
+
+			Expect(driver.TouchVolume(logger, volumeID, "my-image")).To(Succeed())
+
+			meta, err := driver.ReadVolumeMeta(logger, volumeID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.Size).To(BeEquivalentTo(3000))
+			Expect(meta.CreatedAt.Equal(createdAt.Round(0))).To(BeTrue())
+			Expect(meta.LastUsed).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(meta.UsedBy).To(Equal([]string{"my-image"}))
+		})
+
+		It("keeps the last images using the volume, most recent first", func() {
+			Expect(driver.TouchVolume(logger, volumeID, "image-1")).To(Succeed())
+			Expect(driver.TouchVolume(logger, volumeID, "image-2")).To(Succeed())
+			Expect(driver.TouchVolume(logger, volumeID, "image-1")).To(Succeed())
+
+			meta, err := driver.ReadVolumeMeta(logger, volumeID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.UsedBy).To(Equal([]string{"image-1", "image-2"}))
+
+			for i := 0; i < 20; i++ {
+				Expect(driver.TouchVolume(logger, volumeID, fmt.Sprintf("image-%d", i+3))).To(Succeed())
+			}
+			meta, err = driver.ReadVolumeMeta(logger, volumeID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.UsedBy).To(HaveLen(10))
+			Expect(meta.UsedBy[0]).To(Equal("image-22"))
+		})
+
+		Context("when the metadata is missing", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(volumeMetaPath(storePath, volumeID))).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				Expect(driver.TouchVolume(logger, volumeID, "my-image")).To(MatchError(ContainSubstring("reading volume")))
+			})
+		})
+
+		Context("when the volume is touched concurrently", func() {
+			It("keeps the metadata intact", func() {
+				createdAt := time.Now().Add(-time.Hour)
+				Expect(driver.WriteVolumeMeta(logger, volumeID, base_image_puller.VolumeMeta{Size: 3000, CreatedAt: createdAt, LastUsed: createdAt})).To(Succeed())
+
+				wg := new(sync.WaitGroup)
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer GinkgoRecover()
+						defer wg.Done()
+						Expect(driver.TouchVolume(logger, volumeID, fmt.Sprintf("image-%d", i))).To(Succeed())
+					}(i)
+				}
+				wg.Wait()
+
+				meta, err := driver.ReadVolumeMeta(logger, volumeID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(meta.Size).To(BeEquivalentTo(3000))
+				Expect(meta.CreatedAt.Equal(createdAt.Round(0))).To(BeTrue())
+				Expect(meta.UsedBy).To(HaveLen(10))
+			})
+		})
+	})
+
 	Describe("GenerateVolumeMeta", func() {
 		var (
 			volumeID              string
@@ -0,0 +1,201 @@
+package overlayxfs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store"
+	quotapkg "code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs/quota"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+const incompleteVolumeMarker = "-incomplete-"
+
+// CheckStore finds what interrupted operations left behind in the store, and
+// removes it when repair is set. The caller must hold the global lock
+// exclusively, as in-progress operations look just the same.
+func (d *Driver) CheckStore(logger lager.Logger, repair bool) (groot.FsckReport, error) {
+	logger = logger.Session("overlayxfs-checking-store", lager.Data{"repair": repair})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	var (
+		report groot.FsckReport
+		err    error
+	)
+
+	// broken images go first, as destroying them releases their project ids
+	if report.ImagesWithoutInfo, err = d.checkImagesInfo(logger, repair); err != nil {
+		return groot.FsckReport{}, err
+	}
+	if report.IncompleteVolumes, err = d.checkIncompleteVolumes(logger, repair); err != nil {
+		return groot.FsckReport{}, err
+	}
+	if report.DanglingLinks, err = d.checkLinks(logger, repair); err != nil {
+		return groot.FsckReport{}, err
+	}
+	if report.OrphanedVolumesMeta, err = d.checkVolumesMeta(logger, repair); err != nil {
+		return groot.FsckReport{}, err
+	}
+	if report.OrphanedProjectIDs, err = d.checkProjectIDs(logger, repair); err != nil {
+		return groot.FsckReport{}, err
+	}
+
+	return report, nil
+}
+
+// checkImagesInfo finds the images whose creation never completed, as the
+// image info is the last thing written
+func (d *Driver) checkImagesInfo(logger lager.Logger, repair bool) ([]string, error) {
+	imagesPath := filepath.Join(d.storePath, store.ImageDirName)
+	entries, err := os.ReadDir(imagesPath)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing images")
+	}
+
+	imagesWithoutInfo := []string{}
+	for _, entry := range entries {
+		imagePath := filepath.Join(imagesPath, entry.Name())
+		if _, err := os.Stat(filepath.Join(imagePath, imageInfoName)); !os.IsNotExist(err) {
+			continue
+		}
+
+		imagesWithoutInfo = append(imagesWithoutInfo, entry.Name())
+		if repair {
+			if err := d.DestroyImage(logger, imagePath); err != nil {
+				return nil, errorspkg.Wrapf(err, "destroying image `%s`", entry.Name())
+			}
+		}
+	}
+
+	return imagesWithoutInfo, nil
+}
+
+func (d *Driver) checkIncompleteVolumes(logger lager.Logger, repair bool) ([]string, error) {
+	volumes, err := d.Volumes(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	incompleteVolumes := []string{}
+	for _, volumeID := range volumes {
+		if !strings.Contains(volumeID, incompleteVolumeMarker) {
+			continue
+		}
+
+		incompleteVolumes = append(incompleteVolumes, volumeID)
+		if repair {
+			if err := d.DestroyVolume(logger, volumeID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return incompleteVolumes, nil
+}
+
+// checkLinks finds the short id symlinks pointing nowhere, and the link files
+// of volumes that are gone
+func (d *Driver) checkLinks(logger lager.Logger, repair bool) ([]string, error) {
+	linksPath := filepath.Join(d.storePath, LinksDirName)
+	entries, err := os.ReadDir(linksPath)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing links")
+	}
+
+	danglingLinks := []string{}
+	for _, entry := range entries {
+		linkPath := filepath.Join(linksPath, entry.Name())
+		if entry.Type()&os.ModeSymlink != 0 {
+			if _, err := os.Stat(linkPath); !os.IsNotExist(err) {
+				continue
+			}
+		} else if _, err := os.Lstat(filepath.Join(d.storePath, store.VolumesDirName, entry.Name())); !os.IsNotExist(err) {
+			continue
+		}
+
+		danglingLinks = append(danglingLinks, entry.Name())
+		if repair {
+			if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+				return nil, errorspkg.Wrapf(err, "removing link `%s`", entry.Name())
+			}
+		}
+	}
+
+	return danglingLinks, nil
+}
+
+func (d *Driver) checkVolumesMeta(logger lager.Logger, repair bool) ([]string, error) {
+	metaPath := filepath.Join(d.storePath, store.MetaDirName)
+	entries, err := os.ReadDir(metaPath)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing metadata")
+	}
+
+	orphanedVolumesMeta := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "volume-") {
+			continue
+		}
+
+		volumeID := strings.TrimPrefix(entry.Name(), "volume-")
+		if _, err := os.Lstat(filepath.Join(d.storePath, store.VolumesDirName, volumeID)); !os.IsNotExist(err) {
+			continue
+		}
+
+		orphanedVolumesMeta = append(orphanedVolumesMeta, entry.Name())
+		if repair {
+			if err := os.Remove(filepath.Join(metaPath, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return nil, errorspkg.Wrapf(err, "removing metadata `%s`", entry.Name())
+			}
+		}
+	}
+
+	return orphanedVolumesMeta, nil
+}
+
+// checkProjectIDs finds the quota project ids no image has. It finds none when
+// the project id of any image can't be told.
+func (d *Driver) checkProjectIDs(logger lager.Logger, repair bool) ([]string, error) {
+	imagesPath := filepath.Join(d.storePath, store.ImageDirName)
+	images, err := os.ReadDir(imagesPath)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing images")
+	}
+
+	usedProjectIDs := map[string]bool{}
+	for _, image := range images {
+		projectID, err := quotapkg.GetProjectID(logger, filepath.Join(imagesPath, image.Name()))
+		if err != nil {
+			logger.Error("fetching-project-id-failed", err, lager.Data{"imageID": image.Name()})
+			return []string{}, nil
+		}
+		usedProjectIDs[strconv.FormatUint(uint64(projectID), 10)] = true
+	}
+
+	idsPath := filepath.Join(d.storePath, IDDir)
+	entries, err := os.ReadDir(idsPath)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "listing project ids")
+	}
+
+	orphanedProjectIDs := []string{}
+	for _, entry := range entries {
+		if usedProjectIDs[entry.Name()] {
+			continue
+		}
+
+		orphanedProjectIDs = append(orphanedProjectIDs, entry.Name())
+		if repair {
+			if err := os.RemoveAll(filepath.Join(idsPath, entry.Name())); err != nil {
+				return nil, errorspkg.Wrapf(err, "removing project id `%s`", entry.Name())
+			}
+		}
+	}
+
+	return orphanedProjectIDs, nil
+}
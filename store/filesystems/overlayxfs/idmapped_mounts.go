@@ -0,0 +1,223 @@
+package overlayxfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/containers/storage/pkg/idmap"
+	"github.com/containers/storage/pkg/idtools"
+	errorspkg "github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const IDMappedLowerDirsName = "lower"
+
+// IDMappedMountsSupportFilename records in the store meta directory whether
+// the store supports idmapped mounts, as probed by init-store
+const IDMappedMountsSupportFilename = "idmapped_mounts_support"
+
+// overlay only accepts idmapped mounts as layers from 5.19 onwards
+var minIDMappedMountsKernelVersion = [2]int{5, 19}
+
+// SupportsIDMappedMounts reports whether volumes can be kept unmapped in the
+// store and idmapped when an image is mounted. The answer recorded by
+// init-store is used when there is one, and the store is only probed once
+// per process otherwise.
+func (d *Driver) SupportsIDMappedMounts(logger lager.Logger) bool {
+	d.idMappedMountsOnce.Do(func() {
+		recorded, err := os.ReadFile(filepath.Join(d.storePath, store.MetaDirName, IDMappedMountsSupportFilename))
+		if err == nil {
+			d.idMappedMountsSupported = strings.TrimSpace(string(recorded)) == "true"
+			return
+		}
+
+		d.idMappedMountsSupported = d.probeIDMappedMounts(logger)
+	})
+
+	return d.idMappedMountsSupported
+}
+
+// RecordIDMappedMountsSupport probes the store for idmapped mounts support
+// and records the result for the later commands
+func (d *Driver) RecordIDMappedMountsSupport(logger lager.Logger) error {
+	supported := d.probeIDMappedMounts(logger)
+	recordPath := filepath.Join(d.storePath, store.MetaDirName, IDMappedMountsSupportFilename)
+	if err := os.WriteFile(recordPath, []byte(strconv.FormatBool(supported)), 0644); err != nil {
+		return errorspkg.Wrap(err, "recording idmapped mounts support")
+	}
+
+	return nil
+}
+
+// probeIDMappedMounts checks the kernel version and tries to idmap a clone
+// of the store mount, which also covers missing privileges and filesystems
+// without idmapping support.
+func (d *Driver) probeIDMappedMounts(logger lager.Logger) bool {
+	logger = logger.Session("overlayxfs-checking-idmapped-mounts-support")
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		logger.Error("uname-failed", err)
+		return false
+	}
+
+	release := unix.ByteSliceToString(uname.Release[:])
+	if !kernelVersionAtLeast(release, minIDMappedMountsKernelVersion) {
+		logger.Debug("kernel-too-old", lager.Data{"release": release})
+		return false
+	}
+
+	identity := []groot.IDMappingSpec{{NamespaceID: 0, HostID: 0, Size: 1}}
+	pid, cleanup, err := idmap.CreateUsernsProcess(toIDMaps(identity), toIDMaps(identity))
+	if err != nil {
+		logger.Debug("creating-user-namespace-failed", lager.Data{"error": err.Error()})
+		return false
+	}
+	defer cleanup()
+
+	treeFd, err := d.idmappedTree(d.storePath, pid)
+	if err != nil {
+		logger.Debug("idmapping-store-failed", lager.Data{"error": err.Error()})
+		return false
+	}
+	unix.Close(treeFd)
+
+	return true
+}
+
+// mountIDMappedLowerDirs exposes each of the given volumes through an
+// idmapped bind mount under the image path and returns the mount points, in
+// the same order and form as the volume paths it was given.
+func (d *Driver) mountIDMappedLowerDirs(logger lager.Logger, imagePath string, volumePaths []string, uidMappings, gidMappings []groot.IDMappingSpec) ([]string, error) {
+	logger = logger.Session("mounting-idmapped-lowerdirs", lager.Data{"imagePath": imagePath})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	lowerDirsPath := filepath.Join(imagePath, IDMappedLowerDirsName)
+	// the folder is already there when the image is remounted
+	if err := os.Mkdir(lowerDirsPath, 0700); err != nil && !os.IsExist(err) {
+		logger.Error("creating-lowerdirs-folder-failed", err)
+		return nil, errorspkg.Wrap(err, "creating idmapped lowerdirs folder")
+	}
+
+	pid, cleanup, err := idmap.CreateUsernsProcess(toIDMaps(uidMappings), toIDMaps(gidMappings))
+	if err != nil {
+		logger.Error("creating-user-namespace-failed", err)
+		return nil, errorspkg.Wrap(err, "creating user namespace for idmapped mounts")
+	}
+	defer cleanup()
+
+	mountPoints := []string{}
+	for i, volumePath := range volumePaths {
+		mountPoint := filepath.Join(lowerDirsPath, strconv.Itoa(i))
+		if err := idmap.CreateIDMappedMount(filepath.Join(d.storePath, volumePath), mountPoint, pid); err != nil {
+			logger.Error("creating-idmapped-mount-failed", err, lager.Data{"volumePath": volumePath})
+			return nil, errorspkg.Wrapf(err, "creating idmapped mount for %s", volumePath)
+		}
+
+		relativeMountPoint, err := filepath.Rel(d.storePath, mountPoint)
+		if err != nil {
+			return nil, errorspkg.Wrapf(err, "relativizing idmapped mount %s", mountPoint)
+		}
+		mountPoints = append(mountPoints, relativeMountPoint)
+	}
+
+	return mountPoints, nil
+}
+
+func (d *Driver) unmountIDMappedLowerDirs(logger lager.Logger, imagePath string) error {
+	lowerDirsPath := filepath.Join(imagePath, IDMappedLowerDirsName)
+	lowerDirs, err := os.ReadDir(lowerDirsPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errorspkg.Wrap(err, "listing idmapped lowerdirs")
+	}
+
+	for _, lowerDir := range lowerDirs {
+		mountPoint := filepath.Join(lowerDirsPath, lowerDir.Name())
+		if err := d.unmounter.Unmount(logger, mountPoint); err != nil {
+			return errorspkg.Wrapf(err, "unmount idmapped lowerdir %q failed", mountPoint)
+		}
+	}
+
+	return nil
+}
+
+func (d *Driver) idmappedTree(path string, usernsPid int) (int, error) {
+	userNsFile, err := os.Open(fmt.Sprintf("/proc/%d/ns/user", usernsPid))
+	if err != nil {
+		return -1, errorspkg.Wrap(err, "opening user namespace")
+	}
+	defer userNsFile.Close()
+
+	treeFd, err := unix.OpenTree(unix.AT_FDCWD, path, unix.OPEN_TREE_CLONE)
+	if err != nil {
+		return -1, errorspkg.Wrapf(err, "open_tree %s", path)
+	}
+
+	if err := unix.MountSetattr(treeFd, "", unix.AT_EMPTY_PATH, &unix.MountAttr{
+		Attr_set:  unix.MOUNT_ATTR_IDMAP,
+		Userns_fd: uint64(userNsFile.Fd()),
+	}); err != nil {
+		unix.Close(treeFd)
+		return -1, errorspkg.Wrapf(err, "mount_setattr %s", path)
+	}
+
+	return treeFd, nil
+}
+
+func hasIDMappings(uidMappings, gidMappings []groot.IDMappingSpec) bool {
+	return len(uidMappings) > 0 || len(gidMappings) > 0
+}
+
+// toIDMaps maps ids 1:1 when no mappings are given, so that images can map
+// uids without having to map gids as well (and vice versa)
+func toIDMaps(mappings []groot.IDMappingSpec) []idtools.IDMap {
+	if len(mappings) == 0 {
+		return []idtools.IDMap{{ContainerID: 0, HostID: 0, Size: 4294967295}}
+	}
+
+	idMaps := []idtools.IDMap{}
+	for _, mapping := range mappings {
+		idMaps = append(idMaps, idtools.IDMap{
+			ContainerID: mapping.NamespaceID,
+			HostID:      mapping.HostID,
+			Size:        mapping.Size,
+		})
+	}
+	return idMaps
+}
+
+func kernelVersionAtLeast(release string, minVersion [2]int) bool {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minorDigits := strings.IndexFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+	if minorDigits == -1 {
+		minorDigits = len(parts[1])
+	}
+	minor, err := strconv.Atoi(parts[1][:minorDigits])
+	if err != nil {
+		return false
+	}
+
+	if major != minVersion[0] {
+		return major > minVersion[0]
+	}
+	return minor >= minVersion[1]
+}
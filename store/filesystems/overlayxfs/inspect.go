@@ -0,0 +1,80 @@
+package overlayxfs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	quotapkg "code.cloudfoundry.org/grootfs/store/filesystems/overlayxfs/quota"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/containers/storage/pkg/mount"
+	errorspkg "github.com/pkg/errors"
+)
+
+// InspectImage tells whether the image rootfs is mounted and with which
+// overlay options, and the exclusive quota applied to it and its project id
+func (d *Driver) InspectImage(logger lager.Logger, imagePath string) (groot.ImageInspection, error) {
+	logger = logger.Session("overlayxfs-inspecting-image", lager.Data{"imagePath": imagePath})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	inspection := groot.ImageInspection{}
+
+	var err error
+	inspection.MountData, inspection.Mounted, err = overlayMountData(filepath.Join(imagePath, RootfsDir))
+	if err != nil {
+		return groot.ImageInspection{}, err
+	}
+
+	diskLimit, err := os.ReadFile(filepath.Join(imagePath, imageQuotaName))
+	if err != nil && !os.IsNotExist(err) {
+		return groot.ImageInspection{}, errorspkg.Wrap(err, "reading image quota")
+	}
+	if len(diskLimit) > 0 {
+		if inspection.ExclusiveDiskLimit, err = strconv.ParseInt(strings.TrimSpace(string(diskLimit)), 10, 64); err != nil {
+			return groot.ImageInspection{}, errorspkg.Wrap(err, "parsing image quota")
+		}
+	}
+
+	if inspection.ExclusiveDiskLimit > 0 {
+		projectID, err := quotapkg.GetProjectID(logger, imagePath)
+		if err != nil {
+			logger.Error("fetching-project-id-failed", err)
+		}
+		inspection.ProjectID = projectID
+	}
+
+	return inspection, nil
+}
+
+// InspectVolume describes a volume from its metadata
+func (d *Driver) InspectVolume(logger lager.Logger, id string) (groot.VolumeInspection, error) {
+	metadata, err := d.ReadVolumeMeta(logger, id)
+	if err != nil {
+		return groot.VolumeInspection{}, errorspkg.Wrapf(err, "reading metadata of volume %s", id)
+	}
+
+	return groot.VolumeInspection{
+		Size:           metadata.Size,
+		SourceImage:    metadata.SourceImage,
+		ManifestDigest: metadata.ManifestDigest,
+	}, nil
+}
+
+// overlayMountData returns the options of the overlay mounted at the given
+// path, if there is one
+func overlayMountData(path string) (string, bool, error) {
+	mounts, err := mount.GetMounts()
+	if err != nil {
+		return "", false, errorspkg.Wrap(err, "listing mounts")
+	}
+
+	for _, mountInfo := range mounts {
+		if mountInfo.Mountpoint == path && mountInfo.FSType == "overlay" {
+			return mountInfo.VFSOptions, true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,93 @@
+package overlayxfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+// RemountImage mounts the rootfs of an image on its base volumes again, as
+// the overlay mounts do not survive a reboot. The lower directories are
+// rebuilt from the volume links. Nothing is done when the rootfs is already
+// mounted, or when the image was created without mounting it. The layers of
+// images with idmapped layers are idmapped again with the given id mappings.
+func (d *Driver) RemountImage(logger lager.Logger, imagePath string, baseVolumeIDs []string, uidMappings, gidMappings []groot.IDMappingSpec) (bool, error) {
+	logger = logger.Session("overlayxfs-remounting-image", lager.Data{"imagePath": imagePath})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if _, err := os.Stat(filepath.Join(imagePath, withoutMountName)); err == nil {
+		logger.Debug("image-created-without-mount")
+		return false, nil
+	}
+
+	rootfsDir := filepath.Join(imagePath, RootfsDir)
+	if _, mounted, err := overlayMountData(rootfsDir); err != nil {
+		return false, err
+	} else if mounted {
+		logger.Debug("image-already-mounted")
+		return false, nil
+	}
+
+	idMapped := hasIDMappings(uidMappings, gidMappings)
+	if _, err := os.Stat(filepath.Join(imagePath, IDMappedLowerDirsName)); err == nil && !idMapped {
+		return false, errorspkg.New("remounting images with idmapped layers requires their id mappings")
+	}
+
+	mountableVolumeIDs, err := d.mountableVolumeIDs(logger, image_manager.ImageDriverSpec{
+		BaseVolumeIDs: baseVolumeIDs,
+		ImagePath:     imagePath,
+		Mount:         true,
+	})
+	if err != nil {
+		logger.Error("flattening-volumes-failed", err)
+		return false, errorspkg.Wrap(err, "flattening volumes")
+	}
+
+	lowerDirs, _, err := d.getLowerDirs(logger, mountableVolumeIDs)
+	if err != nil {
+		logger.Error("generating-lowerdir-paths-failed", err)
+		return false, errorspkg.Wrap(err, "generating lowerdir paths failed")
+	}
+
+	if err := os.Chdir(d.storePath); err != nil {
+		return false, errorspkg.Wrap(err, "failed to change directory to the store path")
+	}
+
+	if idMapped {
+		// the idmapped lowerdirs may have outlived the rootfs mount
+		if err := d.unmountIDMappedLowerDirs(logger, imagePath); err != nil {
+			return false, err
+		}
+
+		if lowerDirs, err = d.mountIDMappedLowerDirs(logger, imagePath, lowerDirs, uidMappings, gidMappings); err != nil {
+			return false, errorspkg.Wrap(err, "mounting idmapped lowerdirs")
+		}
+	}
+
+	mountData := d.formatMountData(lowerDirs, filepath.Join(imagePath, WorkDir), filepath.Join(imagePath, UpperDir), false)
+	if err := d.mountImage(logger, rootfsDir, mountData); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ImageMounted tells whether the rootfs of the image is mounted
+func (d *Driver) ImageMounted(logger lager.Logger, imagePath string) (bool, error) {
+	_, mounted, err := overlayMountData(filepath.Join(imagePath, RootfsDir))
+	return mounted, err
+}
+
+// MarkImageWithoutMount records that the rootfs of the image is mounted by the
+// caller, so that it is left alone when remounting
+func (d *Driver) MarkImageWithoutMount(logger lager.Logger, imagePath string) error {
+	if err := os.WriteFile(filepath.Join(imagePath, withoutMountName), []byte{}, 0600); err != nil {
+		return errorspkg.Wrap(err, "marking image as not mounted")
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package overlayxfs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// MaxLowerDirs is the maximum number of lower directories overlay can stack
+const MaxLowerDirs = 500
+
+// placeholderShortIDLength is longer than any generated short id, so that the
+// links of volumes yet to be squashed are never underestimated
+const placeholderShortIDLength = 24
+
+// mountableVolumeIDs returns the volumes to stack for an image. When stacking
+// all the base volumes would go over the overlay limits, the bottom ones are
+// replaced by a squashed volume, which is built and cached the first time.
+// Squashing images get all their base volumes replaced.
+func (d *Driver) mountableVolumeIDs(logger lager.Logger, spec image_manager.ImageDriverSpec) ([]string, error) {
+	volumeIDs := spec.BaseVolumeIDs
+	if spec.Squash && len(volumeIDs) > 1 {
+		if err := d.squashVolumes(logger, volumeIDs); err != nil {
+			return nil, err
+		}
+		return squashedVolumeIDs(volumeIDs, len(volumeIDs)), nil
+	}
+
+	if d.lowerDirsFit(spec, volumeIDs) {
+		return volumeIDs, nil
+	}
+
+	logger = logger.Session("flattening-volumes", lager.Data{"imagePath": spec.ImagePath, "volumes": len(volumeIDs)})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	for squashed := 2; squashed <= len(volumeIDs); squashed++ {
+		if !d.lowerDirsFit(spec, squashedVolumeIDs(volumeIDs, squashed)) {
+			continue
+		}
+
+		// a bigger squashed volume built for another image fits as well
+		for existing := len(volumeIDs); existing > squashed; existing-- {
+			squashedVolumePath := filepath.Join(d.storePath, store.VolumesDirName, groot.SquashedVolumeID(volumeIDs[existing-1]))
+			if _, err := os.Stat(squashedVolumePath); err == nil {
+				squashed = existing
+				break
+			}
+		}
+
+		if err := d.squashVolumes(logger, volumeIDs[:squashed]); err != nil {
+			return nil, err
+		}
+		return squashedVolumeIDs(volumeIDs, squashed), nil
+	}
+
+	return nil, errorspkg.New("the image layers can't be stacked")
+}
+
+func squashedVolumeIDs(volumeIDs []string, squashed int) []string {
+	return append([]string{groot.SquashedVolumeID(volumeIDs[squashed-1])}, volumeIDs[squashed:]...)
+}
+
+// lowerDirsFit checks the overlay stack depth and that the mount data fits in
+// a page, as the kernel does not take more
+func (d *Driver) lowerDirsFit(spec image_manager.ImageDriverSpec, volumeIDs []string) bool {
+	if len(volumeIDs) > MaxLowerDirs {
+		return false
+	}
+
+	idMapped := spec.Mount && (len(spec.UIDMappings) > 0 || len(spec.GIDMappings) > 0)
+	lowerDirs := []string{}
+	for i, volumeID := range volumeIDs {
+		lowerDir := filepath.Join(LinksDirName, strings.Repeat("x", placeholderShortIDLength))
+		if idMapped {
+			lowerDir = filepath.Join(store.ImageDirName, filepath.Base(spec.ImagePath), IDMappedLowerDirsName, strconv.Itoa(i))
+		} else if shortID, err := os.ReadFile(filepath.Join(d.storePath, LinksDirName, volumeID)); err == nil {
+			lowerDir = filepath.Join(LinksDirName, string(shortID))
+		}
+		lowerDirs = append(lowerDirs, lowerDir)
+	}
+
+	// the absolute form is the longest, and it is what the image mount info has
+	mountData := d.formatMountData(lowerDirs, filepath.Join(spec.ImagePath, WorkDir), filepath.Join(spec.ImagePath, UpperDir), true)
+	return len(mountData) < unix.Getpagesize()
+}
+
+// squashVolumes builds the volume flattening the given chain of volumes,
+// unless it already exists
+func (d *Driver) squashVolumes(logger lager.Logger, volumeIDs []string) error {
+	squashedVolumeID := groot.SquashedVolumeID(volumeIDs[len(volumeIDs)-1])
+	logger = logger.Session("squashing-volumes", lager.Data{"squashedVolumeID": squashedVolumeID, "volumes": len(volumeIDs)})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	finalVolumePath := filepath.Join(d.storePath, store.VolumesDirName, squashedVolumeID)
+	if _, err := os.Stat(finalVolumePath); err == nil {
+		logger.Debug("volume-already-squashed")
+		return nil
+	}
+
+	tempVolumeID := fmt.Sprintf("%s-incomplete-%d", squashedVolumeID, time.Now().UnixNano())
+	tempVolumePath, err := d.CreateVolume(logger, "", tempVolumeID)
+	if err != nil {
+		return err
+	}
+
+	for _, volumeID := range volumeIDs {
+		if err := applyVolume(logger, filepath.Join(d.storePath, store.VolumesDirName, volumeID), tempVolumePath); err != nil {
+			d.destroyTempVolume(logger, tempVolumeID)
+			return errorspkg.Wrapf(err, "squashing volume %s", volumeID)
+		}
+	}
+
+	if _, err := d.finalizeVolume(logger, tempVolumeID, squashedVolumeID); err != nil {
+		return errorspkg.Wrap(err, "finalizing squashed volume")
+	}
+
+	return nil
+}
+
+// applyVolume copies a volume on top of the squashed volume being built,
+// using reflinks when the filesystem supports them. Whiteouts and opaque
+// directories remove what the volumes below had, and are not kept, as there
+// is nothing below a squashed volume.
+func applyVolume(logger lager.Logger, volumePath, squashedVolumePath string) error {
+	whiteouts := []string{}
+	err := filepath.WalkDir(volumePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(volumePath, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+		targetPath := filepath.Join(squashedVolumePath, relativePath)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeCharDevice != 0 && info.Sys().(*syscall.Stat_t).Rdev == 0 {
+			whiteouts = append(whiteouts, targetPath)
+			return os.RemoveAll(targetPath)
+		}
+
+		targetInfo, err := os.Lstat(targetPath)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if info.IsDir() && targetInfo.IsDir() {
+			opaque, err := isOpaqueDir(path)
+			if err != nil || !opaque {
+				return err
+			}
+		} else if !info.IsDir() && !targetInfo.IsDir() {
+			return nil
+		}
+
+		// the entry replaces whatever the volumes below had at that path
+		return os.RemoveAll(targetPath)
+	})
+	if err != nil {
+		return errorspkg.Wrap(err, "applying whiteouts")
+	}
+
+	if output, err := exec.Command("cp", "-a", "--reflink=auto", "--remove-destination", volumePath+"/.", squashedVolumePath).CombinedOutput(); err != nil {
+		logger.Error("copying-volume-failed", err, lager.Data{"output": string(output)})
+		return errorspkg.Errorf("copying volume: %s: %s", err, string(output))
+	}
+
+	for _, whiteout := range whiteouts {
+		if err := os.Remove(whiteout); err != nil && !os.IsNotExist(err) {
+			return errorspkg.Wrap(err, "removing whiteout")
+		}
+	}
+
+	return nil
+}
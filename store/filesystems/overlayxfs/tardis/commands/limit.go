@@ -39,11 +39,21 @@ var LimitCommand = cli.Command{
 		imagesPath := filepath.Dir(imagePath)
 
 		diskLimit := uint64(ctx.Int64("disk-limit-bytes"))
-		idDiscoverer := ids.NewDiscoverer(filepath.Join(filepath.Dir(imagesPath), overlayxfs.IDDir))
-		projectID, err := idDiscoverer.Alloc(logger)
+		// images that already have a quota keep their project id, so that the
+		// files they hold are still accounted for after a resize
+		projectID, err := quotapkg.GetProjectID(logger, imagePath)
 		if err != nil {
-			logger.Error("allocating-project-id", err)
-			return errorspkg.Wrap(err, "allocating project id")
+			logger.Error("getting-project-id", err)
+			return errorspkg.Wrap(err, "getting project id")
+		}
+
+		if projectID == 0 {
+			idDiscoverer := ids.NewDiscoverer(filepath.Join(filepath.Dir(imagesPath), overlayxfs.IDDir))
+			projectID, err = idDiscoverer.Alloc(logger)
+			if err != nil {
+				logger.Error("allocating-project-id", err)
+				return errorspkg.Wrap(err, "allocating project id")
+			}
 		}
 
 		return func(logger lager.Logger) error {
@@ -21,6 +21,18 @@ type FakeDependencyManager struct {
 		result1 []string
 		result2 error
 	}
+	RegisteredIDsStub        func() ([]string, error)
+	registeredIDsMutex       sync.RWMutex
+	registeredIDsArgsForCall []struct {
+	}
+	registeredIDsReturns struct {
+		result1 []string
+		result2 error
+	}
+	registeredIDsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -89,11 +101,69 @@ func (fake *FakeDependencyManager) DependenciesReturnsOnCall(i int, result1 []st
 	}{result1, result2}
 }
 
+func (fake *FakeDependencyManager) RegisteredIDs() ([]string, error) {
+	fake.registeredIDsMutex.Lock()
+	ret, specificReturn := fake.registeredIDsReturnsOnCall[len(fake.registeredIDsArgsForCall)]
+	fake.registeredIDsArgsForCall = append(fake.registeredIDsArgsForCall, struct {
+	}{})
+	stub := fake.RegisteredIDsStub
+	fakeReturns := fake.registeredIDsReturns
+	fake.recordInvocation("RegisteredIDs", []interface{}{})
+	fake.registeredIDsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsCallCount() int {
+	fake.registeredIDsMutex.RLock()
+	defer fake.registeredIDsMutex.RUnlock()
+	return len(fake.registeredIDsArgsForCall)
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsCalls(stub func() ([]string, error)) {
+	fake.registeredIDsMutex.Lock()
+	defer fake.registeredIDsMutex.Unlock()
+	fake.RegisteredIDsStub = stub
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsReturns(result1 []string, result2 error) {
+	fake.registeredIDsMutex.Lock()
+	defer fake.registeredIDsMutex.Unlock()
+	fake.RegisteredIDsStub = nil
+	fake.registeredIDsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDependencyManager) RegisteredIDsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.registeredIDsMutex.Lock()
+	defer fake.registeredIDsMutex.Unlock()
+	fake.RegisteredIDsStub = nil
+	if fake.registeredIDsReturnsOnCall == nil {
+		fake.registeredIDsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.registeredIDsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDependencyManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.dependenciesMutex.RLock()
 	defer fake.dependenciesMutex.RUnlock()
+	fake.registeredIDsMutex.RLock()
+	defer fake.registeredIDsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -4,8 +4,9 @@ package garbage_collectorfakes
 import (
 	"sync"
 
+	"code.cloudfoundry.org/grootfs/base_image_puller"
 	"code.cloudfoundry.org/grootfs/store/garbage_collector"
-	"code.cloudfoundry.org/lager/v3"
+	lager "code.cloudfoundry.org/lager/v3"
 )
 
 type FakeVolumeDriver struct {
@@ -46,6 +47,20 @@ type FakeVolumeDriver struct {
 	moveVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ReadVolumeMetaStub        func(lager.Logger, string) (base_image_puller.VolumeMeta, error)
+	readVolumeMetaMutex       sync.RWMutex
+	readVolumeMetaArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	readVolumeMetaReturns struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}
+	readVolumeMetaReturnsOnCall map[int]struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}
 	VolumePathStub        func(lager.Logger, string) (string, error)
 	volumePathMutex       sync.RWMutex
 	volumePathArgsForCall []struct {
@@ -264,6 +279,71 @@ func (fake *FakeVolumeDriver) MoveVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeVolumeDriver) ReadVolumeMeta(arg1 lager.Logger, arg2 string) (base_image_puller.VolumeMeta, error) {
+	fake.readVolumeMetaMutex.Lock()
+	ret, specificReturn := fake.readVolumeMetaReturnsOnCall[len(fake.readVolumeMetaArgsForCall)]
+	fake.readVolumeMetaArgsForCall = append(fake.readVolumeMetaArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ReadVolumeMetaStub
+	fakeReturns := fake.readVolumeMetaReturns
+	fake.recordInvocation("ReadVolumeMeta", []interface{}{arg1, arg2})
+	fake.readVolumeMetaMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVolumeDriver) ReadVolumeMetaCallCount() int {
+	fake.readVolumeMetaMutex.RLock()
+	defer fake.readVolumeMetaMutex.RUnlock()
+	return len(fake.readVolumeMetaArgsForCall)
+}
+
+func (fake *FakeVolumeDriver) ReadVolumeMetaCalls(stub func(lager.Logger, string) (base_image_puller.VolumeMeta, error)) {
+	fake.readVolumeMetaMutex.Lock()
+	defer fake.readVolumeMetaMutex.Unlock()
+	fake.ReadVolumeMetaStub = stub
+}
+
+func (fake *FakeVolumeDriver) ReadVolumeMetaArgsForCall(i int) (lager.Logger, string) {
+	fake.readVolumeMetaMutex.RLock()
+	defer fake.readVolumeMetaMutex.RUnlock()
+	argsForCall := fake.readVolumeMetaArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVolumeDriver) ReadVolumeMetaReturns(result1 base_image_puller.VolumeMeta, result2 error) {
+	fake.readVolumeMetaMutex.Lock()
+	defer fake.readVolumeMetaMutex.Unlock()
+	fake.ReadVolumeMetaStub = nil
+	fake.readVolumeMetaReturns = struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVolumeDriver) ReadVolumeMetaReturnsOnCall(i int, result1 base_image_puller.VolumeMeta, result2 error) {
+	fake.readVolumeMetaMutex.Lock()
+	defer fake.readVolumeMetaMutex.Unlock()
+	fake.ReadVolumeMetaStub = nil
+	if fake.readVolumeMetaReturnsOnCall == nil {
+		fake.readVolumeMetaReturnsOnCall = make(map[int]struct {
+			result1 base_image_puller.VolumeMeta
+			result2 error
+		})
+	}
+	fake.readVolumeMetaReturnsOnCall[i] = struct {
+		result1 base_image_puller.VolumeMeta
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeVolumeDriver) VolumePath(arg1 lager.Logger, arg2 string) (string, error) {
 	fake.volumePathMutex.Lock()
 	ret, specificReturn := fake.volumePathReturnsOnCall[len(fake.volumePathArgsForCall)]
@@ -402,6 +482,8 @@ func (fake *FakeVolumeDriver) Invocations() map[string][][]interface{} {
 	defer fake.markVolumeArtifactsMutex.RUnlock()
 	fake.moveVolumeMutex.RLock()
 	defer fake.moveVolumeMutex.RUnlock()
+	fake.readVolumeMetaMutex.RLock()
+	defer fake.readVolumeMetaMutex.RUnlock()
 	fake.volumePathMutex.RLock()
 	defer fake.volumePathMutex.RUnlock()
 	fake.volumesMutex.RLock()
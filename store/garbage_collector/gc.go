@@ -3,8 +3,15 @@ package garbage_collector
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"code.cloudfoundry.org/grootfs/base_image_puller"
 	"code.cloudfoundry.org/grootfs/groot"
 	"code.cloudfoundry.org/lager/v3"
 	errorspkg "github.com/pkg/errors"
@@ -20,6 +27,7 @@ type ImageIDsGetter interface {
 
 type DependencyManager interface {
 	Dependencies(id string) ([]string, error)
+	RegisteredIDs() ([]string, error)
 }
 
 type VolumeDriver interface {
@@ -28,6 +36,7 @@ type VolumeDriver interface {
 	DestroyVolume(logger lager.Logger, id string) error
 	MarkVolumeArtifacts(logger lager.Logger, id string) error
 	Volumes(logger lager.Logger) ([]string, error)
+	ReadVolumeMeta(logger lager.Logger, id string) (base_image_puller.VolumeMeta, error)
 }
 
 type GarbageCollector struct {
@@ -155,6 +164,24 @@ func (g *GarbageCollector) UnusedVolumes(logger lager.Logger) ([]string, error)
 		g.removeDependencyFromOrphanList(orphanedVolumes, usedVolumes)
 	}
 
+	registeredIDs, err := g.dependencyManager.RegisteredIDs()
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "failed to retrieve registered dependencies")
+	}
+
+	for _, registeredID := range registeredIDs {
+		if !strings.HasPrefix(registeredID, fmt.Sprintf(groot.RefReferenceFormat, "")) &&
+			!strings.HasPrefix(registeredID, fmt.Sprintf(groot.PinReferenceFormat, "")) {
+			continue
+		}
+
+		usedVolumes, err := g.dependencyManager.Dependencies(registeredID)
+		if err != nil {
+			return nil, err
+		}
+		g.removeDependencyFromOrphanList(orphanedVolumes, usedVolumes)
+	}
+
 	orphanedVolumeIDs := []string{}
 	for id := range orphanedVolumes {
 		orphanedVolumeIDs = append(orphanedVolumeIDs, id)
@@ -165,5 +192,153 @@ func (g *GarbageCollector) UnusedVolumes(logger lager.Logger) ([]string, error)
 func (g *GarbageCollector) removeDependencyFromOrphanList(volumesList map[string]struct{}, usedVolumes []string) {
 	for _, volumeID := range usedVolumes {
 		delete(volumesList, volumeID)
+		delete(volumesList, groot.SquashedVolumeID(volumeID))
+	}
+}
+
+// LeastRecentlyUsed returns the least recently used of the volumes, as many
+// as it takes for their sizes to add up to the given bytes. Volumes without
+// metadata come first.
+func (g *GarbageCollector) LeastRecentlyUsed(logger lager.Logger, volumeIDs []string, bytes int64) ([]string, error) {
+	logger = logger.Session("least-recently-used-volumes", lager.Data{"bytes": bytes})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	volumesMeta := make(map[string]base_image_puller.VolumeMeta, len(volumeIDs))
+	for _, volumeID := range volumeIDs {
+		volumeMeta, err := g.volumeDriver.ReadVolumeMeta(logger, volumeID)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errorspkg.Wrapf(err, "reading volume `%s` metadata", volumeID)
+		}
+		volumesMeta[volumeID] = volumeMeta
+	}
+
+	sortedVolumeIDs := append([]string{}, volumeIDs...)
+	sort.SliceStable(sortedVolumeIDs, func(i, j int) bool {
+		return volumesMeta[sortedVolumeIDs[i]].LastUsed.Before(volumesMeta[sortedVolumeIDs[j]].LastUsed)
+	})
+
+	var freedBytes int64
+	for i, volumeID := range sortedVolumeIDs {
+		if freedBytes >= bytes {
+			return sortedVolumeIDs[:i], nil
+		}
+		freedBytes += volumesMeta[volumeID].Size
+	}
+
+	return sortedVolumeIDs, nil
+}
+
+// CleanCandidates describes the volumes from their metadata, measuring the
+// ones without any such as incomplete volumes
+func (g *GarbageCollector) CleanCandidates(logger lager.Logger, volumeIDs []string) ([]groot.CleanCandidate, error) {
+	candidates := []groot.CleanCandidate{}
+	for _, volumeID := range volumeIDs {
+		volumeMeta, err := g.volumeDriver.ReadVolumeMeta(logger, volumeID)
+		if os.IsNotExist(err) {
+			volumeMeta.Size = g.volumeSize(logger, volumeID)
+		} else if err != nil {
+			return nil, errorspkg.Wrapf(err, "reading volume `%s` metadata", volumeID)
+		}
+
+		candidates = append(candidates, groot.CleanCandidate{
+			ID:          volumeID,
+			Size:        volumeMeta.Size,
+			LastUsed:    volumeMeta.LastUsed,
+			ImageIDs:    volumeMeta.UsedBy,
+			SourceImage: volumeMeta.SourceImage,
+		})
 	}
+
+	return candidates, nil
+}
+
+// StaleIncompleteVolumes lists the volumes left incomplete for longer than the
+// grace period, which happens when their creation crashed
+func (g *GarbageCollector) StaleIncompleteVolumes(logger lager.Logger, gracePeriod time.Duration) ([]string, error) {
+	volumes, err := g.volumeDriver.Volumes(logger)
+	if err != nil {
+		return nil, errorspkg.Wrap(err, "failed to retrieve volume list")
+	}
+
+	staleVolumes := []string{}
+	for _, volumeID := range volumes {
+		createdAt, ok := incompleteVolumeCreationTime(volumeID)
+		if ok && time.Since(createdAt) >= gracePeriod {
+			staleVolumes = append(staleVolumes, volumeID)
+		}
+	}
+
+	return staleVolumes, nil
+}
+
+// ReclaimIncompleteVolumes destroys the stale incomplete volumes and returns
+// the bytes reclaimed
+func (g *GarbageCollector) ReclaimIncompleteVolumes(logger lager.Logger, gracePeriod time.Duration) (int64, error) {
+	logger = logger.Session("reclaim-incomplete-volumes", lager.Data{"gracePeriod": gracePeriod.String()})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	staleVolumes, err := g.StaleIncompleteVolumes(logger, gracePeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		reclaimedBytes int64
+		reclaimErr     error
+	)
+	for _, volumeID := range staleVolumes {
+		volumeSize := g.volumeSize(logger, volumeID)
+		if err := g.volumeDriver.DestroyVolume(logger, volumeID); err != nil {
+			logger.Error("failed-to-destroy-volume", err, lager.Data{"volumeID": volumeID})
+			reclaimErr = errorspkg.New("destroying incomplete volumes failed")
+			continue
+		}
+		reclaimedBytes += volumeSize
+	}
+
+	return reclaimedBytes, reclaimErr
+}
+
+// incompleteVolumeCreationTime tells the creation time from the timestamp in
+// the name of incomplete volumes: `<id>-incomplete-<nanos>[-<random>]`
+func incompleteVolumeCreationTime(volumeID string) (time.Time, bool) {
+	parts := strings.SplitN(volumeID, "-incomplete-", 2)
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+
+	nanos, err := strconv.ParseInt(strings.SplitN(parts[1], "-", 2)[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, nanos), true
+}
+
+// volumeSize adds up the size of the volume files, as incomplete volumes have
+// no metadata
+func (g *GarbageCollector) volumeSize(logger lager.Logger, volumeID string) int64 {
+	volumePath, err := g.volumeDriver.VolumePath(logger, volumeID)
+	if err != nil {
+		logger.Error("failed-to-find-volume", err, lager.Data{"volumeID": volumeID})
+		return 0
+	}
+
+	var size int64
+	err = filepath.WalkDir(volumePath, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("failed-to-measure-volume", err, lager.Data{"volumeID": volumeID})
+	}
+
+	return size
 }
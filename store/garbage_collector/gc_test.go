@@ -2,8 +2,12 @@ package garbage_collector_test
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
+	"code.cloudfoundry.org/grootfs/base_image_puller"
 	"code.cloudfoundry.org/grootfs/store/garbage_collector"
 	"code.cloudfoundry.org/grootfs/store/garbage_collector/garbage_collectorfakes"
 	"code.cloudfoundry.org/lager/v3"
@@ -73,6 +77,78 @@ var _ = Describe("Gc", func() {
 			Expect(unusedVolumes).To(ConsistOf("sha256ubuntu", "sha256privateubuntu", "unusedLayerVolume", "unusedLocalVolume-timestamp"))
 		})
 
+		Context("when there are squashed volumes", func() {
+			BeforeEach(func() {
+				fakeVolumeDriver.VolumesReturns([]string{
+					"volDocker1",
+					"volDocker2",
+					"volDocker2-squashed",
+					"unusedLayerVolume",
+					"unusedLayerVolume-squashed",
+				}, nil)
+			})
+
+			It("keeps the squashed volumes of the chains in use", func() {
+				unusedVolumes, err := garbageCollector.UnusedVolumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unusedVolumes).To(ConsistOf("unusedLayerVolume", "unusedLayerVolume-squashed"))
+			})
+		})
+
+		Context("when there are committed refs", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.RegisteredIDsReturns([]string{"image:idA", "ref:my-ref", "baseimage:docker:///ubuntu"}, nil)
+				fakeDependencyManager.DependenciesStub = func(id string) ([]string, error) {
+					return map[string][]string{
+						"image:idA":                  []string{"volDocker1", "volDocker2"},
+						"ref:my-ref":                 []string{"volDocker1", "unusedLayerVolume"},
+						"baseimage:docker:///ubuntu": []string{"sha256ubuntu"},
+					}[id], nil
+				}
+				fakeImageIDsGetter.ImageIDsReturns([]string{"idA"}, nil)
+			})
+
+			It("keeps the volumes the refs depend on", func() {
+				unusedVolumes, err := garbageCollector.UnusedVolumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unusedVolumes).To(ConsistOf("volDocker3", "usedLocalVolume-timestamp", "unusedLocalVolume-timestamp", "sha256ubuntu", "sha256privateubuntu"))
+			})
+		})
+
+		Context("when there are pinned images", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.RegisteredIDsReturns([]string{"image:idA", "pin:docker:///ubuntu"}, nil)
+				fakeDependencyManager.DependenciesStub = func(id string) ([]string, error) {
+					return map[string][]string{
+						"image:idA":            []string{"volDocker1", "volDocker2"},
+						"pin:docker:///ubuntu": []string{"sha256ubuntu"},
+					}[id], nil
+				}
+				fakeImageIDsGetter.ImageIDsReturns([]string{"idA"}, nil)
+			})
+
+			It("keeps the volumes the pins depend on", func() {
+				unusedVolumes, err := garbageCollector.UnusedVolumes(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unusedVolumes).NotTo(ContainElement("sha256ubuntu"))
+				Expect(unusedVolumes).To(ContainElement("sha256privateubuntu"))
+			})
+		})
+
+		Context("when listing the registered dependencies fails", func() {
+			BeforeEach(func() {
+				fakeDependencyManager.RegisteredIDsReturns(nil, errors.New("failed to list deps"))
+			})
+
+			It("returns an error", func() {
+				_, err := garbageCollector.UnusedVolumes(logger)
+				Expect(err).To(MatchError(ContainSubstring("failed to list deps")))
+			})
+		})
+
 		Context("when retrieving images fails", func() {
 			BeforeEach(func() {
 				fakeImageIDsGetter.ImageIDsReturns(nil, errors.New("failed to retrieve images"))
@@ -190,4 +266,153 @@ var _ = Describe("Gc", func() {
 			})
 		})
 	})
+
+	Describe("LeastRecentlyUsed", func() {
+		BeforeEach(func() {
+			now := time.Now()
+			fakeVolumeDriver.ReadVolumeMetaStub = func(_ lager.Logger, id string) (base_image_puller.VolumeMeta, error) {
+				volumesMeta := map[string]base_image_puller.VolumeMeta{
+					"vol-recent": {Size: 100, LastUsed: now},
+					"vol-old":    {Size: 200, LastUsed: now.Add(-2 * time.Hour)},
+					"vol-older":  {Size: 300, LastUsed: now.Add(-3 * time.Hour)},
+				}
+				volumeMeta, ok := volumesMeta[id]
+				if !ok {
+					return base_image_puller.VolumeMeta{}, os.ErrNotExist
+				}
+				return volumeMeta, nil
+			}
+		})
+
+		It("returns the least recently used volumes adding up to the bytes", func() {
+			volumes, err := garbageCollector.LeastRecentlyUsed(logger, []string{"vol-recent", "vol-old", "vol-older"}, 400)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(Equal([]string{"vol-older", "vol-old"}))
+		})
+
+		It("returns all the volumes when they don't add up to the bytes", func() {
+			volumes, err := garbageCollector.LeastRecentlyUsed(logger, []string{"vol-recent", "vol-old", "vol-older"}, 1000)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(Equal([]string{"vol-older", "vol-old", "vol-recent"}))
+		})
+
+		It("puts the volumes without metadata first", func() {
+			volumes, err := garbageCollector.LeastRecentlyUsed(logger, []string{"vol-recent", "vol-no-meta", "vol-older"}, 300)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(Equal([]string{"vol-no-meta", "vol-older"}))
+		})
+
+		Context("when reading the metadata fails", func() {
+			BeforeEach(func() {
+				fakeVolumeDriver.ReadVolumeMetaReturns(base_image_puller.VolumeMeta{}, errors.New("corrupted"))
+				fakeVolumeDriver.ReadVolumeMetaStub = nil
+			})
+
+			It("returns an error", func() {
+				_, err := garbageCollector.LeastRecentlyUsed(logger, []string{"vol-recent"}, 100)
+				Expect(err).To(MatchError(ContainSubstring("corrupted")))
+			})
+		})
+	})
+
+	Describe("CleanCandidates", func() {
+		var volumePath string
+
+		BeforeEach(func() {
+			fakeVolumeDriver.ReadVolumeMetaStub = func(_ lager.Logger, id string) (base_image_puller.VolumeMeta, error) {
+				if id == "vol-no-meta" {
+					return base_image_puller.VolumeMeta{}, os.ErrNotExist
+				}
+				return base_image_puller.VolumeMeta{Size: 100, UsedBy: []string{"image-2", "image-1"}, SourceImage: "docker:///cfgarden/empty"}, nil
+			}
+
+			var err error
+			volumePath, err = os.MkdirTemp("", "vol-no-meta")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(volumePath, "file"), make([]byte, 1000), 0644)).To(Succeed())
+			fakeVolumeDriver.VolumePathReturns(volumePath, nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumePath)).To(Succeed())
+		})
+
+		It("describes the volumes from their metadata", func() {
+			candidates, err := garbageCollector.CleanCandidates(logger, []string{"vol-a", "vol-no-meta"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(HaveLen(2))
+			Expect(candidates[0].ID).To(Equal("vol-a"))
+			Expect(candidates[0].Size).To(BeEquivalentTo(100))
+			Expect(candidates[0].ImageIDs).To(Equal([]string{"image-2", "image-1"}))
+			Expect(candidates[0].SourceImage).To(Equal("docker:///cfgarden/empty"))
+			Expect(candidates[1].ID).To(Equal("vol-no-meta"))
+			Expect(candidates[1].Size).To(BeEquivalentTo(1000))
+		})
+	})
+
+	Describe("ReclaimIncompleteVolumes", func() {
+		var volumesPath string
+
+		BeforeEach(func() {
+			var err error
+			volumesPath, err = os.MkdirTemp("", "volumes")
+			Expect(err).NotTo(HaveOccurred())
+
+			staleVolumeID := fmt.Sprintf("layer-1-incomplete-%d-4242", time.Now().Add(-2*time.Hour).UnixNano())
+			Expect(os.MkdirAll(filepath.Join(volumesPath, staleVolumeID, "dir"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(volumesPath, staleVolumeID, "dir", "file"), make([]byte, 1000), 0644)).To(Succeed())
+
+			fakeVolumeDriver.VolumesReturns([]string{
+				"layer-1",
+				staleVolumeID,
+				fmt.Sprintf("layer-2-incomplete-%d", time.Now().UnixNano()),
+				"layer-3-incomplete-notatimestamp",
+			}, nil)
+			fakeVolumeDriver.VolumePathStub = func(_ lager.Logger, id string) (string, error) {
+				return filepath.Join(volumesPath, id), nil
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(volumesPath)).To(Succeed())
+		})
+
+		It("destroys the incomplete volumes older than the grace period", func() {
+			_, err := garbageCollector.ReclaimIncompleteVolumes(logger, time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeVolumeDriver.DestroyVolumeCallCount()).To(Equal(1))
+			_, volumeID := fakeVolumeDriver.DestroyVolumeArgsForCall(0)
+			Expect(volumeID).To(HavePrefix("layer-1-incomplete-"))
+		})
+
+		It("returns the reclaimed bytes", func() {
+			reclaimedBytes, err := garbageCollector.ReclaimIncompleteVolumes(logger, time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimedBytes).To(BeEquivalentTo(1000))
+		})
+
+		Context("when destroying a volume fails", func() {
+			BeforeEach(func() {
+				fakeVolumeDriver.DestroyVolumeReturns(errors.New("busy"))
+			})
+
+			It("returns an error", func() {
+				reclaimedBytes, err := garbageCollector.ReclaimIncompleteVolumes(logger, time.Hour)
+				Expect(err).To(MatchError(ContainSubstring("destroying incomplete volumes failed")))
+				Expect(reclaimedBytes).To(BeZero())
+			})
+		})
+
+		Context("when retrieving volume list fails", func() {
+			BeforeEach(func() {
+				fakeVolumeDriver.VolumesReturns(nil, errors.New("failed to retrieve volume list"))
+			})
+
+			It("returns an error", func() {
+				_, err := garbageCollector.ReclaimIncompleteVolumes(logger, time.Hour)
+				Expect(err).To(MatchError(ContainSubstring("failed to retrieve volume list")))
+			})
+		})
+	})
 })
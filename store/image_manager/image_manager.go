@@ -1,10 +1,14 @@
 package image_manager // import "code.cloudfoundry.org/grootfs/store/image_manager"
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"code.cloudfoundry.org/grootfs/groot"
 	"code.cloudfoundry.org/grootfs/store"
@@ -21,6 +25,12 @@ type ImageDriverSpec struct {
 	ExclusiveDiskLimit bool
 	OwnerUID           int
 	OwnerGID           int
+	// IDMappings are only set when the base volumes are stored unmapped and
+	// need to be idmapped when the image is mounted
+	UIDMappings []groot.IDMappingSpec
+	GIDMappings []groot.IDMappingSpec
+	// Squash stacks a single volume flattening all the base volumes
+	Squash bool
 }
 
 //go:generate counterfeiter . ImageDriver
@@ -30,6 +40,82 @@ type ImageDriver interface {
 	FetchStats(logger lager.Logger, path string) (groot.VolumeStats, error)
 }
 
+//go:generate counterfeiter . ImageCommitter
+
+// ImageCommitter is implemented by the image drivers able to turn the
+// changes made to an image into a new volume. The id mappings are only given
+// for images whose layers are idmapped at mount time.
+type ImageCommitter interface {
+	CommitImage(logger lager.Logger, imagePath, parentChainID string, uidMappings, gidMappings []groot.IDMappingSpec) (groot.LayerInfo, error)
+}
+
+//go:generate counterfeiter . ImageDiffExporter
+
+// ImageDiffExporter is implemented by the image drivers able to write the
+// changes made to an image, and its base volumes, as layer tarballs
+type ImageDiffExporter interface {
+	ExportImageDiff(logger lager.Logger, imagePath string, w io.Writer, uidMappings, gidMappings []groot.IDMappingSpec) error
+	ExportImageVolume(logger lager.Logger, imagePath, volumeID string, w io.Writer, uidMappings, gidMappings []groot.IDMappingSpec) error
+}
+
+//go:generate counterfeiter . ImageResizer
+
+// ImageResizer is implemented by the image drivers able to change the disk
+// limit of existing images
+type ImageResizer interface {
+	ResizeImage(logger lager.Logger, imagePath string, diskLimit int64, exclusiveDiskLimit bool) error
+}
+
+//go:generate counterfeiter . ImageCloner
+
+// ImageCloner is implemented by the image drivers able to create images with
+// a copy of the changes made to another image
+type ImageCloner interface {
+	CloneImage(logger lager.Logger, srcImagePath string, spec ImageDriverSpec) (groot.MountInfo, error)
+}
+
+//go:generate counterfeiter . ImageInspector
+
+// ImageInspector is implemented by the image drivers able to tell how an
+// image is mounted and limited
+type ImageInspector interface {
+	InspectImage(logger lager.Logger, imagePath string) (groot.ImageInspection, error)
+}
+
+//go:generate counterfeiter . ImageRemounter
+
+// ImageRemounter is implemented by the image drivers able to mount the rootfs
+// of existing images again. The id mappings are only given for images whose
+// layers are idmapped at mount time.
+type ImageRemounter interface {
+	RemountImage(logger lager.Logger, imagePath string, baseVolumeIDs []string, uidMappings, gidMappings []groot.IDMappingSpec) (bool, error)
+}
+
+const BaseImageConfigName = "base_image_config.json"
+
+// LabelsName is the file keeping the user-defined labels of an image
+const LabelsName = "labels.json"
+
+// IDMappingsName is the file keeping the id mappings of images created with
+// mappings other than the store ones
+const IDMappingsName = "id_mappings.json"
+
+// ImageMetaName is the file recording when and from which base image an
+// image was created
+const ImageMetaName = "image_meta.json"
+
+type imageMeta struct {
+	CreatedAt      time.Time `json:"created_at"`
+	SourceImage    string    `json:"source_image,omitempty"`
+	ManifestDigest string    `json:"manifest_digest,omitempty"`
+}
+
+type imageIDMappings struct {
+	UIDMappings   []groot.IDMappingSpec `json:"uid_mappings"`
+	GIDMappings   []groot.IDMappingSpec `json:"gid_mappings"`
+	IDMappedMount bool                  `json:"idmapped_mount"`
+}
+
 type ImageManager struct {
 	imageDriver ImageDriver
 	storePath   string
@@ -62,6 +148,80 @@ func (b *ImageManager) Create(logger lager.Logger, spec groot.ImageSpec) (groot.
 	logger.Info("starting")
 	defer logger.Info("ending")
 
+	return b.create(logger, spec, func(imageDriverSpec ImageDriverSpec) (groot.MountInfo, error) {
+		return b.imageDriver.CreateImage(logger, imageDriverSpec)
+	})
+}
+
+// Clone creates an image on the given base volumes with a copy of the changes
+// made to the source image. The clone keeps the owner, the base image config
+// and the provenance of the source image, and its labels unless others are
+// given.
+func (b *ImageManager) Clone(logger lager.Logger, srcID string, spec groot.ImageSpec) (groot.ImageInfo, error) {
+	logger = logger.Session("cloning-image", lager.Data{"storePath": b.storePath, "srcID": srcID, "id": spec.ID})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if ok, err := b.Exists(srcID); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return groot.ImageInfo{}, errorspkg.Errorf("image not found: %s", srcID)
+	}
+
+	cloner, ok := b.imageDriver.(ImageCloner)
+	if !ok {
+		return groot.ImageInfo{}, errorspkg.New("the image driver does not support cloning images")
+	}
+
+	srcImagePath := b.imagePath(srcID)
+	baseImage, err := b.readBaseImageConfig(srcImagePath)
+	if err != nil {
+		logger.Error("reading-base-image-config-failed", err)
+		return groot.ImageInfo{}, err
+	}
+	spec.BaseImage = baseImage
+
+	if spec.Labels == nil {
+		if spec.Labels, err = b.readLabels(srcImagePath); err != nil {
+			logger.Error("reading-labels-failed", err)
+			return groot.ImageInfo{}, err
+		}
+	}
+
+	// the clone shares the base volumes of the source image, so their files
+	// have to be seen through the same mappings
+	idMappings, err := b.readIDMappings(srcImagePath)
+	if err != nil {
+		logger.Error("reading-id-mappings-failed", err)
+		return groot.ImageInfo{}, err
+	}
+	spec.UIDMappings = idMappings.UIDMappings
+	spec.GIDMappings = idMappings.GIDMappings
+	spec.IDMappedMount = idMappings.IDMappedMount
+
+	srcImageMeta, _, err := b.readImageMeta(srcImagePath)
+	if err != nil {
+		logger.Error("reading-image-meta-failed", err)
+		return groot.ImageInfo{}, err
+	}
+	spec.BaseImageReference = srcImageMeta.SourceImage
+	spec.ManifestDigest = srcImageMeta.ManifestDigest
+
+	srcImageStat, err := os.Stat(srcImagePath)
+	if err != nil {
+		return groot.ImageInfo{}, errorspkg.Wrap(err, "checking source image owner")
+	}
+	if sysStat, ok := srcImageStat.Sys().(*syscall.Stat_t); ok {
+		spec.OwnerUID = int(sysStat.Uid)
+		spec.OwnerGID = int(sysStat.Gid)
+	}
+
+	return b.create(logger, spec, func(imageDriverSpec ImageDriverSpec) (groot.MountInfo, error) {
+		return cloner.CloneImage(logger, srcImagePath, imageDriverSpec)
+	})
+}
+
+// create makes the image directory and populates it with createImage
+func (b *ImageManager) create(logger lager.Logger, spec groot.ImageSpec, createImage func(ImageDriverSpec) (groot.MountInfo, error)) (groot.ImageInfo, error) {
 	imagePath := b.imagePath(spec.ID)
 	imageRootFSPath := filepath.Join(imagePath, "rootfs")
 
@@ -98,10 +258,15 @@ func (b *ImageManager) Create(logger lager.Logger, spec groot.ImageSpec) (groot.
 		ExclusiveDiskLimit: spec.ExcludeBaseImageFromQuota,
 		OwnerUID:           spec.OwnerUID,
 		OwnerGID:           spec.OwnerGID,
+		Squash:             spec.Squash,
+	}
+	if spec.IDMappedMount {
+		imageDriverSpec.UIDMappings = spec.UIDMappings
+		imageDriverSpec.GIDMappings = spec.GIDMappings
 	}
 
 	var mountInfo groot.MountInfo
-	if mountInfo, err = b.imageDriver.CreateImage(logger, imageDriverSpec); err != nil {
+	if mountInfo, err = createImage(imageDriverSpec); err != nil {
 		logger.Error("creating-image-failed", err, lager.Data{"imageDriverSpec": imageDriverSpec})
 		return groot.ImageInfo{}, errorspkg.Wrap(err, "creating image")
 	}
@@ -114,6 +279,34 @@ func (b *ImageManager) Create(logger lager.Logger, spec groot.ImageSpec) (groot.
 		return groot.ImageInfo{}, err
 	}
 
+	if err = b.writeBaseImageConfig(imagePath, spec.BaseImage); err != nil {
+		logger.Error("writing-base-image-config-failed", err)
+		return groot.ImageInfo{}, err
+	}
+
+	if err = b.writeLabels(imagePath, spec.Labels); err != nil {
+		logger.Error("writing-labels-failed", err)
+		return groot.ImageInfo{}, err
+	}
+
+	if err = b.writeIDMappings(imagePath, imageIDMappings{
+		UIDMappings:   spec.UIDMappings,
+		GIDMappings:   spec.GIDMappings,
+		IDMappedMount: spec.IDMappedMount,
+	}); err != nil {
+		logger.Error("writing-id-mappings-failed", err)
+		return groot.ImageInfo{}, err
+	}
+
+	if err = b.writeImageMeta(imagePath, imageMeta{
+		CreatedAt:      time.Now(),
+		SourceImage:    spec.BaseImageReference,
+		ManifestDigest: spec.ManifestDigest,
+	}); err != nil {
+		logger.Error("writing-image-meta-failed", err)
+		return groot.ImageInfo{}, err
+	}
+
 	imageInfo, err := b.imageInfo(imageRootFSPath, imagePath, spec.BaseImage, mountInfo, spec.Mount)
 	if err != nil {
 		logger.Error("creating-image-object", err)
@@ -123,6 +316,8 @@ func (b *ImageManager) Create(logger lager.Logger, spec groot.ImageSpec) (groot.
 	return imageInfo, nil
 }
 
+// Destroy removes the image, along with everything kept in its directory such
+// as the base image config, the labels and the id mappings
 func (b *ImageManager) Destroy(logger lager.Logger, id string) error {
 	logger = logger.Session("deleting-image", lager.Data{"storePath": b.storePath, "id": id})
 	logger.Info("starting")
@@ -150,6 +345,186 @@ func (b *ImageManager) Destroy(logger lager.Logger, id string) error {
 	return nil
 }
 
+// Commit turns the changes made to the image into a new volume on top of the
+// parent chain ID. It also returns the config of the image base image. Images
+// whose base volumes were unpacked with their own mappings can't be committed,
+// as the new volume would only fit on top of volumes owned the same way.
+func (b *ImageManager) Commit(logger lager.Logger, id, parentChainID string) (groot.LayerInfo, specsv1.Image, error) {
+	logger = logger.Session("committing-image", lager.Data{"storePath": b.storePath, "id": id})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if ok, err := b.Exists(id); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return groot.LayerInfo{}, specsv1.Image{}, errorspkg.Errorf("image not found: %s", id)
+	}
+
+	committer, ok := b.imageDriver.(ImageCommitter)
+	if !ok {
+		return groot.LayerInfo{}, specsv1.Image{}, errorspkg.New("the image driver does not support committing images")
+	}
+
+	imagePath := b.imagePath(id)
+	baseImage, err := b.readBaseImageConfig(imagePath)
+	if err != nil {
+		logger.Error("reading-base-image-config-failed", err)
+		return groot.LayerInfo{}, specsv1.Image{}, err
+	}
+
+	idMappings, err := b.readIDMappings(imagePath)
+	if err != nil {
+		logger.Error("reading-id-mappings-failed", err)
+		return groot.LayerInfo{}, specsv1.Image{}, err
+	}
+	if idMappings.hasMappings() && !idMappings.IDMappedMount {
+		return groot.LayerInfo{}, specsv1.Image{}, errorspkg.New("committing images created with their own id mappings is not supported")
+	}
+
+	layerInfo, err := committer.CommitImage(logger, imagePath, parentChainID, idMappings.UIDMappings, idMappings.GIDMappings)
+	if err != nil {
+		logger.Error("committing-image-failed", err)
+		return groot.LayerInfo{}, specsv1.Image{}, errorspkg.Wrap(err, "committing image")
+	}
+
+	return layerInfo, baseImage, nil
+}
+
+// Resize changes the disk limit of the image
+func (b *ImageManager) Resize(logger lager.Logger, id string, diskLimit int64, exclusiveDiskLimit bool) error {
+	logger = logger.Session("resizing-image", lager.Data{"storePath": b.storePath, "id": id, "diskLimit": diskLimit})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if ok, err := b.Exists(id); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return errorspkg.Errorf("image not found: %s", id)
+	}
+
+	resizer, ok := b.imageDriver.(ImageResizer)
+	if !ok {
+		return errorspkg.New("the image driver does not support resizing images")
+	}
+
+	if err := resizer.ResizeImage(logger, b.imagePath(id), diskLimit, exclusiveDiskLimit); err != nil {
+		logger.Error("resizing-image-failed", err)
+		return errorspkg.Wrap(err, "resizing image")
+	}
+
+	return nil
+}
+
+// Remount mounts the rootfs of the image on its base volumes again. It
+// returns false when the image driver had nothing to do.
+func (b *ImageManager) Remount(logger lager.Logger, id string, baseVolumeIDs []string) (bool, error) {
+	logger = logger.Session("remounting-image", lager.Data{"storePath": b.storePath, "id": id})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if ok, err := b.Exists(id); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return false, errorspkg.Errorf("image not found: %s", id)
+	}
+
+	remounter, ok := b.imageDriver.(ImageRemounter)
+	if !ok {
+		return false, errorspkg.New("the image driver does not support remounting images")
+	}
+
+	imagePath := b.imagePath(id)
+	idMappings, err := b.readIDMappings(imagePath)
+	if err != nil {
+		logger.Error("reading-id-mappings-failed", err)
+		return false, err
+	}
+	if !idMappings.IDMappedMount {
+		idMappings = imageIDMappings{}
+	}
+
+	remounted, err := remounter.RemountImage(logger, imagePath, baseVolumeIDs, idMappings.UIDMappings, idMappings.GIDMappings)
+	if err != nil {
+		logger.Error("remounting-image-failed", err)
+		return false, errorspkg.Wrap(err, "remounting image")
+	}
+
+	return remounted, nil
+}
+
+// ExportDiff writes the changes made to the image as an uncompressed layer
+// tarball, mapping the owners back to the ids seen inside the image. The
+// mappings the image was created with take precedence over the given ones.
+func (b *ImageManager) ExportDiff(logger lager.Logger, id string, idMappings groot.IDMappings, w io.Writer) error {
+	logger = logger.Session("exporting-image-diff", lager.Data{"storePath": b.storePath, "id": id})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if ok, err := b.Exists(id); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return errorspkg.Errorf("image not found: %s", id)
+	}
+
+	exporter, ok := b.imageDriver.(ImageDiffExporter)
+	if !ok {
+		return errorspkg.New("the image driver does not support exporting image diffs")
+	}
+
+	imagePath := b.imagePath(id)
+	idMappings, err := b.exportIDMappings(imagePath, idMappings)
+	if err != nil {
+		logger.Error("reading-id-mappings-failed", err)
+		return err
+	}
+
+	if err := exporter.ExportImageDiff(logger, imagePath, w, idMappings.UIDMappings, idMappings.GIDMappings); err != nil {
+		logger.Error("exporting-image-diff-failed", err)
+		return errorspkg.Wrap(err, "exporting image diff")
+	}
+
+	return nil
+}
+
+// ExportVolume writes one of the base volumes of the image as an uncompressed
+// layer tarball, mapping the owners back to the ids seen inside the image. The
+// mappings the image was created with take precedence over the given ones.
+func (b *ImageManager) ExportVolume(logger lager.Logger, id, volumeID string, idMappings groot.IDMappings, w io.Writer) error {
+	logger = logger.Session("exporting-image-volume", lager.Data{"storePath": b.storePath, "id": id, "volumeID": volumeID})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	if ok, err := b.Exists(id); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return errorspkg.Errorf("image not found: %s", id)
+	}
+
+	exporter, ok := b.imageDriver.(ImageDiffExporter)
+	if !ok {
+		return errorspkg.New("the image driver does not support exporting image volumes")
+	}
+
+	imagePath := b.imagePath(id)
+	idMappings, err := b.exportIDMappings(imagePath, idMappings)
+	if err != nil {
+		logger.Error("reading-id-mappings-failed", err)
+		return err
+	}
+
+	if err := exporter.ExportImageVolume(logger, imagePath, volumeID, w, idMappings.UIDMappings, idMappings.GIDMappings); err != nil {
+		logger.Error("exporting-image-volume-failed", err)
+		return errorspkg.Wrapf(err, "exporting volume %s", volumeID)
+	}
+
+	return nil
+}
+
+// BaseImage returns the config of the image base image. It is empty for
+// images created before the config was kept.
+func (b *ImageManager) BaseImage(id string) (specsv1.Image, error) {
+	if ok, _ := b.Exists(id); !ok {
+		return specsv1.Image{}, errorspkg.Errorf("image not found: %s", id)
+	}
+
+	return b.readBaseImageConfig(b.imagePath(id))
+}
+
 func (b *ImageManager) Exists(id string) (bool, error) {
 	imagePath := path.Join(b.storePath, store.ImageDirName, id)
 	if _, err := os.Stat(imagePath); err != nil {
@@ -177,6 +552,69 @@ func (b *ImageManager) Stats(logger lager.Logger, id string) (groot.VolumeStats,
 	return b.imageDriver.FetchStats(logger, imagePath)
 }
 
+// Inspect describes the image from its directory and base image config, and
+// the image driver when it supports it
+func (b *ImageManager) Inspect(logger lager.Logger, id string) (groot.ImageInspection, error) {
+	logger = logger.Session("inspecting-image", lager.Data{"storePath": b.storePath, "id": id})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	if ok, err := b.Exists(id); !ok {
+		logger.Error("checking-image-path-failed", err)
+		return groot.ImageInspection{}, errorspkg.Errorf("image not found: %s", id)
+	}
+
+	imagePath := b.imagePath(id)
+	inspection := groot.ImageInspection{}
+	if inspector, ok := b.imageDriver.(ImageInspector); ok {
+		var err error
+		if inspection, err = inspector.InspectImage(logger, imagePath); err != nil {
+			logger.Error("inspecting-image-failed", err)
+			return groot.ImageInspection{}, errorspkg.Wrap(err, "inspecting image")
+		}
+	}
+	inspection.ID = id
+	inspection.Path = imagePath
+
+	meta, recorded, err := b.readImageMeta(imagePath)
+	if err != nil {
+		return groot.ImageInspection{}, err
+	}
+	if recorded {
+		inspection.CreatedAt = meta.CreatedAt
+		inspection.SourceImage = meta.SourceImage
+		inspection.ManifestDigest = meta.ManifestDigest
+	} else {
+		// images created before it was recorded are as old as their directory
+		imageInfo, err := os.Stat(imagePath)
+		if err != nil {
+			return groot.ImageInspection{}, errorspkg.Wrap(err, "reading image path")
+		}
+		inspection.CreatedAt = imageInfo.ModTime()
+	}
+
+	rootfsInfo, err := os.Stat(filepath.Join(imagePath, "rootfs"))
+	if err != nil {
+		return groot.ImageInspection{}, errorspkg.Wrap(err, "reading image rootfs")
+	}
+	if stat, ok := rootfsInfo.Sys().(*syscall.Stat_t); ok {
+		inspection.OwnerUID = int(stat.Uid)
+		inspection.OwnerGID = int(stat.Gid)
+	}
+
+	baseImage, err := b.readBaseImageConfig(imagePath)
+	if err != nil {
+		return groot.ImageInspection{}, err
+	}
+	inspection.Config = baseImage.Config
+
+	if inspection.Labels, err = b.readLabels(imagePath); err != nil {
+		return groot.ImageInspection{}, err
+	}
+
+	return inspection, nil
+}
+
 var OpenFile = os.OpenFile
 
 func (b *ImageManager) imageInfo(rootfsPath, imagePath string, baseImage specsv1.Image, mountJson groot.MountInfo, mount bool) (groot.ImageInfo, error) {
@@ -193,6 +631,144 @@ func (b *ImageManager) imageInfo(rootfsPath, imagePath string, baseImage specsv1
 	return imageInfo, nil
 }
 
+func (b *ImageManager) writeBaseImageConfig(imagePath string, baseImage specsv1.Image) error {
+	configBytes, err := json.Marshal(baseImage)
+	if err != nil {
+		return errorspkg.Wrap(err, "marshaling base image config")
+	}
+
+	if err := os.WriteFile(filepath.Join(imagePath, BaseImageConfigName), configBytes, 0600); err != nil {
+		return errorspkg.Wrap(err, "writing base image config")
+	}
+	return nil
+}
+
+// readBaseImageConfig returns an empty config for images created before the
+// base image config was kept around
+func (b *ImageManager) readBaseImageConfig(imagePath string) (specsv1.Image, error) {
+	var baseImage specsv1.Image
+	configBytes, err := os.ReadFile(filepath.Join(imagePath, BaseImageConfigName))
+	if os.IsNotExist(err) {
+		return baseImage, nil
+	} else if err != nil {
+		return baseImage, errorspkg.Wrap(err, "reading base image config")
+	}
+
+	if err := json.Unmarshal(configBytes, &baseImage); err != nil {
+		return baseImage, errorspkg.Wrap(err, "parsing base image config")
+	}
+	return baseImage, nil
+}
+
+func (b *ImageManager) writeImageMeta(imagePath string, meta imageMeta) error {
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return errorspkg.Wrap(err, "marshaling image meta")
+	}
+
+	if err := os.WriteFile(filepath.Join(imagePath, ImageMetaName), metaBytes, 0600); err != nil {
+		return errorspkg.Wrap(err, "writing image meta")
+	}
+	return nil
+}
+
+// readImageMeta tells whether the image meta was recorded, as it is not for
+// images created before it was
+func (b *ImageManager) readImageMeta(imagePath string) (imageMeta, bool, error) {
+	metaBytes, err := os.ReadFile(filepath.Join(imagePath, ImageMetaName))
+	if os.IsNotExist(err) {
+		return imageMeta{}, false, nil
+	} else if err != nil {
+		return imageMeta{}, false, errorspkg.Wrap(err, "reading image meta")
+	}
+
+	var meta imageMeta
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return imageMeta{}, false, errorspkg.Wrap(err, "parsing image meta")
+	}
+	return meta, true, nil
+}
+
+// writeLabels writes nothing for images without labels
+func (b *ImageManager) writeLabels(imagePath string, labels map[string]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	labelsBytes, err := json.Marshal(labels)
+	if err != nil {
+		return errorspkg.Wrap(err, "marshaling labels")
+	}
+
+	if err := os.WriteFile(filepath.Join(imagePath, LabelsName), labelsBytes, 0600); err != nil {
+		return errorspkg.Wrap(err, "writing labels")
+	}
+	return nil
+}
+
+func (b *ImageManager) readLabels(imagePath string) (map[string]string, error) {
+	labelsBytes, err := os.ReadFile(filepath.Join(imagePath, LabelsName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errorspkg.Wrap(err, "reading labels")
+	}
+
+	var labels map[string]string
+	if err := json.Unmarshal(labelsBytes, &labels); err != nil {
+		return nil, errorspkg.Wrap(err, "parsing labels")
+	}
+	return labels, nil
+}
+
+func (b *ImageManager) writeIDMappings(imagePath string, idMappings imageIDMappings) error {
+	if !idMappings.hasMappings() {
+		return nil
+	}
+
+	idMappingsBytes, err := json.Marshal(idMappings)
+	if err != nil {
+		return errorspkg.Wrap(err, "marshaling id mappings")
+	}
+
+	if err := os.WriteFile(filepath.Join(imagePath, IDMappingsName), idMappingsBytes, 0600); err != nil {
+		return errorspkg.Wrap(err, "writing id mappings")
+	}
+	return nil
+}
+
+// readIDMappings returns no mappings for images created with the store ones
+func (b *ImageManager) readIDMappings(imagePath string) (imageIDMappings, error) {
+	idMappingsBytes, err := os.ReadFile(filepath.Join(imagePath, IDMappingsName))
+	if os.IsNotExist(err) {
+		return imageIDMappings{}, nil
+	} else if err != nil {
+		return imageIDMappings{}, errorspkg.Wrap(err, "reading id mappings")
+	}
+
+	var idMappings imageIDMappings
+	if err := json.Unmarshal(idMappingsBytes, &idMappings); err != nil {
+		return imageIDMappings{}, errorspkg.Wrap(err, "parsing id mappings")
+	}
+	return idMappings, nil
+}
+
+func (b *ImageManager) exportIDMappings(imagePath string, storeIDMappings groot.IDMappings) (groot.IDMappings, error) {
+	idMappings, err := b.readIDMappings(imagePath)
+	if err != nil {
+		return groot.IDMappings{}, err
+	}
+	if !idMappings.hasMappings() {
+		return storeIDMappings, nil
+	}
+
+	return groot.IDMappings{UIDMappings: idMappings.UIDMappings, GIDMappings: idMappings.GIDMappings}, nil
+}
+
+func (m imageIDMappings) hasMappings() bool {
+	return len(m.UIDMappings) > 0 || len(m.GIDMappings) > 0
+}
+
 func (b *ImageManager) imagePath(id string) string {
 	return path.Join(b.storePath, store.ImageDirName, id)
 }
@@ -1,6 +1,7 @@
 package image_manager_test
 
 import (
+	"bytes"
 	"errors"
 	"os"
 	"path"
@@ -114,6 +115,89 @@ var _ = Describe("Image", func() {
 			Expect(spec.OwnerGID).To(Equal(456))
 		})
 
+		Context("when id mappings are given", func() {
+			var imageSpec groot.ImageSpec
+
+			BeforeEach(func() {
+				imageSpec = groot.ImageSpec{
+					ID:          "some-id",
+					BaseImage:   imageConfig,
+					UIDMappings: []groot.IDMappingSpec{{HostID: 1000, NamespaceID: 0, Size: 1}},
+					GIDMappings: []groot.IDMappingSpec{{HostID: 2000, NamespaceID: 0, Size: 1}},
+				}
+			})
+
+			It("keeps them with the image", func() {
+				image, err := imageManager.Create(logger, imageSpec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(filepath.Join(image.Path, imagemanager.IDMappingsName)).To(BeAnExistingFile())
+			})
+
+			It("doesn't pass them to the image driver", func() {
+				_, err := imageManager.Create(logger, imageSpec)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, spec := fakeImageDriver.CreateImageArgsForCall(0)
+				Expect(spec.UIDMappings).To(BeEmpty())
+				Expect(spec.GIDMappings).To(BeEmpty())
+			})
+
+			Context("and the layers are idmapped at mount time", func() {
+				BeforeEach(func() {
+					imageSpec.IDMappedMount = true
+				})
+
+				It("passes them to the image driver", func() {
+					_, err := imageManager.Create(logger, imageSpec)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, spec := fakeImageDriver.CreateImageArgsForCall(0)
+					Expect(spec.UIDMappings).To(Equal(imageSpec.UIDMappings))
+					Expect(spec.GIDMappings).To(Equal(imageSpec.GIDMappings))
+				})
+			})
+		})
+
+		It("doesn't keep id mappings for images created with the store ones", func() {
+			image, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(image.Path, imagemanager.IDMappingsName)).NotTo(BeAnExistingFile())
+		})
+
+		It("passes the squash option to the image driver", func() {
+			_, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig, Squash: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := fakeImageDriver.CreateImageArgsForCall(0)
+			Expect(spec.Squash).To(BeTrue())
+		})
+
+		It("keeps the labels with the image", func() {
+			image, err := imageManager.Create(logger, groot.ImageSpec{
+				ID:        "some-id",
+				BaseImage: imageConfig,
+				Labels:    map[string]string{"app": "some-app", "space": "some-space"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(image.Path, imagemanager.LabelsName)).To(BeAnExistingFile())
+
+			inspection, err := imageManager.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inspection.Labels).To(Equal(map[string]string{"app": "some-app", "space": "some-space"}))
+		})
+
+		Context("when the image has no labels", func() {
+			It("doesn't write the labels file", func() {
+				image, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(filepath.Join(image.Path, imagemanager.LabelsName)).NotTo(BeAnExistingFile())
+
+				inspection, err := imageManager.Inspect(logger, "some-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(inspection.Labels).To(BeEmpty())
+			})
+		})
+
 		Context("when mounting is skipped", func() {
 			It("returns a image with mount information", func() {
 				image, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig, Mount: false})
@@ -349,6 +433,689 @@ var _ = Describe("Image", func() {
 
 	})
 
+	Describe("Commit", func() {
+		var fakeImageCommitter *image_managerfakes.FakeImageCommitter
+
+		BeforeEach(func() {
+			fakeImageCommitter = new(image_managerfakes.FakeImageCommitter)
+			fakeImageCommitter.CommitImageReturns(groot.LayerInfo{ChainID: "chain-3", ParentChainID: "chain-2", DiffID: "diff-3"}, nil)
+		})
+
+		JustBeforeEach(func() {
+			committingDriver := struct {
+				*image_managerfakes.FakeImageDriver
+				*image_managerfakes.FakeImageCommitter
+			}{fakeImageDriver, fakeImageCommitter}
+			imageManager = imagemanager.NewImageManager(committingDriver, storePath)
+
+			_, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("commits the image with the image driver", func() {
+			layerInfo, _, err := imageManager.Commit(logger, "some-id", "chain-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layerInfo.ChainID).To(Equal("chain-3"))
+
+			Expect(fakeImageCommitter.CommitImageCallCount()).To(Equal(1))
+			_, imagePath, parentChainID, uidMappings, gidMappings := fakeImageCommitter.CommitImageArgsForCall(0)
+			Expect(imagePath).To(Equal(filepath.Join(imagesPath, "some-id")))
+			Expect(parentChainID).To(Equal("chain-2"))
+			Expect(uidMappings).To(BeEmpty())
+			Expect(gidMappings).To(BeEmpty())
+		})
+
+		Context("when the image layers are idmapped at mount time", func() {
+			var imageSpec groot.ImageSpec
+
+			JustBeforeEach(func() {
+				imageSpec = groot.ImageSpec{
+					ID:            "mapped-id",
+					BaseImage:     imageConfig,
+					UIDMappings:   []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+					GIDMappings:   []groot.IDMappingSpec{{HostID: 200000, NamespaceID: 0, Size: 65536}},
+					IDMappedMount: true,
+				}
+				_, err := imageManager.Create(logger, imageSpec)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("commits the image with its id mappings", func() {
+				_, _, err := imageManager.Commit(logger, "mapped-id", "chain-2")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, _, uidMappings, gidMappings := fakeImageCommitter.CommitImageArgsForCall(0)
+				Expect(uidMappings).To(Equal(imageSpec.UIDMappings))
+				Expect(gidMappings).To(Equal(imageSpec.GIDMappings))
+			})
+		})
+
+		Context("when the image layers were unpacked with the image id mappings", func() {
+			JustBeforeEach(func() {
+				_, err := imageManager.Create(logger, groot.ImageSpec{
+					ID:          "mapped-id",
+					BaseImage:   imageConfig,
+					UIDMappings: []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error", func() {
+				_, _, err := imageManager.Commit(logger, "mapped-id", "chain-2")
+				Expect(err).To(MatchError(ContainSubstring("created with their own id mappings is not supported")))
+				Expect(fakeImageCommitter.CommitImageCallCount()).To(BeZero())
+			})
+		})
+
+		It("returns the config of the image base image", func() {
+			_, baseImage, err := imageManager.Commit(logger, "some-id", "chain-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(baseImage.Created.Unix()).To(Equal(imageConfig.Created.Unix()))
+		})
+
+		Context("when the image does not exist", func() {
+			It("returns an error", func() {
+				_, _, err := imageManager.Commit(logger, "other-id", "chain-2")
+				Expect(err).To(MatchError("image not found: other-id"))
+			})
+		})
+
+		Context("when the image driver fails to commit", func() {
+			BeforeEach(func() {
+				fakeImageCommitter.CommitImageReturns(groot.LayerInfo{}, errors.New("no space left"))
+			})
+
+			It("returns an error", func() {
+				_, _, err := imageManager.Commit(logger, "some-id", "chain-2")
+				Expect(err).To(MatchError(ContainSubstring("no space left")))
+			})
+		})
+
+		Context("when the image driver can't commit images", func() {
+			JustBeforeEach(func() {
+				imageManager = imagemanager.NewImageManager(fakeImageDriver, storePath)
+			})
+
+			It("returns an error", func() {
+				_, _, err := imageManager.Commit(logger, "some-id", "chain-2")
+				Expect(err).To(MatchError(ContainSubstring("does not support committing")))
+			})
+		})
+	})
+
+	Describe("Clone", func() {
+		var (
+			fakeImageCloner *image_managerfakes.FakeImageCloner
+			srcImageConfig  specsv1.Image
+		)
+
+		BeforeEach(func() {
+			fakeImageCloner = new(image_managerfakes.FakeImageCloner)
+			fakeImageCloner.CloneImageStub = func(_ lager.Logger, _ string, spec imagemanager.ImageDriverSpec) (groot.MountInfo, error) {
+				return groot.MountInfo{Source: "my-clone-source"}, os.Mkdir(filepath.Join(spec.ImagePath, "rootfs"), 0777)
+			}
+			srcImageConfig = specsv1.Image{Author: "Groot", Config: specsv1.ImageConfig{}}
+		})
+
+		JustBeforeEach(func() {
+			cloningDriver := struct {
+				*image_managerfakes.FakeImageDriver
+				*image_managerfakes.FakeImageCloner
+			}{fakeImageDriver, fakeImageCloner}
+			imageManager = imagemanager.NewImageManager(cloningDriver, storePath)
+
+			_, err := imageManager.Create(logger, groot.ImageSpec{
+				ID:                 "src-id",
+				BaseImage:          srcImageConfig,
+				OwnerUID:           1000,
+				OwnerGID:           2000,
+				Labels:             map[string]string{"app": "src-app"},
+				BaseImageReference: "docker:///busybox",
+				ManifestDigest:     "sha256:busybox",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("keeps the source image provenance", func() {
+			_, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			inspection, err := imageManager.Inspect(logger, "dst-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inspection.SourceImage).To(Equal("docker:///busybox"))
+			Expect(inspection.ManifestDigest).To(Equal("sha256:busybox"))
+		})
+
+		It("clones the source image with the image driver", func() {
+			image, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{
+				ID:            "dst-id",
+				BaseVolumeIDs: []string{"id-1", "id-2"},
+				DiskLimit:     1024,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(image.Path).To(Equal(filepath.Join(imagesPath, "dst-id")))
+			Expect(image.Mounts[0].Source).To(Equal("my-clone-source"))
+
+			Expect(fakeImageCloner.CloneImageCallCount()).To(Equal(1))
+			_, srcImagePath, spec := fakeImageCloner.CloneImageArgsForCall(0)
+			Expect(srcImagePath).To(Equal(filepath.Join(imagesPath, "src-id")))
+			Expect(spec.ImagePath).To(Equal(image.Path))
+			Expect(spec.BaseVolumeIDs).To(Equal([]string{"id-1", "id-2"}))
+			Expect(spec.DiskLimit).To(BeEquivalentTo(1024))
+		})
+
+		Context("when the source image was created with id mappings", func() {
+			var srcSpec groot.ImageSpec
+
+			JustBeforeEach(func() {
+				srcSpec = groot.ImageSpec{
+					ID:            "mapped-src-id",
+					BaseImage:     srcImageConfig,
+					UIDMappings:   []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+					GIDMappings:   []groot.IDMappingSpec{{HostID: 200000, NamespaceID: 0, Size: 65536}},
+					IDMappedMount: true,
+				}
+				_, err := imageManager.Create(logger, srcSpec)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("clones it with the same id mappings", func() {
+				image, err := imageManager.Clone(logger, "mapped-src-id", groot.ImageSpec{ID: "dst-id"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(filepath.Join(image.Path, imagemanager.IDMappingsName)).To(BeAnExistingFile())
+
+				_, _, spec := fakeImageCloner.CloneImageArgsForCall(0)
+				Expect(spec.UIDMappings).To(Equal(srcSpec.UIDMappings))
+				Expect(spec.GIDMappings).To(Equal(srcSpec.GIDMappings))
+			})
+		})
+
+		It("keeps the source image owner", func() {
+			image, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, spec := fakeImageCloner.CloneImageArgsForCall(0)
+			Expect(spec.OwnerUID).To(Equal(1000))
+			Expect(spec.OwnerGID).To(Equal(2000))
+
+			imagePath, err := os.Stat(image.Path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imagePath.Sys().(*syscall.Stat_t).Uid).To(Equal(uint32(1000)))
+			Expect(imagePath.Sys().(*syscall.Stat_t).Gid).To(Equal(uint32(2000)))
+		})
+
+		It("keeps the source base image config", func() {
+			image, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(image.Image.Author).To(Equal("Groot"))
+
+			baseImage, err := imageManager.BaseImage("dst-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(baseImage.Author).To(Equal("Groot"))
+		})
+
+		It("keeps the source image labels", func() {
+			_, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			inspection, err := imageManager.Inspect(logger, "dst-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inspection.Labels).To(Equal(map[string]string{"app": "src-app"}))
+		})
+
+		Context("when labels are given", func() {
+			It("uses them instead of the source image ones", func() {
+				_, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id", Labels: map[string]string{"app": "dst-app"}})
+				Expect(err).NotTo(HaveOccurred())
+
+				inspection, err := imageManager.Inspect(logger, "dst-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(inspection.Labels).To(Equal(map[string]string{"app": "dst-app"}))
+			})
+		})
+
+		Context("when the source image does not exist", func() {
+			It("returns an error", func() {
+				_, err := imageManager.Clone(logger, "other-id", groot.ImageSpec{ID: "dst-id"})
+				Expect(err).To(MatchError("image not found: other-id"))
+			})
+		})
+
+		Context("when the image driver fails to clone", func() {
+			BeforeEach(func() {
+				fakeImageCloner.CloneImageReturns(groot.MountInfo{}, errors.New("copying upper dir failed"))
+			})
+
+			It("cleans up the clone and returns an error", func() {
+				_, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id"})
+				Expect(err).To(MatchError(ContainSubstring("copying upper dir failed")))
+
+				Expect(filepath.Join(imagesPath, "dst-id")).NotTo(BeAnExistingFile())
+				Expect(fakeImageDriver.DestroyImageCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the image driver can't clone images", func() {
+			JustBeforeEach(func() {
+				imageManager = imagemanager.NewImageManager(fakeImageDriver, storePath)
+			})
+
+			It("returns an error", func() {
+				_, err := imageManager.Clone(logger, "src-id", groot.ImageSpec{ID: "dst-id"})
+				Expect(err).To(MatchError(ContainSubstring("does not support cloning")))
+			})
+		})
+	})
+
+	Describe("Resize", func() {
+		var fakeImageResizer *image_managerfakes.FakeImageResizer
+
+		BeforeEach(func() {
+			fakeImageResizer = new(image_managerfakes.FakeImageResizer)
+		})
+
+		JustBeforeEach(func() {
+			resizingDriver := struct {
+				*image_managerfakes.FakeImageDriver
+				*image_managerfakes.FakeImageResizer
+			}{fakeImageDriver, fakeImageResizer}
+			imageManager = imagemanager.NewImageManager(resizingDriver, storePath)
+
+			_, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("resizes the image with the image driver", func() {
+			Expect(imageManager.Resize(logger, "some-id", 2048, true)).To(Succeed())
+
+			Expect(fakeImageResizer.ResizeImageCallCount()).To(Equal(1))
+			_, imagePath, diskLimit, exclusiveDiskLimit := fakeImageResizer.ResizeImageArgsForCall(0)
+			Expect(imagePath).To(Equal(filepath.Join(imagesPath, "some-id")))
+			Expect(diskLimit).To(BeEquivalentTo(2048))
+			Expect(exclusiveDiskLimit).To(BeTrue())
+		})
+
+		Context("when the image does not exist", func() {
+			It("returns an error", func() {
+				err := imageManager.Resize(logger, "other-id", 2048, true)
+				Expect(err).To(MatchError("image not found: other-id"))
+			})
+		})
+
+		Context("when the image driver fails to resize", func() {
+			BeforeEach(func() {
+				fakeImageResizer.ResizeImageReturns(errors.New("disk limit is smaller than volume size"))
+			})
+
+			It("returns an error", func() {
+				err := imageManager.Resize(logger, "some-id", 2048, false)
+				Expect(err).To(MatchError(ContainSubstring("disk limit is smaller than volume size")))
+			})
+		})
+
+		Context("when the image driver can't resize images", func() {
+			JustBeforeEach(func() {
+				imageManager = imagemanager.NewImageManager(fakeImageDriver, storePath)
+			})
+
+			It("returns an error", func() {
+				err := imageManager.Resize(logger, "some-id", 2048, false)
+				Expect(err).To(MatchError(ContainSubstring("does not support resizing")))
+			})
+		})
+	})
+
+	Describe("Remount", func() {
+		var fakeImageRemounter *image_managerfakes.FakeImageRemounter
+
+		BeforeEach(func() {
+			fakeImageRemounter = new(image_managerfakes.FakeImageRemounter)
+			fakeImageRemounter.RemountImageReturns(true, nil)
+		})
+
+		JustBeforeEach(func() {
+			remountingDriver := struct {
+				*image_managerfakes.FakeImageDriver
+				*image_managerfakes.FakeImageRemounter
+			}{fakeImageDriver, fakeImageRemounter}
+			imageManager = imagemanager.NewImageManager(remountingDriver, storePath)
+
+			_, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("remounts the image with the image driver", func() {
+			remounted, err := imageManager.Remount(logger, "some-id", []string{"id-1", "id-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remounted).To(BeTrue())
+
+			Expect(fakeImageRemounter.RemountImageCallCount()).To(Equal(1))
+			_, imagePath, baseVolumeIDs, uidMappings, gidMappings := fakeImageRemounter.RemountImageArgsForCall(0)
+			Expect(imagePath).To(Equal(filepath.Join(imagesPath, "some-id")))
+			Expect(baseVolumeIDs).To(Equal([]string{"id-1", "id-2"}))
+			Expect(uidMappings).To(BeEmpty())
+			Expect(gidMappings).To(BeEmpty())
+		})
+
+		Context("when the image layers are idmapped at mount time", func() {
+			var imageSpec groot.ImageSpec
+
+			JustBeforeEach(func() {
+				imageSpec = groot.ImageSpec{
+					ID:            "mapped-id",
+					BaseImage:     imageConfig,
+					UIDMappings:   []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+					GIDMappings:   []groot.IDMappingSpec{{HostID: 200000, NamespaceID: 0, Size: 65536}},
+					IDMappedMount: true,
+				}
+				_, err := imageManager.Create(logger, imageSpec)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("remounts the image with its id mappings", func() {
+				_, err := imageManager.Remount(logger, "mapped-id", []string{"id-1"})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, _, uidMappings, gidMappings := fakeImageRemounter.RemountImageArgsForCall(0)
+				Expect(uidMappings).To(Equal(imageSpec.UIDMappings))
+				Expect(gidMappings).To(Equal(imageSpec.GIDMappings))
+			})
+		})
+
+		Context("when the image does not exist", func() {
+			It("returns an error", func() {
+				_, err := imageManager.Remount(logger, "other-id", nil)
+				Expect(err).To(MatchError("image not found: other-id"))
+			})
+		})
+
+		Context("when the image driver fails to remount", func() {
+			BeforeEach(func() {
+				fakeImageRemounter.RemountImageReturns(false, errors.New("mounting overlay"))
+			})
+
+			It("returns an error", func() {
+				_, err := imageManager.Remount(logger, "some-id", nil)
+				Expect(err).To(MatchError(ContainSubstring("mounting overlay")))
+			})
+		})
+
+		Context("when the image driver can't remount images", func() {
+			JustBeforeEach(func() {
+				imageManager = imagemanager.NewImageManager(fakeImageDriver, storePath)
+			})
+
+			It("returns an error", func() {
+				_, err := imageManager.Remount(logger, "some-id", nil)
+				Expect(err).To(MatchError(ContainSubstring("does not support remounting")))
+			})
+		})
+	})
+
+	Describe("exporting", func() {
+		var (
+			fakeImageDiffExporter *image_managerfakes.FakeImageDiffExporter
+			idMappings            groot.IDMappings
+		)
+
+		BeforeEach(func() {
+			fakeImageDiffExporter = new(image_managerfakes.FakeImageDiffExporter)
+			idMappings = groot.IDMappings{
+				UIDMappings: []groot.IDMappingSpec{{HostID: 100000, NamespaceID: 0, Size: 65536}},
+				GIDMappings: []groot.IDMappingSpec{{HostID: 200000, NamespaceID: 0, Size: 65536}},
+			}
+		})
+
+		JustBeforeEach(func() {
+			exportingDriver := struct {
+				*image_managerfakes.FakeImageDriver
+				*image_managerfakes.FakeImageDiffExporter
+			}{fakeImageDriver, fakeImageDiffExporter}
+			imageManager = imagemanager.NewImageManager(exportingDriver, storePath)
+
+			_, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Describe("ExportDiff", func() {
+			It("exports the image diff with the image driver", func() {
+				output := new(bytes.Buffer)
+				Expect(imageManager.ExportDiff(logger, "some-id", idMappings, output)).To(Succeed())
+
+				Expect(fakeImageDiffExporter.ExportImageDiffCallCount()).To(Equal(1))
+				_, imagePath, w, uidMappings, gidMappings := fakeImageDiffExporter.ExportImageDiffArgsForCall(0)
+				Expect(imagePath).To(Equal(filepath.Join(imagesPath, "some-id")))
+				Expect(w).To(Equal(output))
+				Expect(uidMappings).To(Equal(idMappings.UIDMappings))
+				Expect(gidMappings).To(Equal(idMappings.GIDMappings))
+			})
+
+			Context("when the image does not exist", func() {
+				It("returns an error", func() {
+					err := imageManager.ExportDiff(logger, "other-id", idMappings, new(bytes.Buffer))
+					Expect(err).To(MatchError("image not found: other-id"))
+				})
+			})
+
+			Context("when the image driver fails to export", func() {
+				BeforeEach(func() {
+					fakeImageDiffExporter.ExportImageDiffReturns(errors.New("broken pipe"))
+				})
+
+				It("returns an error", func() {
+					err := imageManager.ExportDiff(logger, "some-id", idMappings, new(bytes.Buffer))
+					Expect(err).To(MatchError(ContainSubstring("broken pipe")))
+				})
+			})
+		})
+
+		Describe("ExportVolume", func() {
+			It("exports the image volume with the image driver", func() {
+				output := new(bytes.Buffer)
+				Expect(imageManager.ExportVolume(logger, "some-id", "chain-1", idMappings, output)).To(Succeed())
+
+				Expect(fakeImageDiffExporter.ExportImageVolumeCallCount()).To(Equal(1))
+				_, imagePath, volumeID, w, uidMappings, gidMappings := fakeImageDiffExporter.ExportImageVolumeArgsForCall(0)
+				Expect(imagePath).To(Equal(filepath.Join(imagesPath, "some-id")))
+				Expect(volumeID).To(Equal("chain-1"))
+				Expect(w).To(Equal(output))
+				Expect(uidMappings).To(Equal(idMappings.UIDMappings))
+				Expect(gidMappings).To(Equal(idMappings.GIDMappings))
+			})
+
+			Context("when the image driver fails to export", func() {
+				BeforeEach(func() {
+					fakeImageDiffExporter.ExportImageVolumeReturns(errors.New("volume does not exist"))
+				})
+
+				It("returns an error", func() {
+					err := imageManager.ExportVolume(logger, "some-id", "chain-1", idMappings, new(bytes.Buffer))
+					Expect(err).To(MatchError(ContainSubstring("volume does not exist")))
+				})
+			})
+		})
+
+		Context("when the image was created with its own id mappings", func() {
+			var imageSpec groot.ImageSpec
+
+			JustBeforeEach(func() {
+				imageSpec = groot.ImageSpec{
+					ID:          "mapped-id",
+					BaseImage:   imageConfig,
+					UIDMappings: []groot.IDMappingSpec{{HostID: 300000, NamespaceID: 0, Size: 65536}},
+					GIDMappings: []groot.IDMappingSpec{{HostID: 400000, NamespaceID: 0, Size: 65536}},
+				}
+				_, err := imageManager.Create(logger, imageSpec)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("exports the image diff with the image id mappings", func() {
+				Expect(imageManager.ExportDiff(logger, "mapped-id", idMappings, new(bytes.Buffer))).To(Succeed())
+
+				_, _, _, uidMappings, gidMappings := fakeImageDiffExporter.ExportImageDiffArgsForCall(0)
+				Expect(uidMappings).To(Equal(imageSpec.UIDMappings))
+				Expect(gidMappings).To(Equal(imageSpec.GIDMappings))
+			})
+
+			It("exports the image volumes with the image id mappings", func() {
+				Expect(imageManager.ExportVolume(logger, "mapped-id", "chain-1", idMappings, new(bytes.Buffer))).To(Succeed())
+
+				_, _, _, _, uidMappings, gidMappings := fakeImageDiffExporter.ExportImageVolumeArgsForCall(0)
+				Expect(uidMappings).To(Equal(imageSpec.UIDMappings))
+				Expect(gidMappings).To(Equal(imageSpec.GIDMappings))
+			})
+		})
+
+		Context("when the image driver can't export image diffs", func() {
+			JustBeforeEach(func() {
+				imageManager = imagemanager.NewImageManager(fakeImageDriver, storePath)
+			})
+
+			It("returns an error", func() {
+				err := imageManager.ExportDiff(logger, "some-id", idMappings, new(bytes.Buffer))
+				Expect(err).To(MatchError(ContainSubstring("does not support exporting")))
+			})
+		})
+	})
+
+	Describe("BaseImage", func() {
+		It("returns the config of the image base image", func() {
+			_, err := imageManager.Create(logger, groot.ImageSpec{ID: "some-id", BaseImage: imageConfig})
+			Expect(err).NotTo(HaveOccurred())
+
+			baseImage, err := imageManager.BaseImage("some-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(baseImage.Created.Unix()).To(Equal(imageConfig.Created.Unix()))
+		})
+
+		Context("when the image was created without keeping its base image config", func() {
+			BeforeEach(func() {
+				Expect(os.Mkdir(filepath.Join(imagesPath, "old-id"), 0777)).To(Succeed())
+			})
+
+			It("returns an empty config", func() {
+				baseImage, err := imageManager.BaseImage("old-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(baseImage).To(Equal(specsv1.Image{}))
+			})
+		})
+
+		Context("when the image does not exist", func() {
+			It("returns an error", func() {
+				_, err := imageManager.BaseImage("other-id")
+				Expect(err).To(MatchError("image not found: other-id"))
+			})
+		})
+	})
+
+	Describe("Inspect", func() {
+		var fakeImageInspector *image_managerfakes.FakeImageInspector
+
+		BeforeEach(func() {
+			fakeImageInspector = new(image_managerfakes.FakeImageInspector)
+			fakeImageInspector.InspectImageReturns(groot.ImageInspection{Mounted: true, ExclusiveDiskLimit: 4096}, nil)
+			imageConfig.Config = specsv1.ImageConfig{Env: []string{"PATH=/bin"}, WorkingDir: "/home"}
+		})
+
+		JustBeforeEach(func() {
+			inspectingDriver := struct {
+				*image_managerfakes.FakeImageDriver
+				*image_managerfakes.FakeImageInspector
+			}{fakeImageDriver, fakeImageInspector}
+			imageManager = imagemanager.NewImageManager(inspectingDriver, storePath)
+
+			_, err := imageManager.Create(logger, groot.ImageSpec{
+				ID:                 "some-id",
+				BaseImage:          imageConfig,
+				BaseImageReference: "docker:///busybox",
+				ManifestDigest:     "sha256:busybox",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("describes the image", func() {
+			inspection, err := imageManager.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(inspection.ID).To(Equal("some-id"))
+			Expect(inspection.Path).To(Equal(filepath.Join(imagesPath, "some-id")))
+			Expect(inspection.CreatedAt).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(inspection.OwnerUID).To(Equal(os.Getuid()))
+			Expect(inspection.OwnerGID).To(Equal(os.Getgid()))
+			Expect(inspection.Config.Env).To(Equal([]string{"PATH=/bin"}))
+			Expect(inspection.Config.WorkingDir).To(Equal("/home"))
+		})
+
+		It("reads the creation time and base image recorded with the image", func() {
+			oldTime := time.Now().Add(-48 * time.Hour)
+			Expect(os.Chtimes(filepath.Join(imagesPath, "some-id"), oldTime, oldTime)).To(Succeed())
+
+			inspection, err := imageManager.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inspection.CreatedAt).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(inspection.SourceImage).To(Equal("docker:///busybox"))
+			Expect(inspection.ManifestDigest).To(Equal("sha256:busybox"))
+		})
+
+		Context("when the image was created before its metadata was recorded", func() {
+			var modTime time.Time
+
+			JustBeforeEach(func() {
+				imagePath := filepath.Join(imagesPath, "some-id")
+				Expect(os.Remove(filepath.Join(imagePath, imagemanager.ImageMetaName))).To(Succeed())
+				modTime = time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+				Expect(os.Chtimes(imagePath, modTime, modTime)).To(Succeed())
+			})
+
+			It("takes the creation time from the image directory", func() {
+				inspection, err := imageManager.Inspect(logger, "some-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(inspection.CreatedAt.Equal(modTime)).To(BeTrue())
+				Expect(inspection.SourceImage).To(BeEmpty())
+			})
+		})
+
+		Context("when the image metadata is corrupted", func() {
+			JustBeforeEach(func() {
+				Expect(os.WriteFile(filepath.Join(imagesPath, "some-id", imagemanager.ImageMetaName), []byte("{"), 0600)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				_, err := imageManager.Inspect(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("parsing image meta")))
+			})
+		})
+
+		It("asks the image driver how the image is mounted and limited", func() {
+			inspection, err := imageManager.Inspect(logger, "some-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeImageInspector.InspectImageCallCount()).To(Equal(1))
+			_, imagePath := fakeImageInspector.InspectImageArgsForCall(0)
+			Expect(imagePath).To(Equal(filepath.Join(imagesPath, "some-id")))
+			Expect(inspection.Mounted).To(BeTrue())
+			Expect(inspection.ExclusiveDiskLimit).To(BeEquivalentTo(4096))
+		})
+
+		Context("when the image driver fails", func() {
+			BeforeEach(func() {
+				fakeImageInspector.InspectImageReturns(groot.ImageInspection{}, errors.New("listing mounts"))
+			})
+
+			It("returns an error", func() {
+				_, err := imageManager.Inspect(logger, "some-id")
+				Expect(err).To(MatchError(ContainSubstring("listing mounts")))
+			})
+		})
+
+		Context("when the image does not exist", func() {
+			It("returns an error", func() {
+				_, err := imageManager.Inspect(logger, "other-id")
+				Expect(err).To(MatchError("image not found: other-id"))
+			})
+		})
+	})
+
 	Describe("ImageIDs", func() {
 		BeforeEach(func() {
 			Expect(os.Mkdir(filepath.Join(imagesPath, "image-a"), 0777)).To(Succeed())
@@ -25,6 +25,7 @@ type StoreDriver interface {
 	InitFilesystem(logger lager.Logger, filesystemPath, storePath string) error
 	DeInitFilesystem(logger lager.Logger, storePath string) error
 	MountFilesystem(logger lager.Logger, filesystemPath, storePath string) error
+	GrowFilesystem(logger lager.Logger, filesystemPath, storePath string) error
 }
 
 type Manager struct {
@@ -123,6 +124,53 @@ func (m *Manager) InitStore(logger lager.Logger, spec InitSpec) (err error) {
 	return nil
 }
 
+// ResizeStore grows the backing store file to the given size, and the mounted
+// filesystem with it. Shrinking is refused, as XFS can't shrink.
+func (m *Manager) ResizeStore(logger lager.Logger, storeSizeBytes int64) (err error) {
+	logger = logger.Session("store-manager-resize-store", lager.Data{"storePath": m.storePath, "storeSizeBytes": storeSizeBytes})
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	lockFile, err := m.locksmith.Lock("init-store")
+	if err != nil {
+		return errorspkg.Wrap(err, "locking")
+	}
+	defer func() {
+		if unlockErr := m.locksmith.Unlock(lockFile); unlockErr != nil {
+			err = errorspkg.Wrap(err, unlockErr.Error())
+		}
+	}()
+
+	backingStoreFile := m.getBackingStoreFilePath()
+	stat, err := os.Stat(backingStoreFile)
+	if os.IsNotExist(err) {
+		return errorspkg.Errorf("store %s has no backing store file", m.storePath)
+	}
+	if err != nil {
+		return errorspkg.Wrap(err, "checking backing store file")
+	}
+
+	if storeSizeBytes < stat.Size() {
+		return errorspkg.Errorf("shrinking the store is not supported: it is %d bytes", stat.Size())
+	}
+	if storeSizeBytes == stat.Size() {
+		logger.Debug("store-already-at-size")
+		return nil
+	}
+
+	if err := os.Truncate(backingStoreFile, storeSizeBytes); err != nil {
+		logger.Error("truncating-backing-store-file-failed", err, lager.Data{"backingstoreFile": backingStoreFile})
+		return errorspkg.Wrap(err, "extending backing store file")
+	}
+
+	if err := m.storeDriver.GrowFilesystem(logger, backingStoreFile, m.storePath); err != nil {
+		logger.Error("growing-filesystem-failed", err, lager.Data{"backingstoreFile": backingStoreFile})
+		return errorspkg.Wrap(err, "growing filesystem")
+	}
+
+	return nil
+}
+
 func (m *Manager) mountFileSystemIfBackingStoreExists(logger lager.Logger) error {
 	if !m.backingStoreFileExists() {
 		return nil
@@ -305,7 +353,13 @@ func (m *Manager) createAndMountFilesystem(logger lager.Logger, storeSizeBytes i
 }
 
 func (m *Manager) getBackingStoreFilePath() string {
-	return fmt.Sprintf("%s.backing-store", m.storePath)
+	return BackingStoreFilePath(m.storePath)
+}
+
+// BackingStoreFilePath returns the file the store filesystem lives in, when
+// the store was initialized with a size
+func BackingStoreFilePath(storePath string) string {
+	return fmt.Sprintf("%s.backing-store", storePath)
 }
 
 func (m *Manager) backingStoreFileExists() bool {
@@ -622,6 +622,81 @@ var _ = Describe("Manager", func() {
 		})
 	})
 
+	Describe("ResizeStore", func() {
+		var backingStoreFile string
+
+		BeforeEach(func() {
+			storePath = filepath.Join(grootfsPath, "resize-store")
+			Expect(os.MkdirAll(storePath, 0755)).To(Succeed())
+
+			backingStoreFile = managerpkg.BackingStoreFilePath(storePath)
+			Expect(os.WriteFile(backingStoreFile, []byte{}, 0600)).To(Succeed())
+			Expect(os.Truncate(backingStoreFile, 1024*1024*300)).To(Succeed())
+		})
+
+		It("extends the backing store file", func() {
+			Expect(manager.ResizeStore(logger, 1024*1024*400)).To(Succeed())
+
+			stat, err := os.Stat(backingStoreFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stat.Size()).To(Equal(int64(1024 * 1024 * 400)))
+		})
+
+		It("uses the store driver to grow the filesystem", func() {
+			Expect(manager.ResizeStore(logger, 1024*1024*400)).To(Succeed())
+
+			Expect(storeDriver.GrowFilesystemCallCount()).To(Equal(1))
+			_, filesystemPath, path := storeDriver.GrowFilesystemArgsForCall(0)
+			Expect(filesystemPath).To(Equal(backingStoreFile))
+			Expect(path).To(Equal(storePath))
+		})
+
+		It("holds the init-store lock", func() {
+			Expect(manager.ResizeStore(logger, 1024*1024*400)).To(Succeed())
+
+			Expect(locksmith.LockCallCount()).To(Equal(1))
+			Expect(locksmith.LockArgsForCall(0)).To(Equal("init-store"))
+			Expect(locksmith.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when the size is smaller than the store", func() {
+			It("refuses to shrink it", func() {
+				err := manager.ResizeStore(logger, 1024*1024*200)
+				Expect(err).To(MatchError(ContainSubstring("shrinking the store is not supported")))
+				Expect(storeDriver.GrowFilesystemCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the store is already at the size", func() {
+			It("does not grow the filesystem", func() {
+				Expect(manager.ResizeStore(logger, 1024*1024*300)).To(Succeed())
+				Expect(storeDriver.GrowFilesystemCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the store has no backing store file", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(backingStoreFile)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				err := manager.ResizeStore(logger, 1024*1024*400)
+				Expect(err).To(MatchError(ContainSubstring("has no backing store file")))
+			})
+		})
+
+		Context("when the store driver fails to grow the filesystem", func() {
+			BeforeEach(func() {
+				storeDriver.GrowFilesystemReturns(errors.New("xfs_growfs failed"))
+			})
+
+			It("returns an error", func() {
+				err := manager.ResizeStore(logger, 1024*1024*400)
+				Expect(err).To(MatchError(ContainSubstring("xfs_growfs failed")))
+			})
+		})
+	})
+
 	Describe("DeleteStore", func() {
 		var (
 			imagesPath  string
@@ -5,7 +5,7 @@ import (
 	"sync"
 
 	"code.cloudfoundry.org/grootfs/store/manager"
-	"code.cloudfoundry.org/lager/v3"
+	lager "code.cloudfoundry.org/lager/v3"
 )
 
 type FakeStoreDriver struct {
@@ -36,6 +36,19 @@ type FakeStoreDriver struct {
 	deInitFilesystemReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GrowFilesystemStub        func(lager.Logger, string, string) error
+	growFilesystemMutex       sync.RWMutex
+	growFilesystemArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}
+	growFilesystemReturns struct {
+		result1 error
+	}
+	growFilesystemReturnsOnCall map[int]struct {
+		result1 error
+	}
 	InitFilesystemStub        func(lager.Logger, string, string) error
 	initFilesystemMutex       sync.RWMutex
 	initFilesystemArgsForCall []struct {
@@ -205,6 +218,69 @@ func (fake *FakeStoreDriver) DeInitFilesystemReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeStoreDriver) GrowFilesystem(arg1 lager.Logger, arg2 string, arg3 string) error {
+	fake.growFilesystemMutex.Lock()
+	ret, specificReturn := fake.growFilesystemReturnsOnCall[len(fake.growFilesystemArgsForCall)]
+	fake.growFilesystemArgsForCall = append(fake.growFilesystemArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GrowFilesystemStub
+	fakeReturns := fake.growFilesystemReturns
+	fake.recordInvocation("GrowFilesystem", []interface{}{arg1, arg2, arg3})
+	fake.growFilesystemMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStoreDriver) GrowFilesystemCallCount() int {
+	fake.growFilesystemMutex.RLock()
+	defer fake.growFilesystemMutex.RUnlock()
+	return len(fake.growFilesystemArgsForCall)
+}
+
+func (fake *FakeStoreDriver) GrowFilesystemCalls(stub func(lager.Logger, string, string) error) {
+	fake.growFilesystemMutex.Lock()
+	defer fake.growFilesystemMutex.Unlock()
+	fake.GrowFilesystemStub = stub
+}
+
+func (fake *FakeStoreDriver) GrowFilesystemArgsForCall(i int) (lager.Logger, string, string) {
+	fake.growFilesystemMutex.RLock()
+	defer fake.growFilesystemMutex.RUnlock()
+	argsForCall := fake.growFilesystemArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeStoreDriver) GrowFilesystemReturns(result1 error) {
+	fake.growFilesystemMutex.Lock()
+	defer fake.growFilesystemMutex.Unlock()
+	fake.GrowFilesystemStub = nil
+	fake.growFilesystemReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStoreDriver) GrowFilesystemReturnsOnCall(i int, result1 error) {
+	fake.growFilesystemMutex.Lock()
+	defer fake.growFilesystemMutex.Unlock()
+	fake.GrowFilesystemStub = nil
+	if fake.growFilesystemReturnsOnCall == nil {
+		fake.growFilesystemReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.growFilesystemReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeStoreDriver) InitFilesystem(arg1 lager.Logger, arg2 string, arg3 string) error {
 	fake.initFilesystemMutex.Lock()
 	ret, specificReturn := fake.initFilesystemReturnsOnCall[len(fake.initFilesystemArgsForCall)]
@@ -400,6 +476,8 @@ func (fake *FakeStoreDriver) Invocations() map[string][][]interface{} {
 	defer fake.configureStoreMutex.RUnlock()
 	fake.deInitFilesystemMutex.RLock()
 	defer fake.deInitFilesystemMutex.RUnlock()
+	fake.growFilesystemMutex.RLock()
+	defer fake.growFilesystemMutex.RUnlock()
 	fake.initFilesystemMutex.RLock()
 	defer fake.initFilesystemMutex.RUnlock()
 	fake.mountFilesystemMutex.RLock()
@@ -128,18 +128,81 @@ func readImageQuota(imageDir string) (int64, error) {
 }
 
 func (s *StoreMeasurer) PathStats(path string) (totalBytes, UsedBytes int64, err error) {
+	total, used, _, err := s.filesystemStats()
+	return total, used, err
+}
+
+// StoreCapacity is the space of the store filesystem, and how the store uses
+// it
+type StoreCapacity struct {
+	Capacity                 int64   `json:"capacity"`
+	Used                     int64   `json:"used"`
+	Available                int64   `json:"available"`
+	CommittedQuota           int64   `json:"committed_quota"`
+	TotalVolumesSize         int64   `json:"total_volumes_size"`
+	UsedVolumesSize          int64   `json:"used_volumes_size"`
+	UnusedVolumesSize        int64   `json:"unused_volumes_size"`
+	PurgeableCachePercentage float64 `json:"purgeable_cache_percentage"`
+}
+
+// Capacity measures the store filesystem, and breaks down what the store
+// takes of it. The purgeable cache is the share of the filesystem taken by
+// unused volumes, which clean can free.
+func (s *StoreMeasurer) Capacity(logger lager.Logger) (StoreCapacity, error) {
+	logger = logger.Session("measuring-capacity")
+	logger.Debug("starting")
+	defer logger.Debug("ending")
+
+	total, used, available, err := s.filesystemStats()
+	if err != nil {
+		return StoreCapacity{}, err
+	}
+
+	committedQuota, err := s.CommittedQuota(logger)
+	if err != nil {
+		return StoreCapacity{}, errorspkg.Wrap(err, "measuring committed quota")
+	}
+
+	totalVolumesSize, err := s.TotalVolumesSize(logger)
+	if err != nil {
+		return StoreCapacity{}, errorspkg.Wrap(err, "measuring volumes")
+	}
+
+	unusedVolumesSize, err := s.UnusedVolumesSize(logger)
+	if err != nil {
+		return StoreCapacity{}, errorspkg.Wrap(err, "measuring unused volumes")
+	}
+
+	capacity := StoreCapacity{
+		Capacity:          total,
+		Used:              used,
+		Available:         available,
+		CommittedQuota:    committedQuota,
+		TotalVolumesSize:  totalVolumesSize,
+		UsedVolumesSize:   totalVolumesSize - unusedVolumesSize,
+		UnusedVolumesSize: unusedVolumesSize,
+	}
+	if total > 0 {
+		capacity.PurgeableCachePercentage = float64(unusedVolumesSize) * 100 / float64(total)
+	}
+
+	return capacity, nil
+}
+
+func (s *StoreMeasurer) filesystemStats() (totalBytes, usedBytes, availableBytes int64, err error) {
 	stats := syscall.Statfs_t{}
 	if err = syscall.Statfs(s.storePath, &stats); err != nil {
-		return 0, 0, errorspkg.Wrapf(err, "Invalid path %s", s.storePath)
+		return 0, 0, 0, errorspkg.Wrapf(err, "Invalid path %s", s.storePath)
 	}
 
 	// #nosec - file + filesystem sizes will never be negative. changing here will massively impact the codebase's use of int64 throughout public interfaces
 	bsize := uint64(stats.Bsize)
 	free := stats.Bfree * bsize
 	total := stats.Blocks * bsize
+	available := stats.Bavail * bsize
 	// #nosec -  this won't overflow until we have filesystems reaching 9.2 exabytes (18_446_744_073_709_551_615 / 2). changing here will massively impact the codebase's use of int64 throughout public interfaces
 	used := int64(total - free)
 
 	// #nosec -  this won't overflow until we have filesystems reaching 9.2 exabytes (18_446_744_073_709_551_615 / 2). changing here will massively impact the codebase's use of int64 throughout public interfaces
-	return int64(total), used, nil
+	return int64(total), used, int64(available), nil
 }
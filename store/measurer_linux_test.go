@@ -211,4 +211,50 @@ var _ = Describe("Measurer", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Describe("Capacity", func() {
+		BeforeEach(func() {
+			volumeDriver.VolumeSizeReturns(2048, nil)
+			volumeDriver.VolumesReturns([]string{"sha256:fake1", "sha256:fake2", "sha256:fake3"}, nil)
+			unusedVolumeGetter.UnusedVolumesReturns([]string{"sha256:fake3"}, nil)
+
+			imagePath := filepath.Join(storePath, store.ImageDirName, "my-image")
+			Expect(os.MkdirAll(imagePath, 0744)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(imagePath, "image_quota"), []byte("1024"), 0777)).To(Succeed())
+		})
+
+		It("measures the store filesystem", func() {
+			capacity, err := storeMeasurer.Capacity(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			total, used, err := storeMeasurer.PathStats(storePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(capacity.Capacity).To(Equal(total))
+			Expect(capacity.Used).To(BeNumerically("~", used, 1024*1024))
+			Expect(capacity.Available).To(BeNumerically(">", 0))
+			Expect(capacity.Available).To(BeNumerically("<=", capacity.Capacity-capacity.Used))
+		})
+
+		It("breaks down the store usage", func() {
+			capacity, err := storeMeasurer.Capacity(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(capacity.CommittedQuota).To(BeEquivalentTo(1024))
+			Expect(capacity.TotalVolumesSize).To(BeEquivalentTo(6144))
+			Expect(capacity.UsedVolumesSize).To(BeEquivalentTo(4096))
+			Expect(capacity.UnusedVolumesSize).To(BeEquivalentTo(2048))
+			Expect(capacity.PurgeableCachePercentage).To(BeNumerically("~", float64(2048)*100/float64(capacity.Capacity), 0.0001))
+		})
+
+		Context("when getting the unused volumes fails", func() {
+			BeforeEach(func() {
+				unusedVolumeGetter.UnusedVolumesReturns(nil, errors.New("failed here"))
+			})
+
+			It("returns an error", func() {
+				_, err := storeMeasurer.Capacity(logger)
+				Expect(err).To(MatchError(ContainSubstring("failed here")))
+			})
+		})
+	})
 })
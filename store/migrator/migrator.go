@@ -0,0 +1,322 @@
+package migrator // import "code.cloudfoundry.org/grootfs/store/migrator"
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/grootfs/base_image_puller"
+	"code.cloudfoundry.org/grootfs/store"
+	"code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/lager/v3"
+	errorspkg "github.com/pkg/errors"
+)
+
+// VersionFilename is the file in the store meta directory recording the
+// version of the store layout. Stores without it predate versioning, and are
+// at version 0.
+const VersionFilename = "store_version"
+
+// CurrentVersion is the store layout version this grootfs understands
+const CurrentVersion = 3
+
+//go:generate counterfeiter . VolumeDriver
+
+type VolumeDriver interface {
+	Volumes(logger lager.Logger) ([]string, error)
+	ReadVolumeMeta(logger lager.Logger, id string) (base_image_puller.VolumeMeta, error)
+	WriteVolumeMeta(logger lager.Logger, id string, metadata base_image_puller.VolumeMeta) error
+	GenerateVolumeMeta(logger lager.Logger, id string) error
+	VolumePath(logger lager.Logger, id string) (string, error)
+	ImageMounted(logger lager.Logger, imagePath string) (bool, error)
+	MarkImageWithoutMount(logger lager.Logger, imagePath string) error
+}
+
+// Migration brings the store layout to its version. Migrations must be
+// idempotent, as a migration interrupted half way is run again in full.
+type Migration struct {
+	Version int
+	Name    string
+	Migrate func(logger lager.Logger) error
+}
+
+type Migrator struct {
+	storePath             string
+	volumeDriver          VolumeDriver
+	withoutMountByDefault bool
+}
+
+func NewMigrator(storePath string, volumeDriver VolumeDriver) *Migrator {
+	return &Migrator{
+		storePath:    storePath,
+		volumeDriver: volumeDriver,
+	}
+}
+
+// WithoutMountByDefault tells whether the images of the store are created
+// without mounting them unless asked otherwise, which is all that is known of
+// the images created before grootfs recorded it and the last reboot
+func (m *Migrator) WithoutMountByDefault(withoutMount bool) *Migrator {
+	m.withoutMountByDefault = withoutMount
+	return m
+}
+
+// Migrations lists the store migrations, in the order they are to be run
+func (m *Migrator) Migrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "volume-metadata", Migrate: m.generateVolumesMeta},
+		{Version: 2, Name: "volume-timestamps", Migrate: m.addVolumesTimestamps},
+		{Version: 3, Name: "without-mount-markers", Migrate: m.markImagesWithoutMount},
+	}
+}
+
+// Migrate runs the migrations the store is missing, recording the version
+// after each one, so that an interrupted migration resumes where it stopped.
+// It returns the versions the store was migrated from and to.
+func (m *Migrator) Migrate(logger lager.Logger) (int, int, error) {
+	logger = logger.Session("migrating-store", lager.Data{"storePath": m.storePath})
+	logger.Info("starting")
+	defer logger.Info("ending")
+
+	fromVersion, err := CheckVersion(m.storePath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	version := fromVersion
+	for _, migration := range m.Migrations() {
+		if migration.Version <= version {
+			continue
+		}
+
+		logger.Info("running-migration", lager.Data{"version": migration.Version, "name": migration.Name})
+		if err := migration.Migrate(logger); err != nil {
+			return fromVersion, version, errorspkg.Wrapf(err, "migrating store to version %d (%s)", migration.Version, migration.Name)
+		}
+
+		if err := WriteVersion(m.storePath, migration.Version); err != nil {
+			return fromVersion, version, err
+		}
+		version = migration.Version
+	}
+
+	return fromVersion, version, nil
+}
+
+// generateVolumesMeta writes the metadata of the volumes pulled before volumes
+// had it
+func (m *Migrator) generateVolumesMeta(logger lager.Logger) error {
+	volumes, err := m.volumeDriver.Volumes(logger)
+	if err != nil {
+		return errorspkg.Wrap(err, "listing volumes")
+	}
+
+	for _, volumeID := range volumes {
+		if strings.Contains(volumeID, "-incomplete-") {
+			continue
+		}
+
+		_, err := m.volumeDriver.ReadVolumeMeta(logger, volumeID)
+		if err == nil {
+			continue
+		}
+		if !os.IsNotExist(err) {
+			return errorspkg.Wrapf(err, "reading metadata of volume %s", volumeID)
+		}
+
+		if err := m.volumeDriver.GenerateVolumeMeta(logger, volumeID); err != nil {
+			return errorspkg.Wrapf(err, "generating metadata of volume %s", volumeID)
+		}
+	}
+
+	return nil
+}
+
+// addVolumesTimestamps records the creation and last use times of the volumes
+// pulled before volumes had them, using the volume modification time
+func (m *Migrator) addVolumesTimestamps(logger lager.Logger) error {
+	volumes, err := m.volumeDriver.Volumes(logger)
+	if err != nil {
+		return errorspkg.Wrap(err, "listing volumes")
+	}
+
+	for _, volumeID := range volumes {
+		if strings.Contains(volumeID, "-incomplete-") {
+			continue
+		}
+
+		metadata, err := m.volumeDriver.ReadVolumeMeta(logger, volumeID)
+		if err != nil {
+			return errorspkg.Wrapf(err, "reading metadata of volume %s", volumeID)
+		}
+		if !metadata.CreatedAt.IsZero() {
+			continue
+		}
+
+		volumePath, err := m.volumeDriver.VolumePath(logger, volumeID)
+		if err != nil {
+			return errorspkg.Wrapf(err, "fetching path of volume %s", volumeID)
+		}
+
+		volumeInfo, err := os.Stat(volumePath)
+		if err != nil {
+			return errorspkg.Wrapf(err, "stating volume %s", volumeID)
+		}
+
+		metadata.CreatedAt = volumeInfo.ModTime()
+		metadata.LastUsed = volumeInfo.ModTime()
+		if err := m.volumeDriver.WriteVolumeMeta(logger, volumeID, metadata); err != nil {
+			return errorspkg.Wrapf(err, "writing metadata of volume %s", volumeID)
+		}
+	}
+
+	return nil
+}
+
+// markImagesWithoutMount marks the images created without mounting them before
+// grootfs recorded it, so that they are not remounted. The images mounted at
+// creation are still mounted unless the host rebooted since, otherwise the
+// store default is assumed.
+func (m *Migrator) markImagesWithoutMount(logger lager.Logger) error {
+	imagesPath := filepath.Join(m.storePath, store.ImageDirName)
+	images, err := os.ReadDir(imagesPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errorspkg.Wrap(err, "listing images")
+	}
+
+	bootTime, err := readBootTime()
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		imagePath := filepath.Join(imagesPath, image.Name())
+
+		// images with their metadata are marked at creation already
+		if _, err := os.Stat(filepath.Join(imagePath, image_manager.ImageMetaName)); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return errorspkg.Wrapf(err, "checking metadata of image %s", image.Name())
+		}
+
+		imageInfo, err := os.Stat(imagePath)
+		if err != nil {
+			return errorspkg.Wrapf(err, "stating image %s", image.Name())
+		}
+
+		withoutMount := m.withoutMountByDefault
+		if imageInfo.ModTime().After(bootTime) {
+			mounted, err := m.volumeDriver.ImageMounted(logger, imagePath)
+			if err != nil {
+				return errorspkg.Wrapf(err, "checking mount of image %s", image.Name())
+			}
+			withoutMount = !mounted
+		}
+		if !withoutMount {
+			continue
+		}
+
+		logger.Debug("marking-image-without-mount", lager.Data{"imagePath": imagePath})
+		if err := m.volumeDriver.MarkImageWithoutMount(logger, imagePath); err != nil {
+			return errorspkg.Wrapf(err, "marking image %s", image.Name())
+		}
+	}
+
+	return nil
+}
+
+// readBootTime returns when the host booted, as recorded in /proc/stat
+func readBootTime() (time.Time, error) {
+	statFile, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, errorspkg.Wrap(err, "opening /proc/stat")
+	}
+	defer statFile.Close()
+
+	scanner := bufio.NewScanner(statFile)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != "btime" {
+			continue
+		}
+
+		bootTime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, errorspkg.Wrap(err, "parsing boot time")
+		}
+		return time.Unix(bootTime, 0), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, errorspkg.Wrap(err, "reading /proc/stat")
+	}
+
+	return time.Time{}, errorspkg.New("boot time not found in /proc/stat")
+}
+
+// ReadVersion returns the version of the store layout
+func ReadVersion(storePath string) (int, error) {
+	contents, err := os.ReadFile(versionFilePath(storePath))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errorspkg.Wrap(err, "reading store version")
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return 0, errorspkg.Wrap(err, "parsing store version")
+	}
+
+	return version, nil
+}
+
+// CheckVersion returns the version of the store layout, failing when it is
+// newer than this grootfs understands
+func CheckVersion(storePath string) (int, error) {
+	version, err := ReadVersion(storePath)
+	if err != nil {
+		return 0, err
+	}
+
+	if version > CurrentVersion {
+		return version, errorspkg.Errorf("store version %d is newer than the supported version %d: upgrade grootfs", version, CurrentVersion)
+	}
+
+	return version, nil
+}
+
+// WriteVersion records the version of the store layout
+func WriteVersion(storePath string, version int) error {
+	versionFilePath := versionFilePath(storePath)
+	versionFile, err := os.CreateTemp(filepath.Dir(versionFilePath), VersionFilename+".tmp-")
+	if err != nil {
+		return errorspkg.Wrap(err, "creating store version file")
+	}
+	defer os.Remove(versionFile.Name())
+	defer versionFile.Close()
+
+	if _, err := versionFile.WriteString(strconv.Itoa(version) + "\n"); err != nil {
+		return errorspkg.Wrap(err, "writing store version file")
+	}
+
+	if err := versionFile.Chmod(0644); err != nil {
+		return errorspkg.Wrap(err, "changing store version file permissions")
+	}
+
+	if err := os.Rename(versionFile.Name(), versionFilePath); err != nil {
+		return errorspkg.Wrap(err, "moving store version file")
+	}
+
+	return nil
+}
+
+func versionFilePath(storePath string) string {
+	return filepath.Join(storePath, store.MetaDirName, VersionFilename)
+}
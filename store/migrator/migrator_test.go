@@ -0,0 +1,283 @@
+package migrator_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/grootfs/base_image_puller"
+	"code.cloudfoundry.org/grootfs/store"
+	imagemanager "code.cloudfoundry.org/grootfs/store/image_manager"
+	"code.cloudfoundry.org/grootfs/store/migrator"
+	"code.cloudfoundry.org/grootfs/store/migrator/migratorfakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrator", func() {
+	var (
+		storePath        string
+		fakeVolumeDriver *migratorfakes.FakeVolumeDriver
+		logger           lager.Logger
+
+		storeMigrator *migrator.Migrator
+	)
+
+	BeforeEach(func() {
+		var err error
+		storePath, err = os.MkdirTemp("", "store")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(storePath, store.MetaDirName), 0755)).To(Succeed())
+
+		fakeVolumeDriver = new(migratorfakes.FakeVolumeDriver)
+		fakeVolumeDriver.VolumesReturns([]string{"vol-with-meta", "vol-without-meta", "vol-incomplete-1234"}, nil)
+		volumesMeta := map[string]base_image_puller.VolumeMeta{
+			"vol-with-meta": {Size: 1024},
+		}
+		fakeVolumeDriver.ReadVolumeMetaStub = func(_ lager.Logger, id string) (base_image_puller.VolumeMeta, error) {
+			if metadata, ok := volumesMeta[id]; ok {
+				return metadata, nil
+			}
+			return base_image_puller.VolumeMeta{}, os.ErrNotExist
+		}
+		fakeVolumeDriver.GenerateVolumeMetaStub = func(_ lager.Logger, id string) error {
+			now := time.Now()
+			volumesMeta[id] = base_image_puller.VolumeMeta{Size: 2048, CreatedAt: now, LastUsed: now}
+			return nil
+		}
+		fakeVolumeDriver.VolumePathStub = func(_ lager.Logger, id string) (string, error) {
+			return storePath, nil
+		}
+
+		logger = lagertest.NewTestLogger("migrator")
+		storeMigrator = migrator.NewMigrator(storePath, fakeVolumeDriver)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(storePath)).To(Succeed())
+	})
+
+	Describe("ReadVersion", func() {
+		It("returns 0 for stores that predate versioning", func() {
+			Expect(migrator.ReadVersion(storePath)).To(Equal(0))
+		})
+
+		It("returns the recorded version", func() {
+			Expect(migrator.WriteVersion(storePath, 3)).To(Succeed())
+			Expect(migrator.ReadVersion(storePath)).To(Equal(3))
+		})
+
+		Context("when the version file is corrupted", func() {
+			BeforeEach(func() {
+				Expect(os.WriteFile(filepath.Join(storePath, store.MetaDirName, migrator.VersionFilename), []byte("one"), 0644)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				_, err := migrator.ReadVersion(storePath)
+				Expect(err).To(MatchError(ContainSubstring("parsing store version")))
+			})
+		})
+	})
+
+	Describe("CheckVersion", func() {
+		It("accepts the current version", func() {
+			Expect(migrator.WriteVersion(storePath, migrator.CurrentVersion)).To(Succeed())
+			Expect(migrator.CheckVersion(storePath)).To(Equal(migrator.CurrentVersion))
+		})
+
+		It("accepts older versions, leaving the migration to migrate-store", func() {
+			Expect(migrator.CheckVersion(storePath)).To(Equal(0))
+			Expect(migrator.WriteVersion(storePath, migrator.CurrentVersion-1)).To(Succeed())
+			Expect(migrator.CheckVersion(storePath)).To(Equal(migrator.CurrentVersion - 1))
+			Expect(fakeVolumeDriver.VolumesCallCount()).To(BeZero())
+		})
+
+		It("refuses newer versions", func() {
+			Expect(migrator.WriteVersion(storePath, migrator.CurrentVersion+1)).To(Succeed())
+			_, err := migrator.CheckVersion(storePath)
+			Expect(err).To(MatchError(ContainSubstring("is newer than the supported version")))
+		})
+	})
+
+	Describe("Migrations", func() {
+		It("lists the migrations in order, up to the current version", func() {
+			migrations := storeMigrator.Migrations()
+			for i, migration := range migrations {
+				Expect(migration.Version).To(Equal(i + 1))
+			}
+			Expect(migrations[len(migrations)-1].Version).To(Equal(migrator.CurrentVersion))
+		})
+	})
+
+	Describe("Migrate", func() {
+		It("migrates the store to the current version", func() {
+			fromVersion, toVersion, err := storeMigrator.Migrate(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fromVersion).To(Equal(0))
+			Expect(toVersion).To(Equal(migrator.CurrentVersion))
+			Expect(migrator.ReadVersion(storePath)).To(Equal(migrator.CurrentVersion))
+		})
+
+		It("generates the missing volume metadata", func() {
+			_, _, err := storeMigrator.Migrate(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeVolumeDriver.GenerateVolumeMetaCallCount()).To(Equal(1))
+			_, volumeID := fakeVolumeDriver.GenerateVolumeMetaArgsForCall(0)
+			Expect(volumeID).To(Equal("vol-without-meta"))
+		})
+
+		It("records the timestamps of the volumes missing them", func() {
+			modTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+			Expect(os.Chtimes(storePath, modTime, modTime)).To(Succeed())
+
+			_, _, err := storeMigrator.Migrate(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeVolumeDriver.WriteVolumeMetaCallCount()).To(Equal(1))
+			_, volumeID, metadata := fakeVolumeDriver.WriteVolumeMetaArgsForCall(0)
+			Expect(volumeID).To(Equal("vol-with-meta"))
+			Expect(metadata.Size).To(BeEquivalentTo(1024))
+			Expect(metadata.CreatedAt.Equal(modTime)).To(BeTrue())
+			Expect(metadata.LastUsed.Equal(modTime)).To(BeTrue())
+		})
+
+		Context("when the store is at version 1", func() {
+			BeforeEach(func() {
+				Expect(migrator.WriteVersion(storePath, 1)).To(Succeed())
+				fakeVolumeDriver.VolumesReturns([]string{"vol-with-meta", "vol-incomplete-1234"}, nil)
+			})
+
+			It("does not generate the volume metadata again", func() {
+				fromVersion, toVersion, err := storeMigrator.Migrate(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fromVersion).To(Equal(1))
+				Expect(toVersion).To(Equal(migrator.CurrentVersion))
+
+				Expect(fakeVolumeDriver.GenerateVolumeMetaCallCount()).To(BeZero())
+				Expect(fakeVolumeDriver.WriteVolumeMetaCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the store has images", func() {
+			var imagesPath string
+
+			createImage := func(id string, modTime time.Time) string {
+				imagePath := filepath.Join(imagesPath, id)
+				Expect(os.MkdirAll(imagePath, 0755)).To(Succeed())
+				Expect(os.Chtimes(imagePath, modTime, modTime)).To(Succeed())
+				return imagePath
+			}
+
+			BeforeEach(func() {
+				Expect(migrator.WriteVersion(storePath, 2)).To(Succeed())
+				imagesPath = filepath.Join(storePath, store.ImageDirName)
+
+				newImagePath := createImage("new-image", time.Now())
+				Expect(os.WriteFile(filepath.Join(newImagePath, imagemanager.ImageMetaName), []byte("{}"), 0600)).To(Succeed())
+
+				mountedImagePath := createImage("mounted-image", time.Now())
+				createImage("unmounted-image", time.Now())
+				createImage("image-from-before-boot", time.Unix(0, 0))
+
+				fakeVolumeDriver.ImageMountedStub = func(_ lager.Logger, imagePath string) (bool, error) {
+					return imagePath == mountedImagePath, nil
+				}
+			})
+
+			It("marks the pre-existing images not mounted since the last boot", func() {
+				_, _, err := storeMigrator.Migrate(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeVolumeDriver.MarkImageWithoutMountCallCount()).To(Equal(1))
+				_, imagePath := fakeVolumeDriver.MarkImageWithoutMountArgsForCall(0)
+				Expect(imagePath).To(Equal(filepath.Join(imagesPath, "unmounted-image")))
+			})
+
+			It("leaves the images recorded by grootfs alone", func() {
+				_, _, err := storeMigrator.Migrate(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeVolumeDriver.ImageMountedCallCount()).To(Equal(2))
+				for i := 0; i < fakeVolumeDriver.ImageMountedCallCount(); i++ {
+					_, imagePath := fakeVolumeDriver.ImageMountedArgsForCall(i)
+					Expect(imagePath).NotTo(Equal(filepath.Join(imagesPath, "new-image")))
+				}
+			})
+
+			Context("when the store creates images without mounting them by default", func() {
+				BeforeEach(func() {
+					storeMigrator.WithoutMountByDefault(true)
+				})
+
+				It("also marks the pre-existing images from before the last boot", func() {
+					_, _, err := storeMigrator.Migrate(logger)
+					Expect(err).NotTo(HaveOccurred())
+
+					markedImagePaths := []string{}
+					for i := 0; i < fakeVolumeDriver.MarkImageWithoutMountCallCount(); i++ {
+						_, imagePath := fakeVolumeDriver.MarkImageWithoutMountArgsForCall(i)
+						markedImagePaths = append(markedImagePaths, imagePath)
+					}
+					Expect(markedImagePaths).To(ConsistOf(
+						filepath.Join(imagesPath, "unmounted-image"),
+						filepath.Join(imagesPath, "image-from-before-boot"),
+					))
+				})
+			})
+
+			Context("when marking an image fails", func() {
+				BeforeEach(func() {
+					fakeVolumeDriver.MarkImageWithoutMountReturns(errors.New("read-only file system"))
+				})
+
+				It("returns an error and leaves the version to resume from", func() {
+					_, _, err := storeMigrator.Migrate(logger)
+					Expect(err).To(MatchError(ContainSubstring("read-only file system")))
+					Expect(migrator.ReadVersion(storePath)).To(Equal(2))
+				})
+			})
+		})
+
+		Context("when the store is up to date", func() {
+			BeforeEach(func() {
+				Expect(migrator.WriteVersion(storePath, migrator.CurrentVersion)).To(Succeed())
+			})
+
+			It("does not run any migration", func() {
+				fromVersion, toVersion, err := storeMigrator.Migrate(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fromVersion).To(Equal(toVersion))
+				Expect(fakeVolumeDriver.VolumesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the store is newer", func() {
+			BeforeEach(func() {
+				Expect(migrator.WriteVersion(storePath, migrator.CurrentVersion+1)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				_, _, err := storeMigrator.Migrate(logger)
+				Expect(err).To(MatchError(ContainSubstring("is newer than the supported version")))
+				Expect(fakeVolumeDriver.VolumesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when a migration fails", func() {
+			BeforeEach(func() {
+				fakeVolumeDriver.GenerateVolumeMetaReturns(errors.New("disk full"))
+			})
+
+			It("returns an error and leaves the version to resume from", func() {
+				_, _, err := storeMigrator.Migrate(logger)
+				Expect(err).To(MatchError(ContainSubstring("disk full")))
+				Expect(migrator.ReadVersion(storePath)).To(Equal(0))
+			})
+		})
+	})
+})
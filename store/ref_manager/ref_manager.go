@@ -0,0 +1,78 @@
+package ref_manager // import "code.cloudfoundry.org/grootfs/store/ref_manager"
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	errorspkg "github.com/pkg/errors"
+)
+
+// RefManager keeps the base image info of the images committed into the
+// store, so that they can be used as base images by name.
+type RefManager struct {
+	refsPath string
+}
+
+func NewRefManager(refsPath string) *RefManager {
+	return &RefManager{
+		refsPath: refsPath,
+	}
+}
+
+func (r *RefManager) Save(name string, baseImageInfo groot.BaseImageInfo) error {
+	if err := os.MkdirAll(r.refsPath, 0755); err != nil {
+		return errorspkg.Wrap(err, "creating refs directory")
+	}
+
+	data, err := json.Marshal(baseImageInfo)
+	if err != nil {
+		return errorspkg.Wrap(err, "marshaling ref")
+	}
+
+	return os.WriteFile(r.filePath(name), data, 0644)
+}
+
+func (r *RefManager) Load(name string) (groot.BaseImageInfo, error) {
+	data, err := os.ReadFile(r.filePath(name))
+	if os.IsNotExist(err) {
+		return groot.BaseImageInfo{}, errorspkg.Errorf("ref `%s` not found", name)
+	}
+	if err != nil {
+		return groot.BaseImageInfo{}, errorspkg.Wrapf(err, "reading ref `%s`", name)
+	}
+
+	var baseImageInfo groot.BaseImageInfo
+	if err := json.Unmarshal(data, &baseImageInfo); err != nil {
+		return groot.BaseImageInfo{}, errorspkg.Wrapf(err, "parsing ref `%s`", name)
+	}
+
+	return baseImageInfo, nil
+}
+
+func (r *RefManager) Delete(name string) error {
+	if err := os.Remove(r.filePath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return errorspkg.Errorf("ref `%s` not found", name)
+		}
+		return errorspkg.Wrapf(err, "deleting ref `%s`", name)
+	}
+
+	return nil
+}
+
+func (r *RefManager) Exists(name string) (bool, error) {
+	if _, err := os.Stat(r.filePath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errorspkg.Wrapf(err, "checking if ref `%s` exists", name)
+	}
+
+	return true, nil
+}
+
+func (r *RefManager) filePath(name string) string {
+	return filepath.Join(r.refsPath, name+".json")
+}
@@ -0,0 +1,13 @@
+package ref_manager_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRefManager(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RefManager Suite")
+}
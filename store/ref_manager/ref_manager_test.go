@@ -0,0 +1,90 @@
+package ref_manager_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/grootfs/groot"
+	"code.cloudfoundry.org/grootfs/store/ref_manager"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RefManager", func() {
+	var (
+		metaPath string
+		manager  *ref_manager.RefManager
+	)
+
+	BeforeEach(func() {
+		var err error
+		metaPath, err = os.MkdirTemp("", "meta")
+		Expect(err).NotTo(HaveOccurred())
+
+		manager = ref_manager.NewRefManager(filepath.Join(metaPath, "refs"))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(metaPath)).To(Succeed())
+	})
+
+	Describe("Save", func() {
+		It("saves the base image info under the ref name", func() {
+			baseImageInfo := groot.BaseImageInfo{
+				LayerInfos: []groot.LayerInfo{
+					{ChainID: "chain-1", DiffID: "diff-1"},
+					{ChainID: "chain-2", DiffID: "diff-2", ParentChainID: "chain-1"},
+				},
+				Config: specsv1.Image{Author: "Groot"},
+			}
+			Expect(manager.Save("my-ref", baseImageInfo)).To(Succeed())
+			Expect(filepath.Join(metaPath, "refs", "my-ref.json")).To(BeAnExistingFile())
+
+			loadedBaseImageInfo, err := manager.Load("my-ref")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loadedBaseImageInfo).To(Equal(baseImageInfo))
+		})
+	})
+
+	Describe("Load", func() {
+		Context("when the ref does not exist", func() {
+			It("returns an error", func() {
+				_, err := manager.Load("my-ref")
+				Expect(err).To(MatchError("ref `my-ref` not found"))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the ref", func() {
+			Expect(manager.Save("my-ref", groot.BaseImageInfo{})).To(Succeed())
+			Expect(manager.Delete("my-ref")).To(Succeed())
+
+			exists, err := manager.Exists("my-ref")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		Context("when the ref does not exist", func() {
+			It("returns an error", func() {
+				Expect(manager.Delete("my-ref")).To(MatchError("ref `my-ref` not found"))
+			})
+		})
+	})
+
+	Describe("Exists", func() {
+		It("returns whether the ref exists", func() {
+			exists, err := manager.Exists("my-ref")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			Expect(manager.Save("my-ref", groot.BaseImageInfo{})).To(Succeed())
+
+			exists, err = manager.Exists("my-ref")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+	})
+})